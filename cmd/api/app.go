@@ -0,0 +1,38 @@
+package main
+
+import (
+	"xacms/internal/routes"
+	"xacms/internal/services"
+)
+
+// application 应用依赖，包含路由以及随服务启停的后台任务
+type application struct {
+	Router        *routes.Router
+	DeviceMonitor services.DeviceMonitorService
+	TrackService  services.TrackService
+	Realtime      services.RealtimeService
+	StrikeService services.StrikeService
+	DeviceDrivers services.DeviceDriverService
+	AlertService  services.AlertService
+}
+
+// start 注册路由并启动后台任务
+func (a *application) start() {
+	a.Router.RegisterRoutes()
+	a.TrackService.Start()
+	a.AlertService.Start()
+	a.DeviceDrivers.Start()
+	a.DeviceMonitor.Start()
+	a.StrikeService.Start()
+	a.Realtime.Start()
+}
+
+// stop 停止后台任务
+func (a *application) stop() {
+	a.Realtime.Stop()
+	a.StrikeService.Stop()
+	a.DeviceMonitor.Stop()
+	a.DeviceDrivers.Stop()
+	a.AlertService.Stop()
+	a.TrackService.Stop()
+}
@@ -4,6 +4,9 @@ package main
 
 import (
 	"xacms/internal/pkg/database"
+	"xacms/internal/pkg/event"
+	"xacms/internal/pkg/password"
+	"xacms/internal/pkg/token"
 	"xacms/internal/routes"
 	"xacms/internal/server"
 	"xacms/internal/services"
@@ -12,11 +15,15 @@ import (
 	"github.com/google/wire"
 )
 
-func wireRouter(server *server.FiberServer, validator *utils.ValidationMiddleware) *routes.Router {
+func wireApp(server *server.FiberServer, validator *utils.ValidationMiddleware) *application {
 	wire.Build(
 		database.NewDB,
+		event.NewBus,
+		token.NewManager,
+		password.NewHasher,
 		services.ServicesSet,
 		routes.RoutesSet,
+		wire.Struct(new(application), "*"),
 	)
 	return nil
 }
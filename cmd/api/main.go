@@ -15,7 +15,7 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 )
 
-func gracefulShutdown(fiberServer *server.FiberServer, done chan bool) {
+func gracefulShutdown(fiberServer *server.FiberServer, app *application, done chan bool) {
 	// 创建监听来自操作系统的中断信号的上下文。
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -30,10 +30,16 @@ func gracefulShutdown(fiberServer *server.FiberServer, done chan bool) {
 	// 它当前正在处理的请求
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+
+	// 先断开实时推送长连接，否则服务器会一直等待其结束
+	app.Realtime.Stop()
 	if err := fiberServer.ShutdownWithContext(ctx); err != nil {
 		log.Infof("服务器强制关闭，错误: %v", err)
 	}
 
+	// 停止后台任务
+	app.stop()
+
 	log.Info("服务器正在退出")
 
 	// 通知主goroutine关闭已完成
@@ -43,7 +49,8 @@ func gracefulShutdown(fiberServer *server.FiberServer, done chan bool) {
 func main() {
 	server := server.NewFiberServer()
 
-	wireRouter(server, utils.NewValidationMiddleware()).RegisterRoutes()
+	app := wireApp(server, utils.NewValidationMiddleware())
+	app.start()
 
 	// 创建一个完成通道，在关机完成后发出信号
 	done := make(chan bool, 1)
@@ -57,7 +64,7 @@ func main() {
 	}()
 
 	// 在单独的goroutine中运行优雅关闭
-	go gracefulShutdown(server, done)
+	go gracefulShutdown(server, app, done)
 
 	// 等待优雅关闭完成
 	<-done
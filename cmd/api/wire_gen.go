@@ -8,6 +8,9 @@ package main
 
 import (
 	"xacms/internal/pkg/database"
+	"xacms/internal/pkg/event"
+	"xacms/internal/pkg/password"
+	"xacms/internal/pkg/token"
 	"xacms/internal/routes"
 	"xacms/internal/server"
 	"xacms/internal/services"
@@ -20,29 +23,123 @@ import (
 
 // Injectors from injector.go:
 
-func wireRouter(server2 *server.FiberServer, validator *utils.ValidationMiddleware) *routes.Router {
+func wireApp(server2 *server.FiberServer, validator *utils.ValidationMiddleware) *application {
 	db := database.NewDB()
 	commonService := services.NewCommonService(db, validator, server2)
-	userService := services.NewUserService(db, commonService)
+	hasher := password.NewHasher()
+	manager := token.NewManager()
+	departmentService := services.NewDepartmentService(db, commonService)
+	dataScopeService := services.NewDataScopeService(db, departmentService)
+	sessionService := services.NewSessionService(db, commonService, manager)
+	userService := services.NewUserService(db, commonService, departmentService, dataScopeService, hasher, sessionService)
+	permissionService := services.NewPermissionService(db)
+	loginAttemptService := services.NewLoginAttemptService(db, commonService)
+	twoFactorService := services.NewTwoFactorService(db, commonService, hasher, sessionService)
 	userHandler := &routes.UserHandler{
-		UserService:   userService,
-		CommonService: commonService,
+		UserService:         userService,
+		CommonService:       commonService,
+		PermissionService:   permissionService,
+		LoginAttemptService: loginAttemptService,
+		TwoFactorService:    twoFactorService,
+		SessionService:      sessionService,
 	}
-	menuService := services.NewMenuService(db, commonService, server2)
+	menuService := services.NewMenuService(db, commonService, permissionService, server2)
+	buttonService := services.NewButtonService(db, commonService, permissionService)
 	menuHandler := &routes.MenuHandler{
-		CommonService: commonService,
-		MenuService:   menuService,
+		CommonService:     commonService,
+		MenuService:       menuService,
+		ButtonService:     buttonService,
+		PermissionService: permissionService,
 	}
-	roleService := services.NewRoleService(db, commonService)
+	roleService := services.NewRoleService(db, commonService, permissionService)
 	roleHandler := &routes.RoleHandler{
-		RoleService:   roleService,
-		CommonService: commonService,
+		RoleService:       roleService,
+		CommonService:     commonService,
+		PermissionService: permissionService,
 	}
-	deviceService := services.NewDeviceService(db, commonService)
+	deviceService := services.NewDeviceService(db, commonService, dataScopeService, hasher)
+	bus := event.NewBus()
+	zoneService := services.NewZoneService(db, commonService)
+	whitelistService := services.NewWhitelistService(db, commonService)
+	detectionService := services.NewDetectionService(db, zoneService, whitelistService, bus)
+	deviceDriverService := services.NewDeviceDriverService(db, commonService, detectionService)
+	streamService := services.NewStreamService(db, commonService, hasher)
+	deviceMonitorService := services.NewDeviceMonitorService(db, commonService, deviceDriverService, streamService, bus)
 	deviceHandler := &routes.DeviceHandler{
-		DeviceService: deviceService,
+		DeviceService:        deviceService,
+		DeviceMonitorService: deviceMonitorService,
+		DeviceDriverService:  deviceDriverService,
+		StreamService:        streamService,
+		CommonService:        commonService,
+	}
+	authService := services.NewAuthService(db, manager, hasher, loginAttemptService, twoFactorService, sessionService)
+	authHandler := &routes.AuthHandler{
+		AuthService:   authService,
+		CommonService: commonService,
+	}
+	tenantService := services.NewTenantService(db, commonService)
+	tenantHandler := &routes.TenantHandler{
+		TenantService: tenantService,
 		CommonService: commonService,
 	}
-	router := routes.NewRouter(server2, userHandler, menuHandler, roleHandler, deviceHandler)
-	return router
+	departmentHandler := &routes.DepartmentHandler{
+		DepartmentService: departmentService,
+		CommonService:     commonService,
+	}
+	apiKeyService := services.NewApiKeyService(db, commonService, permissionService, twoFactorService)
+	detectionHandler := &routes.DetectionHandler{
+		DetectionService: detectionService,
+		DeviceService:    deviceService,
+		ApiKeyService:    apiKeyService,
+		CommonService:    commonService,
+	}
+	trackService := services.NewTrackService(db, commonService, bus)
+	trackHandler := &routes.TrackHandler{
+		TrackService:  trackService,
+		CommonService: commonService,
+	}
+	realtimeService := services.NewRealtimeService(bus, permissionService)
+	realtimeHandler := &routes.RealtimeHandler{
+		RealtimeService: realtimeService,
+		SessionService:  sessionService,
+		ApiKeyService:   apiKeyService,
+	}
+	strikeService := services.NewStrikeService(db, commonService, deviceDriverService, bus)
+	strikeHandler := &routes.StrikeHandler{
+		StrikeService: strikeService,
+		CommonService: commonService,
+	}
+	zoneHandler := &routes.ZoneHandler{
+		ZoneService:   zoneService,
+		CommonService: commonService,
+	}
+	alertService := services.NewAlertService(db, commonService, bus)
+	alertHandler := &routes.AlertHandler{
+		AlertService:  alertService,
+		CommonService: commonService,
+	}
+	whitelistHandler := &routes.WhitelistHandler{
+		WhitelistService: whitelistService,
+		CommonService:    commonService,
+	}
+	auditService := services.NewAuditService(db)
+	auditHandler := &routes.AuditHandler{
+		AuditService:  auditService,
+		CommonService: commonService,
+	}
+	apiKeyHandler := &routes.ApiKeyHandler{
+		ApiKeyService: apiKeyService,
+		CommonService: commonService,
+	}
+	router := routes.NewRouter(server2, manager, permissionService, tenantService, auditService, apiKeyService, sessionService, authHandler, userHandler, menuHandler, roleHandler, deviceHandler, tenantHandler, departmentHandler, detectionHandler, trackHandler, realtimeHandler, strikeHandler, zoneHandler, alertHandler, whitelistHandler, auditHandler, apiKeyHandler)
+	mainApplication := &application{
+		Router:        router,
+		DeviceMonitor: deviceMonitorService,
+		TrackService:  trackService,
+		Realtime:      realtimeService,
+		StrikeService: strikeService,
+		DeviceDrivers: deviceDriverService,
+		AlertService:  alertService,
+	}
+	return mainApplication
 }
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AlertCondition 告警条件
+type AlertCondition string
+
+const (
+	AlertConditionZoneEnter     AlertCondition = "zone_enter"     // 目标进入区域
+	AlertConditionZoneDwell     AlertCondition = "zone_dwell"     // 目标在区域内停留超过阈值(秒)
+	AlertConditionSpeedAbove    AlertCondition = "speed_above"    // 速度超过阈值(米/秒)
+	AlertConditionAltitudeAbove AlertCondition = "altitude_above" // 高度超过阈值(米)
+	AlertConditionAltitudeBelow AlertCondition = "altitude_below" // 高度低于阈值(米)
+	AlertConditionUnknownSerial AlertCondition = "unknown_serial" // 目标无法识别序列号或序列号不在白名单中
+	AlertConditionDeviceOffline AlertCondition = "device_offline" // 设备模块离线
+)
+
+// UsesZone 条件是否与区域相关
+func (c AlertCondition) UsesZone() bool {
+	return c == AlertConditionZoneEnter || c == AlertConditionZoneDwell
+}
+
+// UsesThreshold 条件是否需要阈值
+func (c AlertCondition) UsesThreshold() bool {
+	switch c {
+	case AlertConditionZoneDwell, AlertConditionSpeedAbove, AlertConditionAltitudeAbove, AlertConditionAltitudeBelow:
+		return true
+	default:
+		return false
+	}
+}
+
+// AlertSeverity 告警级别
+type AlertSeverity string
+
+const (
+	AlertSeverityInfo     AlertSeverity = "info"     // 提示
+	AlertSeverityWarning  AlertSeverity = "warning"  // 警告
+	AlertSeverityCritical AlertSeverity = "critical" // 严重
+)
+
+// AlertStatus 告警状态
+type AlertStatus string
+
+const (
+	AlertStatusOpen         AlertStatus = "open"         // 未处理
+	AlertStatusAcknowledged AlertStatus = "acknowledged" // 已确认
+	AlertStatusClosed       AlertStatus = "closed"       // 已关闭
+)
+
+type AlertRuleModel struct {
+	ID        uuid.UUID      `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                    // 唯一ID
+	TenantID  *uuid.UUID     `json:"tenant_id" gorm:"uniqueIndex:idx_alert_rule_tenant_name;type:char(36);comment:租户ID"` // 租户ID
+	Name      string         `json:"name" gorm:"uniqueIndex:idx_alert_rule_tenant_name;size:64;not null;comment:规则名称"`   // 规则名称，租户内唯一
+	Condition AlertCondition `json:"condition" gorm:"size:32;not null;comment:告警条件"`                                     // 告警条件
+	Severity  AlertSeverity  `json:"severity" gorm:"size:16;not null;comment:告警级别"`                                      // 告警级别
+	Enabled   bool           `json:"enabled" gorm:"type:boolean;not null;comment:是否启用"`                                  // 是否启用
+	Remark    string         `json:"remark" gorm:"size:255;comment:备注"`                                                  // 备注
+
+	ZoneID    *uuid.UUID `json:"zone_id" gorm:"type:char(36);comment:区域ID"`   // 区域条件限定的区域，为空表示任意区域
+	DeviceID  *uuid.UUID `json:"device_id" gorm:"type:char(36);comment:设备ID"` // 限定的设备，为空表示全部设备
+	Threshold float64    `json:"threshold" gorm:"comment:阈值"`                 // 阈值：停留时长(秒)、速度(米/秒)或高度(米)
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (AlertRuleModel) TableName() string {
+	return "alert_rules"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (r *AlertRuleModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
+
+type AlertModel struct {
+	ID        uuid.UUID      `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                        // 唯一ID
+	TenantID  *uuid.UUID     `json:"tenant_id" gorm:"index:idx_alert_tenant;type:char(36);comment:租户ID"`                     // 租户ID
+	RuleID    uuid.UUID      `json:"rule_id" gorm:"index:idx_alert_rule_key,priority:1;type:char(36);not null;comment:规则ID"` // 规则ID
+	Key       string         `json:"key" gorm:"index:idx_alert_rule_key,priority:2;size:128;not null;comment:告警对象"`          // 告警对象标识，同一规则同一对象未关闭时只保留一条告警
+	RuleName  string         `json:"rule_name" gorm:"size:64;comment:规则名称"`                                                  // 触发时的规则名称
+	Condition AlertCondition `json:"condition" gorm:"size:32;not null;comment:告警条件"`                                         // 告警条件
+	Severity  AlertSeverity  `json:"severity" gorm:"index:idx_alert_severity;size:16;not null;comment:告警级别"`                 // 告警级别
+	Status    AlertStatus    `json:"status" gorm:"index:idx_alert_status;size:16;not null;comment:状态"`                       // 状态
+	Message   string         `json:"message" gorm:"size:255;comment:告警内容"`                                                   // 告警内容
+
+	DeviceID    *uuid.UUID `json:"device_id" gorm:"index:idx_alert_device;type:char(36);comment:设备ID"` // 设备ID
+	ZoneID      *uuid.UUID `json:"zone_id" gorm:"type:char(36);comment:区域ID"`                          // 区域ID
+	DetectionID *uuid.UUID `json:"detection_id" gorm:"type:char(36);comment:侦测事件ID"`                   // 最近一次触发的侦测事件ID
+	TargetKey   string     `json:"target_key" gorm:"size:64;comment:目标标识"`                             // 目标标识
+	Value       *float64   `json:"value" gorm:"comment:触发值"`                                           // 最近一次触发值
+
+	FirstSeenAt time.Time `json:"first_seen_at" gorm:"index:idx_alert_first_seen;not null;comment:首次触发时间"` // 首次触发时间
+	LastSeenAt  time.Time `json:"last_seen_at" gorm:"not null;comment:最后触发时间"`                             // 最后触发时间
+	Count       int       `json:"count" gorm:"not null;default:1;comment:触发次数"`                            // 未关闭期间的触发次数
+
+	AcknowledgedBy *uuid.UUID `json:"acknowledged_by" gorm:"type:char(36);comment:确认人ID"` // 确认人ID
+	AcknowledgedAt *time.Time `json:"acknowledged_at" gorm:"comment:确认时间"`                // 确认时间
+	ClosedBy       *uuid.UUID `json:"closed_by" gorm:"type:char(36);comment:关闭人ID"`       // 关闭人ID，自动关闭时为空
+	ClosedAt       *time.Time `json:"closed_at" gorm:"comment:关闭时间"`                      // 关闭时间
+	Note           string     `json:"note" gorm:"size:255;comment:处理说明"`                  // 确认或关闭时的说明
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (AlertModel) TableName() string {
+	return "alerts"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (a *AlertModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApiKeyModel struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                            // 唯一ID
+	TenantID   *uuid.UUID `json:"tenant_id" gorm:"index:idx_api_key_tenant;type:char(36);comment:租户ID"`       // 租户ID
+	Name       string     `json:"name" gorm:"size:64;not null;comment:名称"`                                    // 名称
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex:idx_api_key_prefix;size:16;not null;comment:密钥前缀"` // 密钥前缀，用于识别密钥和查找记录
+	SecretHash string     `json:"-" gorm:"size:64;not null;comment:密钥哈希"`                                     // 完整密钥的 SHA-256 哈希
+	Scopes     *ApiNames  `json:"scopes" gorm:"type:text;comment:授权的API名称"`                                   // 授权访问的路由名称
+	Remark     string     `json:"remark" gorm:"size:255;comment:备注"`                                          // 备注
+
+	UserID uuid.UUID  `json:"user_id" gorm:"index:idx_api_key_user;type:char(36);not null;comment:所有者ID"` // 所有者ID，使用密钥时以所有者身份访问，权限不超过所有者
+	User   *UserModel `json:"user,omitempty" gorm:"foreignKey:UserID;comment:所有者"`                        // 所有者
+
+	DeviceID *uuid.UUID   `json:"device_id" gorm:"index:idx_api_key_device;type:char(36);comment:绑定设备ID"` // 绑定设备ID，绑定后可代替设备接入密钥上报该设备的数据
+	Device   *DeviceModel `json:"device,omitempty" gorm:"foreignKey:DeviceID;comment:绑定设备"`               // 绑定设备
+
+	ExpiresAt  *time.Time `json:"expires_at" gorm:"comment:过期时间"`             // 过期时间，为空表示长期有效
+	LastUsedAt *time.Time `json:"last_used_at" gorm:"comment:最近使用时间"`         // 最近使用时间
+	LastUsedIP string     `json:"last_used_ip" gorm:"size:64;comment:最近使用IP"` // 最近使用IP
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (ApiKeyModel) TableName() string {
+	return "api_keys"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (k *ApiKeyModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return
+}
+
+// Expired 判断密钥在指定时间是否已过期
+func (k *ApiKeyModel) Expired(t time.Time) bool {
+	return k.ExpiresAt != nil && t.After(*k.ExpiresAt)
+}
+
+// HasScope 判断密钥是否授权访问指定名称的路由
+func (k *ApiKeyModel) HasScope(routeName string) bool {
+	return k.Scopes != nil && slices.Contains(*k.Scopes, routeName)
+}
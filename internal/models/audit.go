@@ -0,0 +1,71 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"xacms/internal/pkg/audit"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditChanges 请求产生的数据变更
+type AuditChanges []audit.Change
+
+// Value 实现 driver.Valuer 接口，以 JSON 格式存储
+func (c AuditChanges) Value() (driver.Value, error) {
+	data, err := sonic.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("无法将数据变更转换为数据库存储格式: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (c *AuditChanges) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return sonic.Unmarshal(v, c)
+	case string:
+		return sonic.UnmarshalString(v, c)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("无法将数据库中的值转换为数据变更: %v", value)
+	}
+}
+
+type AuditLogModel struct {
+	ID         uuid.UUID    `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                    // 唯一ID
+	TenantID   *uuid.UUID   `json:"tenant_id" gorm:"index:idx_audit_tenant;type:char(36);comment:租户ID"` // 请求限定的租户ID
+	UserID     *uuid.UUID   `json:"user_id" gorm:"index:idx_audit_user;type:char(36);comment:操作人ID"`    // 操作人ID
+	Username   string       `json:"username" gorm:"size:64;comment:操作人用户名"`                             // 操作时的用户名
+	RouteName  string       `json:"route_name" gorm:"index:idx_audit_route;size:128;comment:路由名称"`      // 路由名称，如 "用户管理.创建用户"
+	Method     string       `json:"method" gorm:"size:8;not null;comment:请求方法"`                         // 请求方法
+	Path       string       `json:"path" gorm:"size:255;comment:请求路径"`                                  // 请求路径
+	TargetID   string       `json:"target_id" gorm:"index:idx_audit_target;size:64;comment:操作对象ID"`     // 操作对象ID，取路径中的ID或新增数据的主键
+	Status     int          `json:"status" gorm:"not null;comment:响应状态码"`                               // 响应状态码
+	Success    bool         `json:"success" gorm:"index:idx_audit_success;not null;comment:是否成功"`       // 是否成功
+	Error      string       `json:"error" gorm:"size:255;comment:失败原因"`                                 // 失败原因
+	IP         string       `json:"ip" gorm:"size:64;comment:客户端IP"`                                    // 客户端IP
+	UserAgent  string       `json:"user_agent" gorm:"size:255;comment:客户端标识"`                           // 客户端标识
+	DurationMs int64        `json:"duration_ms" gorm:"comment:耗时(毫秒)"`                                  // 耗时(毫秒)
+	Changes    AuditChanges `json:"changes,omitempty" gorm:"type:text;comment:数据变更"`                    // 数据变更及变更前后的差异
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (AuditLogModel) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (a *AuditLogModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ButtonModel struct {
+	ID       uuid.UUID `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                       // 唯一ID
+	MenuID   uuid.UUID `json:"menu_id" gorm:"type:char(36);not null;index;comment:所属菜单ID"`            // 所属菜单ID
+	Code     string    `json:"code" gorm:"uniqueIndex:idx_button_code;size:64;not null;comment:按钮编码"` // 按钮编码，唯一，前端据此控制按钮显示
+	Name     string    `json:"name" gorm:"size:64;not null;comment:按钮名称"`                             // 按钮名称
+	ApiNames *ApiNames `json:"api_names" gorm:"type:text;comment:API路径"`                              // API路径
+	Order    uint      `json:"order" gorm:"type:int;not null;default:0;comment:排序"`                   // 排序
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (ButtonModel) TableName() string {
+	return "buttons"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (b *ButtonModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DepartmentModel struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                         // 唯一ID
+	TenantID    *uuid.UUID `json:"tenant_id" gorm:"index:idx_department_tenant;type:char(36);comment:租户ID"` // 租户ID
+	ParentID    *uuid.UUID `json:"parent_id" gorm:"index:idx_department_parent;type:char(36);comment:父级ID"` // 父级ID
+	Name        string     `json:"name" gorm:"size:64;not null;comment:部门名称"`                               // 部门名称
+	Description string     `json:"description" gorm:"size:255;comment:部门描述"`                                // 部门描述
+	Order       uint       `json:"order" gorm:"type:int;not null;default:0;comment:排序"`                     // 排序
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (DepartmentModel) TableName() string {
+	return "departments"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (d *DepartmentModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DetectionEventModel struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                                            // 唯一ID
+	TenantID  *uuid.UUID `json:"tenant_id" gorm:"index:idx_detection_tenant;type:char(36);comment:租户ID"`                                     // 租户ID，与设备一致
+	DeviceID  uuid.UUID  `json:"device_id" gorm:"index:idx_detection_device_time,priority:1;type:char(36);not null;comment:设备ID"`            // 设备ID
+	Timestamp time.Time  `json:"timestamp" gorm:"index:idx_detection_device_time,priority:2;index:idx_detection_time;not null;comment:侦测时间"` // 侦测时间
+	TrackID   *uuid.UUID `json:"track_id" gorm:"index:idx_detection_track;type:char(36);comment:航迹ID"`                                       // 所属航迹ID，归入航迹后设置
+
+	TargetID  string   `json:"target_id" gorm:"index:idx_detection_target;size:64;comment:目标ID"` // 侦测模块分配的目标ID
+	Frequency *float64 `json:"frequency" gorm:"comment:频率(MHz)"`                                 // 频率(MHz)
+	Protocol  string   `json:"protocol" gorm:"size:32;comment:通信协议"`                             // 通信协议
+
+	Latitude  *float64 `json:"latitude" gorm:"type:decimal(10,6);comment:纬度"`  // 纬度
+	Longitude *float64 `json:"longitude" gorm:"type:decimal(10,6);comment:经度"` // 经度
+	Altitude  *float64 `json:"altitude" gorm:"comment:高度(米)"`                  // 高度(米)
+	Heading   *float64 `json:"heading" gorm:"comment:航向(度)"`                   // 航向(度)，正北为 0
+	Speed     *float64 `json:"speed" gorm:"comment:速度(米/秒)"`                   // 速度(米/秒)
+	RSSI      *float64 `json:"rssi" gorm:"comment:信号强度(dBm)"`                  // 信号强度(dBm)
+
+	DroneModel  string `json:"drone_model" gorm:"size:64;comment:无人机型号"`                              // 无人机型号
+	DroneSerial string `json:"drone_serial" gorm:"index:idx_detection_serial;size:64;comment:无人机序列号"` // 无人机序列号
+
+	WhitelistEntryID *uuid.UUID `json:"whitelist_entry_id" gorm:"type:char(36);comment:白名单ID"`       // 序列号匹配的白名单ID
+	Friendly         bool       `json:"friendly" gorm:"index:idx_detection_friendly;comment:是否友方目标"` // 是否友方目标：匹配白名单且在有效期和允许区域内
+
+	Zones []*ZoneModel `json:"zones,omitempty" gorm:"many2many:detection_zones;joinForeignKey:DetectionID;joinReferences:ZoneID;comment:所在区域"` // 侦测点所在区域，写入时按区域范围标记
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (DetectionEventModel) TableName() string {
+	return "detection_events"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (d *DetectionEventModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return
+}
+
+// HasPosition 是否包含位置
+func (d *DetectionEventModel) HasPosition() bool {
+	return d.Latitude != nil && d.Longitude != nil
+}
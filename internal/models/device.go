@@ -1,16 +1,49 @@
 package models
 
 import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/bytedance/sonic"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// DriverConfig 设备驱动配置
+type DriverConfig map[string]any
+
+// Value 实现 driver.Valuer 接口，以 JSON 格式存储
+func (c DriverConfig) Value() (driver.Value, error) {
+	data, err := sonic.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("无法将驱动配置转换为数据库存储格式: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (c *DriverConfig) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return sonic.Unmarshal(v, c)
+	case string:
+		return sonic.UnmarshalString(v, c)
+	default:
+		return fmt.Errorf("无法将数据库中的值转换为驱动配置: %v", value)
+	}
+}
+
 type DeviceModel struct {
 	ID uuid.UUID `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"` // 唯一ID
 
-	Name      string  `json:"name" gorm:"uniqueIndex;size:64;not null;comment:设备名称"` // 设备名称
-	Longitude float64 `json:"longitude" gorm:"type:decimal(10,6);comment:设备经度"`      // 设备经度
-	Latitude  float64 `json:"latitude" gorm:"type:decimal(10,6);comment:设备纬度"`       // 设备纬度
+	TenantID *uuid.UUID `json:"tenant_id" gorm:"uniqueIndex:idx_device_tenant_name;type:char(36);comment:租户ID"` // 租户ID
+
+	Name      string  `json:"name" gorm:"uniqueIndex:idx_device_tenant_name;size:64;not null;comment:设备名称"` // 设备名称，租户内唯一
+	Longitude float64 `json:"longitude" gorm:"type:decimal(10,6);comment:设备经度"`                             // 设备经度
+	Latitude  float64 `json:"latitude" gorm:"type:decimal(10,6);comment:设备纬度"`                              // 设备纬度
 
 	// 侦测模块
 	DetectionID   int    `json:"detection_id" gorm:"uniqueIndex;type:char(36);comment:侦测模块ID"` // 侦测模块ID
@@ -22,13 +55,28 @@ type DeviceModel struct {
 	AnalysisIP string `json:"analysis_ip" gorm:"size:64;comment:解析模块IP"`                   // 解析模块IP
 
 	// FPV模块
-	FPVIP          string `json:"fpv_ip" gorm:"size:64;comment:FPV模块IP"`            // FPV模块IP
-	StreamServerIP string `json:"stream_server_ip" gorm:"size:64;comment:流媒体服务器IP"` // 流媒体服务器IP
+	FPVIP              string `json:"fpv_ip" gorm:"size:64;comment:FPV模块IP"`                                // FPV模块IP
+	StreamServerIP     string `json:"stream_server_ip" gorm:"size:64;comment:流媒体服务器IP"`                     // 流媒体服务器IP
+	StreamProtocol     string `json:"stream_protocol" gorm:"size:16;comment:推流协议"`                          // FPV模块向流媒体服务器推流的协议
+	StreamPath         string `json:"stream_path" gorm:"index:idx_device_stream_path;size:128;comment:流路径"` // 流媒体服务器上的流路径，为空时使用 fpv/<设备ID>
+	StreamUsername     string `json:"stream_username" gorm:"size:64;comment:推流用户名"`                         // 推流用户名
+	StreamPasswordHash string `json:"-" gorm:"size:255;comment:推流密码哈希"`                                     // 推流密码哈希，流媒体服务器推流鉴权时校验
 
 	// 打击模块
 	StrikeIP   string `json:"strike_ip" gorm:"size:64;comment:打击模块IP"` // 打击模块IP
 	StrikePort int    `json:"strike_port" gorm:"comment:打击模块端口"`       // 打击模块端口
 
+	// 设备驱动
+	Driver       string        `json:"driver" gorm:"index:idx_device_driver;size:32;comment:设备驱动"` // 设备驱动，为空时通过网络接入：HTTP 上报侦测数据、TCP 下发打击指令
+	DriverConfig *DriverConfig `json:"driver_config" gorm:"type:text;comment:驱动配置"`                // 驱动配置
+
+	DepartmentID *uuid.UUID `json:"department_id" gorm:"index:idx_device_department;type:char(36);comment:所属部门ID"` // 所属部门ID，数据范围按此过滤
+	CreatedBy    *uuid.UUID `json:"created_by" gorm:"index:idx_device_created_by;type:char(36);comment:创建人ID"`     // 创建人ID，仅本人数据范围按此过滤
+
+	IngestKeyHash string `json:"-" gorm:"size:64;comment:数据接入密钥哈希"` // 数据接入密钥哈希，设备上报侦测数据时校验
+
+	ModuleStatuses []*DeviceModuleStatusModel `json:"module_statuses,omitempty" gorm:"foreignKey:DeviceID;comment:模块在线状态"` // 模块在线状态
+
 	CommonModel
 }
 
@@ -37,6 +85,17 @@ func (DeviceModel) TableName() string {
 	return "devices"
 }
 
+// DefaultStreamPathPrefix 默认流路径前缀，后接设备ID
+const DefaultStreamPathPrefix = "fpv/"
+
+// StreamPathOrDefault 获取流媒体服务器上的流路径
+func (d *DeviceModel) StreamPathOrDefault() string {
+	if d.StreamPath != "" {
+		return d.StreamPath
+	}
+	return DefaultStreamPathPrefix + d.ID.String()
+}
+
 // BeforeCreate GORM钩子，在创建记录之前调用
 func (d *DeviceModel) BeforeCreate(tx *gorm.DB) (err error) {
 	if d.ID == uuid.Nil {
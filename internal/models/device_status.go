@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceModule 设备模块
+type DeviceModule string
+
+const (
+	DeviceModuleDetection DeviceModule = "detection" // 侦测模块
+	DeviceModuleAnalysis  DeviceModule = "analysis"  // 解析模块
+	DeviceModuleFPV       DeviceModule = "fpv"       // FPV模块
+	DeviceModuleStream    DeviceModule = "stream"    // 流媒体服务器
+	DeviceModuleVideo     DeviceModule = "video"     // FPV视频流
+	DeviceModuleStrike    DeviceModule = "strike"    // 打击模块
+)
+
+// DeviceModules 全部设备模块
+var DeviceModules = []DeviceModule{
+	DeviceModuleDetection,
+	DeviceModuleAnalysis,
+	DeviceModuleFPV,
+	DeviceModuleStream,
+	DeviceModuleVideo,
+	DeviceModuleStrike,
+}
+
+type DeviceModuleStatusModel struct {
+	DeviceID      uuid.UUID    `json:"device_id" gorm:"primaryKey;type:char(36);comment:设备ID"`         // 设备ID
+	Module        DeviceModule `json:"module" gorm:"primaryKey;size:32;comment:模块"`                    // 模块
+	Address       string       `json:"address" gorm:"size:128;comment:探测地址"`                           // 探测地址
+	Protocol      string       `json:"protocol" gorm:"size:16;comment:探测协议"`                           // 探测协议
+	Online        bool         `json:"online" gorm:"type:boolean;not null;default:false;comment:是否在线"` // 是否在线
+	LastSeenAt    *time.Time   `json:"last_seen_at" gorm:"comment:最后在线时间"`                             // 最后一次探测成功的时间
+	LastCheckedAt *time.Time   `json:"last_checked_at" gorm:"comment:最后探测时间"`                          // 最后一次探测的时间
+	LatencyMs     int64        `json:"latency_ms" gorm:"comment:探测耗时(毫秒)"`                             // 最后一次探测耗时
+	LastError     string       `json:"last_error" gorm:"size:255;comment:最后错误"`                        // 最后一次探测失败的原因
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (DeviceModuleStatusModel) TableName() string {
+	return "device_module_statuses"
+}
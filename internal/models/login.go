@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptModel struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                    // 唯一ID
+	TenantID  *uuid.UUID `json:"tenant_id" gorm:"index:idx_login_tenant;type:char(36);comment:租户ID"` // 用户所属租户ID
+	UserID    *uuid.UUID `json:"user_id" gorm:"index:idx_login_user;type:char(36);comment:用户ID"`     // 用户ID，用户名不存在时为空
+	Username  string     `json:"username" gorm:"size:64;not null;comment:登录用户名"`                     // 登录时输入的用户名
+	Success   bool       `json:"success" gorm:"not null;comment:是否成功"`                               // 是否成功
+	Reason    string     `json:"reason" gorm:"size:255;comment:失败原因"`                                // 失败原因
+	IP        string     `json:"ip" gorm:"index:idx_login_ip;size:64;comment:客户端IP"`                 // 客户端IP
+	UserAgent string     `json:"user_agent" gorm:"size:255;comment:客户端标识"`                           // 客户端标识
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (LoginAttemptModel) TableName() string {
+	return "login_attempts"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (l *LoginAttemptModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return
+}
@@ -49,11 +49,11 @@ type MenuModel struct {
 	Icon         *string    `json:"icon" gorm:"size:64;comment:侧边栏图标"`                                      // 侧边栏图标
 	Order        uint       `json:"order" gorm:"type:int;not null;default:0;comment:排序"`                    // 排序
 
+	Buttons []*ButtonModel `json:"buttons,omitempty" gorm:"foreignKey:MenuID;comment:菜单按钮"` // 菜单按钮
+
 	CommonModel
 }
 
-// TODO:缺少按钮表,需要和菜单表进行关联
-
 // TableName 设置表名
 func (MenuModel) TableName() string {
 	return "menus"
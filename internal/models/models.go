@@ -44,3 +44,29 @@ func (s Status) String() string {
 		return "unknown"
 	}
 }
+
+// DataScope 角色数据范围
+type DataScope uint8
+
+const (
+	DataScopeAll            DataScope = iota + 1 // 全部数据
+	DataScopeDepartment                          // 本部门数据
+	DataScopeDepartmentTree                      // 本部门及下级部门数据
+	DataScopeSelf                                // 仅本人数据
+)
+
+// String 返回数据范围的字符串表示
+func (d DataScope) String() string {
+	switch d {
+	case DataScopeAll:
+		return "all"
+	case DataScopeDepartment:
+		return "department"
+	case DataScopeDepartmentTree:
+		return "department_tree"
+	case DataScopeSelf:
+		return "self"
+	default:
+		return "unknown"
+	}
+}
@@ -6,12 +6,18 @@ import (
 )
 
 type RoleModel struct {
-	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                     // 唯一ID
-	Name        string    `json:"name" gorm:"uniqueIndex:idx_role_name;size:64;not null;comment:角色名称"` // 角色名称
-	Description string    `json:"description" gorm:"size:255;comment:角色描述"`                            // 角色描述
-	Order       uint      `json:"order" gorm:"type:int;not null;default:0;comment:排序"`                 // 排序
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                              // 唯一ID
+	TenantID    *uuid.UUID `json:"tenant_id" gorm:"uniqueIndex:idx_role_tenant_name;type:char(36);comment:租户ID"` // 租户ID，为空表示平台角色
+	Name        string     `json:"name" gorm:"uniqueIndex:idx_role_tenant_name;size:64;not null;comment:角色名称"`   // 角色名称，租户内唯一
+	Description string     `json:"description" gorm:"size:255;comment:角色描述"`                                     // 角色描述
+	Order       uint       `json:"order" gorm:"type:int;not null;default:0;comment:排序"`                          // 排序
+	DataScope   DataScope  `json:"data_scope" gorm:"type:tinyint;not null;default:1;comment:数据范围"`               // 数据范围，1-全部，2-本部门，3-本部门及下级，4-仅本人
+	IsSuper     bool       `json:"is_super" gorm:"type:boolean;not null;default:false;comment:是否超级管理员"`          // 是否超级管理员，拥有全部权限
 
-	Menus []*MenuModel `json:"menus" gorm:"many2many:role_menus;comment:角色菜单"` // 角色菜单
+	RequireTwoFactor bool `json:"require_two_factor" gorm:"type:boolean;not null;default:false;comment:是否强制双因素认证"` // 是否要求该角色的用户启用双因素认证
+
+	Menus   []*MenuModel   `json:"menus" gorm:"many2many:role_menus;comment:角色菜单"`     // 角色菜单
+	Buttons []*ButtonModel `json:"buttons" gorm:"many2many:role_buttons;comment:角色按钮"` // 角色按钮
 
 	// Users []*UserModel `json:"users" gorm:"foreignKey:RoleID;comment:角色用户"` // 角色用户
 
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionModel struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                           // 唯一ID，即令牌中的会话ID
+	TenantID     *uuid.UUID `json:"tenant_id" gorm:"index:idx_session_tenant;type:char(36);comment:租户ID"`      // 用户所属租户ID
+	UserID       uuid.UUID  `json:"user_id" gorm:"index:idx_session_user;type:char(36);not null;comment:用户ID"` // 用户ID
+	IP           string     `json:"ip" gorm:"size:64;comment:登录IP"`                                            // 登录IP
+	UserAgent    string     `json:"user_agent" gorm:"size:255;comment:客户端标识"`                                  // 登录设备或浏览器的客户端标识
+	LastActiveAt time.Time  `json:"last_active_at" gorm:"comment:最近活动时间"`                                      // 最近活动时间
+	LastActiveIP string     `json:"last_active_ip" gorm:"size:64;comment:最近活动IP"`                              // 最近活动IP
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"comment:过期时间"`                                            // 过期时间，与刷新令牌一致，刷新令牌时延长
+	RevokedAt    *time.Time `json:"revoked_at" gorm:"comment:撤销时间"`                                            // 撤销时间，为空表示未撤销
+	Current      bool       `json:"current" gorm:"-"`                                                          // 是否为当前请求使用的会话
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (SessionModel) TableName() string {
+	return "user_sessions"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (s *SessionModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return
+}
+
+// Active 判断会话在指定时间是否有效
+func (s *SessionModel) Active(t time.Time) bool {
+	return s.RevokedAt == nil && t.Before(s.ExpiresAt)
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StrikeAction 打击方式
+type StrikeAction string
+
+const (
+	StrikeActionJam   StrikeAction = "jam"   // 干扰压制
+	StrikeActionSpoof StrikeAction = "spoof" // 导航诱骗
+	StrikeActionLand  StrikeAction = "land"  // 迫降
+	StrikeActionStop  StrikeAction = "stop"  // 停止打击
+)
+
+// StrikeStatus 打击指令状态
+type StrikeStatus string
+
+const (
+	StrikeStatusRequested    StrikeStatus = "requested"    // 已申请，等待审批
+	StrikeStatusApproved     StrikeStatus = "approved"     // 已批准，等待下发
+	StrikeStatusRejected     StrikeStatus = "rejected"     // 已驳回
+	StrikeStatusExpired      StrikeStatus = "expired"      // 审批超时
+	StrikeStatusSent         StrikeStatus = "sent"         // 已下发到打击模块
+	StrikeStatusAcknowledged StrikeStatus = "acknowledged" // 打击模块已确认
+	StrikeStatusFailed       StrikeStatus = "failed"       // 下发失败或打击模块拒绝
+)
+
+// IsFinal 是否为终止状态
+func (s StrikeStatus) IsFinal() bool {
+	switch s {
+	case StrikeStatusRejected, StrikeStatusExpired, StrikeStatusAcknowledged, StrikeStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+type StrikeCommandModel struct {
+	ID       uuid.UUID    `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                   // 唯一ID
+	TenantID *uuid.UUID   `json:"tenant_id" gorm:"index:idx_strike_tenant;type:char(36);comment:租户ID"`               // 租户ID，与设备一致
+	DeviceID uuid.UUID    `json:"device_id" gorm:"index:idx_strike_device;type:char(36);not null;comment:执行打击的设备ID"` // 执行打击的设备ID
+	TrackID  *uuid.UUID   `json:"track_id" gorm:"index:idx_strike_track;type:char(36);comment:打击目标航迹ID"`             // 打击目标航迹ID
+	Action   StrikeAction `json:"action" gorm:"size:16;not null;comment:打击方式"`                                       // 打击方式
+	Duration int          `json:"duration" gorm:"not null;default:0;comment:持续时间(秒)"`                                // 持续时间(秒)，0 表示由打击模块决定
+	Reason   string       `json:"reason" gorm:"size:255;comment:申请原因"`                                               // 申请原因
+	Status   StrikeStatus `json:"status" gorm:"index:idx_strike_status;size:16;not null;comment:状态"`                 // 状态
+
+	RequestedBy  uuid.UUID  `json:"requested_by" gorm:"type:char(36);not null;comment:申请人ID"` // 申请人ID
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null;comment:审批截止时间"`                // 审批截止时间
+	DecidedBy    *uuid.UUID `json:"decided_by" gorm:"type:char(36);comment:审批人ID"`            // 批准或驳回人ID
+	DecidedAt    *time.Time `json:"decided_at" gorm:"comment:审批时间"`                           // 批准或驳回时间
+	DecisionNote string     `json:"decision_note" gorm:"size:255;comment:审批意见"`               // 审批意见
+	SentAt       *time.Time `json:"sent_at" gorm:"comment:下发时间"`                              // 下发时间
+	CompletedAt  *time.Time `json:"completed_at" gorm:"comment:完成时间"`                         // 确认或失败时间
+	Result       string     `json:"result" gorm:"size:255;comment:执行结果"`                      // 打击模块返回的消息或失败原因
+
+	Logs []StrikeCommandLogModel `json:"logs,omitempty" gorm:"foreignKey:CommandID"` // 状态变更记录
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (StrikeCommandModel) TableName() string {
+	return "strike_commands"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (s *StrikeCommandModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return
+}
+
+// ErrStrikeLogImmutable 打击指令状态变更记录不可修改
+var ErrStrikeLogImmutable = errors.New("打击指令状态变更记录不可修改或删除")
+
+// StrikeCommandLogModel 打击指令状态变更记录，只允许新增
+type StrikeCommandLogModel struct {
+	ID         uuid.UUID    `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                      // 唯一ID
+	TenantID   *uuid.UUID   `json:"tenant_id" gorm:"index:idx_strike_log_tenant;type:char(36);comment:租户ID"`              // 租户ID
+	CommandID  uuid.UUID    `json:"command_id" gorm:"index:idx_strike_log_command;type:char(36);not null;comment:打击指令ID"` // 打击指令ID
+	FromStatus StrikeStatus `json:"from_status" gorm:"size:16;comment:变更前状态"`                                             // 变更前状态，申请时为空
+	ToStatus   StrikeStatus `json:"to_status" gorm:"size:16;not null;comment:变更后状态"`                                      // 变更后状态
+	ActorID    *uuid.UUID   `json:"actor_id" gorm:"type:char(36);comment:操作人ID"`                                          // 操作人ID，系统操作时为空
+	Message    string       `json:"message" gorm:"size:255;comment:说明"`                                                   // 说明
+	CreatedAt  time.Time    `json:"created_at" gorm:"autoCreateTime;comment:记录时间"`                                        // 记录时间
+}
+
+// TableName 设置表名
+func (StrikeCommandLogModel) TableName() string {
+	return "strike_command_logs"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (l *StrikeCommandLogModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeUpdate GORM钩子，禁止修改记录
+func (l *StrikeCommandLogModel) BeforeUpdate(tx *gorm.DB) error {
+	return ErrStrikeLogImmutable
+}
+
+// BeforeDelete GORM钩子，禁止删除记录
+func (l *StrikeCommandLogModel) BeforeDelete(tx *gorm.DB) error {
+	return ErrStrikeLogImmutable
+}
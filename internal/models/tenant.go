@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TenantModel struct {
+	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                       // 唯一ID
+	Name        string    `json:"name" gorm:"uniqueIndex:idx_tenant_name;size:64;not null;comment:租户名称"` // 租户名称
+	Code        string    `json:"code" gorm:"uniqueIndex:idx_tenant_code;size:64;not null;comment:租户编码"` // 租户编码，用于子域名识别
+	Description string    `json:"description" gorm:"size:255;comment:租户描述"`                              // 租户描述
+	Status      *Status   `json:"status" gorm:"type:tinyint;not null;default:1;comment:状态"`              // 状态，1-启用，0-禁用
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (TenantModel) TableName() string {
+	return "tenants"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (t *TenantModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TrackStatus 航迹状态
+type TrackStatus string
+
+const (
+	TrackStatusActive TrackStatus = "active" // 跟踪中
+	TrackStatusClosed TrackStatus = "closed" // 已结束
+)
+
+type TrackModel struct {
+	ID        uuid.UUID   `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                               // 唯一ID
+	TenantID  *uuid.UUID  `json:"tenant_id" gorm:"index:idx_track_tenant;type:char(36);comment:租户ID"`                            // 租户ID，与设备一致
+	DeviceID  uuid.UUID   `json:"device_id" gorm:"index:idx_track_device_target,priority:1;type:char(36);not null;comment:设备ID"` // 设备ID
+	TargetKey string      `json:"target_key" gorm:"index:idx_track_device_target,priority:2;size:64;not null;comment:目标标识"`      // 目标标识，优先使用无人机序列号，否则使用目标ID
+	Status    TrackStatus `json:"status" gorm:"index:idx_track_status;size:16;not null;default:active;comment:状态"`               // 状态
+	StartTime time.Time   `json:"start_time" gorm:"index:idx_track_start_time;not null;comment:开始时间"`                            // 首个侦测点时间
+	EndTime   time.Time   `json:"end_time" gorm:"index:idx_track_end_time;not null;comment:结束时间"`                                // 最后一个侦测点时间
+
+	TargetID    string `json:"target_id" gorm:"size:64;comment:目标ID"`                             // 侦测模块分配的目标ID
+	DroneModel  string `json:"drone_model" gorm:"size:64;comment:无人机型号"`                          // 无人机型号
+	DroneSerial string `json:"drone_serial" gorm:"index:idx_track_serial;size:64;comment:无人机序列号"` // 无人机序列号
+	PointCount  int    `json:"point_count" gorm:"not null;default:0;comment:侦测点数量"`               // 侦测点数量
+
+	LastLatitude  *float64 `json:"last_latitude" gorm:"type:decimal(10,6);comment:最后纬度"`  // 最后纬度
+	LastLongitude *float64 `json:"last_longitude" gorm:"type:decimal(10,6);comment:最后经度"` // 最后经度
+	LastAltitude  *float64 `json:"last_altitude" gorm:"comment:最后高度(米)"`                  // 最后高度(米)
+	LastHeading   *float64 `json:"last_heading" gorm:"comment:最后航向(度)"`                   // 最后航向(度)
+	LastSpeed     *float64 `json:"last_speed" gorm:"comment:最后速度(米/秒)"`                   // 最后速度(米/秒)
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (TrackModel) TableName() string {
+	return "tracks"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (t *TrackModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
+
+// TrackKey 获取侦测事件所属航迹的目标标识，无法识别目标时返回空字符串
+func TrackKey(detection *DetectionEventModel) string {
+	if detection.DroneSerial != "" {
+		return detection.DroneSerial
+	}
+	return detection.TargetID
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeModel struct {
+	ID       uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                 // 唯一ID
+	UserID   uuid.UUID  `json:"user_id" gorm:"index:idx_recovery_user_code;type:char(36);not null;comment:用户ID"` // 用户ID
+	CodeHash string     `json:"-" gorm:"index:idx_recovery_user_code;size:64;not null;comment:恢复码哈希"`            // 恢复码的 SHA-256 哈希
+	UsedAt   *time.Time `json:"used_at" gorm:"comment:使用时间"`                                                     // 使用时间，为空表示未使用
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (RecoveryCodeModel) TableName() string {
+	return "user_recovery_codes"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (r *RecoveryCodeModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
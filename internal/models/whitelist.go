@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WhitelistEntryModel struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                                     // 唯一ID
+	TenantID   *uuid.UUID `json:"tenant_id" gorm:"uniqueIndex:idx_whitelist_tenant_serial;type:char(36);comment:租户ID"` // 租户ID
+	Serial     string     `json:"serial" gorm:"uniqueIndex:idx_whitelist_tenant_serial;size:64;not null;comment:序列号"`  // 无人机序列号，租户内唯一
+	DroneModel string     `json:"drone_model" gorm:"size:64;comment:无人机型号"`                                            // 无人机型号
+	Owner      string     `json:"owner" gorm:"size:64;comment:所属单位或人员"`                                                // 所属单位或人员
+	ValidFrom  *time.Time `json:"valid_from" gorm:"comment:生效时间"`                                                      // 生效时间，为空表示立即生效
+	ValidUntil *time.Time `json:"valid_until" gorm:"comment:失效时间"`                                                     // 失效时间，为空表示长期有效
+	Enabled    bool       `json:"enabled" gorm:"type:boolean;not null;comment:是否启用"`                                   // 是否启用
+	Remark     string     `json:"remark" gorm:"size:255;comment:备注"`                                                   // 备注
+
+	Zones []*ZoneModel `json:"zones,omitempty" gorm:"many2many:whitelist_zones;joinForeignKey:WhitelistEntryID;joinReferences:ZoneID;comment:允许区域"` // 允许活动的区域，为空表示不限区域
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (WhitelistEntryModel) TableName() string {
+	return "whitelist_entries"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (w *WhitelistEntryModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return
+}
+
+// ValidAt 判断指定时间是否在有效期内
+func (w *WhitelistEntryModel) ValidAt(t time.Time) bool {
+	if w.ValidFrom != nil && t.Before(*w.ValidFrom) {
+		return false
+	}
+	if w.ValidUntil != nil && t.After(*w.ValidUntil) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ZoneType 区域类型
+type ZoneType string
+
+const (
+	ZoneTypeProtected   ZoneType = "protected"    // 保护区
+	ZoneTypeNoFly       ZoneType = "no_fly"       // 禁飞区
+	ZoneTypeAlertBuffer ZoneType = "alert_buffer" // 预警缓冲区
+)
+
+// ZoneGeometry GeoJSON 几何对象，支持 Polygon、MultiPolygon，以及 Point 配合半径表示圆形区域
+type ZoneGeometry struct {
+	Type        string                 `json:"type"`
+	Coordinates sonic.NoCopyRawMessage `json:"coordinates"`
+}
+
+// Value 实现 driver.Valuer 接口，以 JSON 格式存储
+func (g ZoneGeometry) Value() (driver.Value, error) {
+	data, err := sonic.Marshal(g)
+	if err != nil {
+		return nil, fmt.Errorf("无法将几何对象转换为数据库存储格式: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (g *ZoneGeometry) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		// 坐标引用解码输入，驱动可能复用该缓冲区，需要复制
+		return sonic.UnmarshalString(string(v), g)
+	case string:
+		return sonic.UnmarshalString(v, g)
+	default:
+		return fmt.Errorf("无法将数据库中的值转换为几何对象: %v", value)
+	}
+}
+
+type ZoneModel struct {
+	ID       uuid.UUID  `json:"id" gorm:"primaryKey;type:char(36);comment:唯一ID"`                              // 唯一ID
+	TenantID *uuid.UUID `json:"tenant_id" gorm:"uniqueIndex:idx_zone_tenant_name;type:char(36);comment:租户ID"` // 租户ID
+	Name     string     `json:"name" gorm:"uniqueIndex:idx_zone_tenant_name;size:64;not null;comment:区域名称"`   // 区域名称，租户内唯一
+	Type     ZoneType   `json:"type" gorm:"index:idx_zone_type;size:16;not null;comment:区域类型"`                // 区域类型
+	Enabled  bool       `json:"enabled" gorm:"type:boolean;not null;comment:是否启用"`                            // 是否启用，停用的区域不参与侦测事件标记
+	Remark   string     `json:"remark" gorm:"size:255;comment:备注"`                                            // 备注
+
+	Geometry *ZoneGeometry `json:"geometry,omitempty" gorm:"type:text;comment:几何对象"` // GeoJSON 几何对象
+	Radius   float64       `json:"radius,omitempty" gorm:"comment:半径(米)"`            // 圆形区域半径(米)，几何对象为 Point 时有效
+
+	Devices []*DeviceModel `json:"devices,omitempty" gorm:"many2many:zone_devices;joinForeignKey:ZoneID;joinReferences:DeviceID;comment:关联设备"` // 关联设备
+
+	CommonModel
+}
+
+// TableName 设置表名
+func (ZoneModel) TableName() string {
+	return "zones"
+}
+
+// BeforeCreate GORM钩子，在创建记录之前调用
+func (z *ZoneModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if z.ID == uuid.Nil {
+		z.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,392 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Action 数据变更类型
+type Action string
+
+const (
+	ActionCreate Action = "create" // 新增
+	ActionUpdate Action = "update" // 更新
+	ActionDelete Action = "delete" // 删除
+)
+
+// maxChanges 每个请求最多记录的数据变更数，批量操作超出部分不记录
+const maxChanges = 100
+
+// ignoredFields 比较更新前后差异时忽略的字段
+var ignoredFields = map[string]struct{}{
+	"created_at": {},
+	"updated_at": {},
+}
+
+// Change 一次数据变更
+//
+// 新增时 After 为完整数据，删除时 Before 为完整数据，更新时两者只包含变化的字段。
+// 数据按模型的 JSON 序列化，json:"-" 的敏感字段不会被记录。
+type Change struct {
+	Table  string         `json:"table"`            // 表名
+	ID     string         `json:"id,omitempty"`     // 主键，复合主键以逗号分隔，按条件批量操作时为空
+	Action Action         `json:"action"`           // 变更类型
+	Before map[string]any `json:"before,omitempty"` // 变更前
+	After  map[string]any `json:"after,omitempty"`  // 变更后
+}
+
+// Recorder 记录一次请求内的数据变更，可被多个 goroutine 同时使用
+type Recorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+// Changes 获取已记录的数据变更
+func (r *Recorder) Changes() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Change(nil), r.changes...)
+}
+
+// add 记录数据变更
+func (r *Recorder) add(change Change) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.changes) < maxChanges {
+		r.changes = append(r.changes, change)
+	}
+}
+
+// recorderKey 上下文中变更记录器的键
+type recorderKey struct{}
+
+// WithRecorder 返回携带变更记录器的上下文，使用该上下文的数据库写操作会被记录
+func WithRecorder(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+// FromContext 获取上下文中的变更记录器
+func FromContext(ctx context.Context) (*Recorder, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	recorder, ok := ctx.Value(recorderKey{}).(*Recorder)
+	return recorder, ok
+}
+
+// beforeKey 语句实例中变更前数据的键
+const beforeKey = "audit:before"
+
+// Plugin GORM 数据变更审计插件
+//
+// 上下文中携带变更记录器时，记录新增、更新和删除的数据；
+// 按主键更新和删除时，先读取变更前的数据用于比较差异。
+// 多对多关联表的写入和保存关联时对已有数据的 upsert 不记录，关联变化通过 ReplaceAssociation 记录。
+type Plugin struct{}
+
+// Name 插件名称
+func (Plugin) Name() string {
+	return "audit"
+}
+
+// Initialize 注册回调
+func (Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", loadBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", loadBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("audit:create", recordCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:update", recordUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:delete", recordDelete)
+}
+
+// recorderOf 获取需要记录的语句对应的变更记录器
+func recorderOf(db *gorm.DB) (*Recorder, bool) {
+	recorder, ok := FromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil || db.Error != nil {
+		return nil, false
+	}
+	if isJoinTable(db.Statement.Schema) || isAssociationUpsert(db.Statement) {
+		return nil, false
+	}
+	return recorder, true
+}
+
+// isJoinTable 判断是否为多对多关联表，GORM 为关联表动态生成匿名结构体模型
+func isJoinTable(s *schema.Schema) bool {
+	return s.ModelType.Name() == ""
+}
+
+// isAssociationUpsert 判断是否为保存关联时对关联数据的 upsert（ON CONFLICT DO NOTHING）
+func isAssociationUpsert(stmt *gorm.Statement) bool {
+	c, ok := stmt.Clauses["ON CONFLICT"]
+	if !ok {
+		return false
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	return ok && onConflict.DoNothing
+}
+
+// loadBefore 按主键读取变更前的数据
+func loadBefore(db *gorm.DB) {
+	if _, ok := recorderOf(db); !ok {
+		return
+	}
+
+	id, ok := primaryKey(db, db.Statement.ReflectValue)
+	if !ok {
+		return
+	}
+	if before := load(db, id); before != nil {
+		db.InstanceSet(beforeKey, before)
+	}
+}
+
+// recordCreate 记录新增的数据
+func recordCreate(db *gorm.DB) {
+	recorder, ok := recorderOf(db)
+	if !ok {
+		return
+	}
+
+	each(db.Statement.ReflectValue, func(rv reflect.Value) {
+		id, _ := primaryKey(db, rv)
+		recorder.add(Change{
+			Table:  db.Statement.Table,
+			ID:     id,
+			Action: ActionCreate,
+			After:  snapshot(rv.Interface()),
+		})
+	})
+}
+
+// recordUpdate 记录更新前后变化的字段，无变化时不记录
+func recordUpdate(db *gorm.DB) {
+	recorder, ok := recorderOf(db)
+	if !ok {
+		return
+	}
+
+	change := Change{Table: db.Statement.Table, Action: ActionUpdate}
+	id, ok := primaryKey(db, db.Statement.ReflectValue)
+	if !ok {
+		// 按条件批量更新，只记录更新的内容
+		change.After = snapshot(db.Statement.Dest)
+		recorder.add(change)
+		return
+	}
+
+	change.ID = id
+	value, _ := db.InstanceGet(beforeKey)
+	before, _ := value.(map[string]any)
+	after := load(db, id)
+	change.Before, change.After = diff(before, after)
+	if len(change.Before) == 0 && len(change.After) == 0 {
+		return
+	}
+	recorder.add(change)
+}
+
+// recordDelete 记录删除的数据
+func recordDelete(db *gorm.DB) {
+	recorder, ok := recorderOf(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	change := Change{Table: db.Statement.Table, Action: ActionDelete}
+	if id, ok := primaryKey(db, db.Statement.ReflectValue); ok {
+		change.ID = id
+		value, _ := db.InstanceGet(beforeKey)
+		change.Before, _ = value.(map[string]any)
+	}
+	recorder.add(change)
+}
+
+// ReplaceAssociation 替换所有者的多对多关联，并将关联数据ID的变化记录为所有者的一次更新
+//
+// 变更前后的数据以关联字段的 JSON 名称为键，值为排序后的关联数据ID列表，关联未变化时不记录。
+func ReplaceAssociation(db *gorm.DB, owner any, name string, values ...any) error {
+	association := db.Model(owner).Association(name)
+	if association.Error != nil {
+		return association.Error
+	}
+
+	recorder, ok := FromContext(association.DB.Statement.Context)
+	if !ok {
+		return association.Replace(values...)
+	}
+
+	// 替换关联会修改语句的表和模型，先取得所有者的表名和主键
+	table := association.DB.Statement.Schema.Table
+	id, _ := primaryKey(association.DB, association.DB.Statement.ReflectValue)
+	rel := association.Relationship
+
+	before, err := associatedIDs(db, owner, rel)
+	if err != nil {
+		return err
+	}
+	if err := association.Replace(values...); err != nil {
+		return err
+	}
+	after, err := associatedIDs(db, owner, rel)
+	if err != nil {
+		return err
+	}
+	if slices.Equal(before, after) {
+		return nil
+	}
+
+	key := fieldName(rel.Field)
+	recorder.add(Change{
+		Table:  table,
+		ID:     id,
+		Action: ActionUpdate,
+		Before: map[string]any{key: before},
+		After:  map[string]any{key: after},
+	})
+	return nil
+}
+
+// associatedIDs 查询所有者当前关联数据的ID，按字符串排序
+func associatedIDs(db *gorm.DB, owner any, rel *schema.Relationship) ([]string, error) {
+	dest := reflect.New(reflect.SliceOf(rel.FieldSchema.ModelType))
+	if err := db.Model(owner).Association(rel.Name).Find(dest.Interface()); err != nil {
+		return nil, err
+	}
+
+	field := rel.FieldSchema.PrioritizedPrimaryField
+	items := dest.Elem()
+	ids := make([]string, 0, items.Len())
+	for i := 0; i < items.Len(); i++ {
+		value, _ := field.ValueOf(db.Statement.Context, items.Index(i))
+		ids = append(ids, toString(value))
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// fieldName 获取字段的 JSON 名称，未设置时使用字段名
+func fieldName(field *schema.Field) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// primaryKey 获取单条记录的主键，主键为零值或不是单条记录时返回 false
+func primaryKey(db *gorm.DB, rv reflect.Value) (string, bool) {
+	rv = reflect.Indirect(rv)
+	fields := db.Statement.Schema.PrimaryFields
+	if rv.Kind() != reflect.Struct || len(fields) == 0 {
+		return "", false
+	}
+
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value, isZero := field.ValueOf(db.Statement.Context, rv)
+		if isZero {
+			return "", false
+		}
+		values = append(values, toString(value))
+	}
+	return strings.Join(values, ","), true
+}
+
+// load 在同一连接上按主键读取当前数据，读取失败时返回 nil
+func load(db *gorm.DB, id string) map[string]any {
+	fields := db.Statement.Schema.PrimaryFields
+	values := strings.Split(id, ",")
+	if len(values) != len(fields) {
+		return nil
+	}
+
+	query := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(db.Statement.Table)
+	for i, field := range fields {
+		query = query.Where(db.Statement.Quote(field.DBName)+" = ?", values[i])
+	}
+
+	model := reflect.New(db.Statement.Schema.ModelType).Interface()
+	if err := query.Take(model).Error; err != nil {
+		return nil
+	}
+	return snapshot(model)
+}
+
+// diff 比较变更前后的数据，返回变化的字段
+func diff(before, after map[string]any) (map[string]any, map[string]any) {
+	changedBefore := make(map[string]any)
+	changedAfter := make(map[string]any)
+	for key, value := range after {
+		if _, ok := ignoredFields[key]; ok {
+			continue
+		}
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			changedBefore[key] = before[key]
+			changedAfter[key] = value
+		}
+	}
+	for key, value := range before {
+		if _, ok := ignoredFields[key]; ok {
+			continue
+		}
+		if _, ok := after[key]; !ok {
+			changedBefore[key] = value
+			changedAfter[key] = nil
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// snapshot 将数据按 JSON 序列化后转换为键值对
+func snapshot(value any) map[string]any {
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var result map[string]any
+	if err := sonic.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return result
+}
+
+// each 遍历单条或批量写入的记录
+func each(rv reflect.Value, fn func(reflect.Value)) {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len() && i < maxChanges; i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
+
+// toString 将主键值转换为字符串
+func toString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case interface{ String() string }:
+		return v.String()
+	default:
+		data, _ := sonic.Marshal(v)
+		return string(data)
+	}
+}
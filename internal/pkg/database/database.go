@@ -1,14 +1,19 @@
 package database
 
 import (
+	"errors"
 	"log"
 	"os"
 	"sync"
 	"xacms/internal/models"
+	"xacms/internal/pkg/audit"
+	"xacms/internal/pkg/password"
+	"xacms/internal/pkg/tenant"
 
 	_ "github.com/joho/godotenv/autoload"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -35,6 +40,16 @@ func NewDB() *gorm.DB {
 			log.Fatal("Failed to connect to database:", err)
 		}
 
+		// 租户隔离
+		if err := db.Use(tenant.Plugin{}); err != nil {
+			log.Fatal("Failed to register tenant plugin:", err)
+		}
+
+		// 审计请求产生的数据变更
+		if err := db.Use(audit.Plugin{}); err != nil {
+			log.Fatal("Failed to register audit plugin:", err)
+		}
+
 		// 启用 WAL 模式
 		_ = db.Exec("PRAGMA journal_mode=WAL;")
 		sqlDB, dbError := db.DB()
@@ -46,18 +61,182 @@ func NewDB() *gorm.DB {
 
 		// 自动迁移数据库
 		err = db.AutoMigrate(
+			&models.TenantModel{},
 			&models.RoleModel{},
+			&models.DepartmentModel{},
 			&models.MenuModel{},
+			&models.ButtonModel{},
 			&models.UserModel{},
 			&models.DeviceModel{},
+			&models.DeviceModuleStatusModel{},
+			&models.DetectionEventModel{},
+			&models.TrackModel{},
+			&models.StrikeCommandModel{},
+			&models.StrikeCommandLogModel{},
+			&models.ZoneModel{},
+			&models.AlertRuleModel{},
+			&models.AlertModel{},
+			&models.WhitelistEntryModel{},
+			&models.AuditLogModel{},
+			&models.LoginAttemptModel{},
+			&models.RecoveryCodeModel{},
+			&models.ApiKeyModel{},
+			&models.SessionModel{},
 		)
 		if err != nil {
 			log.Fatal("Failed to migrate database:", err)
 		}
+
+		// 角色和设备名称改为租户内唯一，删除旧的全局唯一索引
+		if err := dropLegacyIndexes(db); err != nil {
+			log.Fatal("Failed to drop legacy indexes:", err)
+		}
+
+		// 时间字段来自公共模型，无法在字段标签中声明索引
+		if err := createTimeIndexes(db); err != nil {
+			log.Fatal("Failed to create time indexes:", err)
+		}
+
+		// 登录不再接受明文密码，启动时将遗留的明文密码一次性转换为哈希
+		if err := hashPlaintextPasswords(db); err != nil {
+			log.Fatal("Failed to hash plaintext passwords:", err)
+		}
+
+		// 初始化管理员账号
+		if err := seedAdmin(db); err != nil {
+			log.Fatal("Failed to seed admin user:", err)
+		}
 	})
 	return db
 }
 
+// dropLegacyIndexes 删除已被租户内唯一索引取代的全局唯一索引
+func dropLegacyIndexes(db *gorm.DB) error {
+	legacy := []struct {
+		model any
+		name  string
+	}{
+		{&models.RoleModel{}, "idx_role_name"},
+		{&models.DeviceModel{}, "idx_devices_name"},
+	}
+	for _, index := range legacy {
+		if db.Migrator().HasIndex(index.model, index.name) {
+			if err := db.Migrator().DropIndex(index.model, index.name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// defaultAdminPassword 本地开发环境未配置 ADMIN_PASSWORD 时使用的初始管理员密码
+const defaultAdminPassword = "Admin@123456"
+
+// createTimeIndexes 为按时间范围查询和排序的记录表创建 created_at 索引
+func createTimeIndexes(db *gorm.DB) error {
+	indexes := []struct {
+		table string
+		name  string
+	}{
+		{"audit_logs", "idx_audit_created"},
+		{"login_attempts", "idx_login_created"},
+	}
+	for _, index := range indexes {
+		if db.Migrator().HasIndex(index.table, index.name) {
+			continue
+		}
+		if err := db.Exec("CREATE INDEX ? ON ? (created_at)", clause.Table{Name: index.name}, clause.Table{Name: index.table}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashPlaintextPasswords 将非受支持哈希格式的用户密码视为历史明文并转换为哈希
+func hashPlaintextPasswords(db *gorm.DB) error {
+	var users []models.UserModel
+	if err := db.Select("id", "username", "password").Find(&users).Error; err != nil {
+		return err
+	}
+
+	hasher := password.NewHasher()
+	for _, user := range users {
+		if password.IsHashed(user.Password) {
+			continue
+		}
+		hashed, err := hasher.Hash(user.Password)
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&models.UserModel{}).Where("id = ?", user.ID).UpdateColumn("password", hashed).Error; err != nil {
+			return err
+		}
+		log.Printf("警告: 用户 %s 的密码为明文存储，已转换为哈希", user.Username)
+	}
+	return nil
+}
+
+// seedAdmin 用户表为空时创建超级管理员角色及初始管理员，避免启用认证后无法登录
+//
+// 初始密码取自 ADMIN_PASSWORD；只有 APP_ENV 为 local 时才允许使用默认密码，
+// 其他环境未配置时不创建，配置为默认密码时拒绝启动。
+func seedAdmin(db *gorm.DB) error {
+	plain := os.Getenv("ADMIN_PASSWORD")
+	local := os.Getenv("APP_ENV") == "local"
+	switch {
+	case plain == "" && local:
+		plain = defaultAdminPassword
+	case plain == "":
+		return nil
+	case plain == defaultAdminPassword && !local:
+		return errors.New("非本地环境不能使用默认的 ADMIN_PASSWORD")
+	}
+
+	var count int64
+	if err := db.Model(&models.UserModel{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	username := os.Getenv("ADMIN_USERNAME")
+	if username == "" {
+		username = "admin"
+	}
+
+	hashed, err := password.NewHasher().Hash(plain)
+	if err != nil {
+		return err
+	}
+
+	role := &models.RoleModel{
+		Name:        "超级管理员",
+		Description: "拥有全部权限",
+		IsSuper:     true,
+	}
+	if err := db.Where("tenant_id IS NULL AND name = ?", role.Name).FirstOrCreate(role).Error; err != nil {
+		return err
+	}
+
+	status := models.StatusEnabled
+	admin := &models.UserModel{
+		Nickname: "管理员",
+		Username: username,
+		Password: hashed,
+		Email:    username + "@xacms.local",
+		Phone:    "",
+		Status:   &status,
+		RoleID:   &role.ID,
+	}
+	if err := db.Create(admin).Error; err != nil {
+		return err
+	}
+
+	log.Printf("已创建初始管理员账号: %s", username)
+	return nil
+}
+
 // CloseDB 关闭数据库连接
 func CloseDB() error {
 	if db != nil {
@@ -0,0 +1,96 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+	"xacms/internal/pkg/strike"
+
+	"github.com/google/uuid"
+)
+
+// ErrSessionClosed 驱动会话已关闭
+var ErrSessionClosed = errors.New("驱动会话已关闭")
+
+// Device 驱动连接设备所需的信息
+type Device struct {
+	ID               uuid.UUID      // 设备ID
+	Name             string         // 设备名称
+	Latitude         float64        // 设备纬度
+	Longitude        float64        // 设备经度
+	DetectionAddress string         // 侦测模块地址
+	StrikeAddress    string         // 打击模块地址
+	Config           map[string]any // 驱动配置
+}
+
+// Detection 设备产生的侦测数据
+type Detection struct {
+	Timestamp   time.Time
+	TargetID    string
+	Frequency   *float64 // 频率(MHz)
+	Protocol    string
+	Latitude    *float64
+	Longitude   *float64
+	Altitude    *float64 // 高度(米)
+	Heading     *float64 // 航向(度)
+	Speed       *float64 // 速度(米/秒)
+	RSSI        *float64 // 信号强度(dBm)
+	DroneModel  string
+	DroneSerial string
+}
+
+// Driver 设备协议驱动
+type Driver interface {
+	// Connect 连接设备，返回的会话在 Close 前持续产生侦测数据
+	Connect(ctx context.Context, device Device) (Session, error)
+}
+
+// Session 与单个设备的连接会话
+type Session interface {
+	// Health 检查设备是否正常
+	Health(ctx context.Context) error
+	// Configure 下发驱动配置
+	Configure(ctx context.Context, config map[string]any) error
+	// Events 设备产生的侦测数据，会话关闭后不再产生
+	Events() <-chan Detection
+	// Send 下发打击指令并等待确认
+	Send(ctx context.Context, command strike.Command) (*strike.Ack, error)
+	// Close 断开连接
+	Close() error
+}
+
+var (
+	mu      sync.RWMutex
+	drivers = map[string]Driver{
+		"simulator": simulator{},
+	}
+)
+
+// Register 注册指定名称的驱动，已存在时覆盖
+func Register(name string, driver Driver) {
+	mu.Lock()
+	defer mu.Unlock()
+	drivers[name] = driver
+}
+
+// Get 获取指定名称的驱动
+func Get(name string) (Driver, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	driver, ok := drivers[name]
+	return driver, ok
+}
+
+// Names 已注册的全部驱动名称
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,341 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+	"xacms/internal/pkg/strike"
+)
+
+// 模拟器默认配置
+const (
+	simulatorInterval = time.Second // 侦测数据产生间隔
+	simulatorTargets  = 2           // 同时存在的目标数量
+	simulatorRadius   = 3000.0      // 目标活动半径(米)
+	simulatorLifetime = 3 * time.Minute
+)
+
+// metersPerDegree 每纬度对应的距离(米)
+const metersPerDegree = 111320.0
+
+// simulatorProfile 模拟目标的机型参数
+type simulatorProfile struct {
+	model     string
+	protocol  string
+	frequency float64
+}
+
+var simulatorProfiles = []simulatorProfile{
+	{"DJI Mavic 3", "OcuSync 3.0", 5785},
+	{"DJI Mini 4 Pro", "OcuSync 4.0", 2437},
+	{"DJI Avata 2", "OcuSync 4.0", 5745},
+	{"Autel EVO II", "SkyLink", 2412},
+	{"FPV Racer", "ELRS", 915},
+}
+
+// simulator 模拟器驱动，无需硬件即可产生侦测数据并确认打击指令
+//
+// 配置项：interval 产生间隔（如 "500ms" 或秒数），targets 目标数量，radius 活动半径(米)。
+type simulator struct{}
+
+// Connect 实现 Driver 接口
+func (simulator) Connect(ctx context.Context, device Device) (Session, error) {
+	config, err := parseSimulatorConfig(device.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &simulatorSession{
+		device: device,
+		config: config,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		events: make(chan Detection, 256),
+		done:   make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// simulatorConfig 模拟器配置
+type simulatorConfig struct {
+	interval time.Duration
+	targets  int
+	radius   float64
+}
+
+// parseSimulatorConfig 解析模拟器配置，未配置的项使用默认值
+func parseSimulatorConfig(values map[string]any) (simulatorConfig, error) {
+	config := simulatorConfig{
+		interval: simulatorInterval,
+		targets:  simulatorTargets,
+		radius:   simulatorRadius,
+	}
+
+	if value, ok := values["interval"]; ok {
+		switch v := value.(type) {
+		case string:
+			interval, err := time.ParseDuration(v)
+			if err != nil {
+				return config, fmt.Errorf("interval 格式无效: %s", v)
+			}
+			config.interval = interval
+		default:
+			seconds, ok := number(v)
+			if !ok {
+				return config, fmt.Errorf("interval 格式无效: %v", v)
+			}
+			config.interval = time.Duration(seconds * float64(time.Second))
+		}
+		if config.interval < 100*time.Millisecond {
+			return config, fmt.Errorf("interval 不能小于 100ms")
+		}
+	}
+
+	if value, ok := values["targets"]; ok {
+		targets, ok := number(value)
+		if !ok || targets < 0 || targets > 50 || targets != math.Trunc(targets) {
+			return config, fmt.Errorf("targets 应为 0 到 50 的整数")
+		}
+		config.targets = int(targets)
+	}
+
+	if value, ok := values["radius"]; ok {
+		radius, ok := number(value)
+		if !ok || radius < 100 || radius > 50000 {
+			return config, fmt.Errorf("radius 应为 100 到 50000 之间的数字")
+		}
+		config.radius = radius
+	}
+
+	return config, nil
+}
+
+// number 将 JSON 解码得到的数字转换为 float64
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// simulatorTarget 模拟目标
+type simulatorTarget struct {
+	profile  simulatorProfile
+	serial   string
+	targetID string
+	north    float64 // 相对设备向北距离(米)
+	east     float64 // 相对设备向东距离(米)
+	altitude float64 // 高度(米)
+	heading  float64 // 航向(度)
+	speed    float64 // 速度(米/秒)
+	expires  time.Time
+}
+
+// simulatorSession 模拟器会话
+type simulatorSession struct {
+	device Device
+
+	mu       sync.Mutex
+	config   simulatorConfig
+	rand     *rand.Rand
+	targets  []*simulatorTarget
+	sequence int
+
+	events    chan Detection
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// run 按间隔移动目标并产生侦测数据
+func (s *simulatorSession) run() {
+	defer s.wg.Done()
+	defer close(s.events)
+
+	last := time.Now()
+	for {
+		s.mu.Lock()
+		interval := s.config.interval
+		s.mu.Unlock()
+
+		select {
+		case <-s.done:
+			return
+		case now := <-time.After(interval):
+			for _, detection := range s.step(now, now.Sub(last)) {
+				// 消费方处理不过来时丢弃，模拟真实设备的丢包
+				select {
+				case s.events <- detection:
+				default:
+				}
+			}
+			last = now
+		}
+	}
+}
+
+// step 推进全部目标并返回本轮侦测数据
+func (s *simulatorSession) step(now time.Time, elapsed time.Duration) []Detection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 移除到期目标并补足数量
+	alive := s.targets[:0]
+	for _, target := range s.targets {
+		if now.Before(target.expires) {
+			alive = append(alive, target)
+		}
+	}
+	s.targets = alive
+	for len(s.targets) < s.config.targets {
+		s.targets = append(s.targets, s.spawn(now))
+	}
+	if len(s.targets) > s.config.targets {
+		s.targets = s.targets[:s.config.targets]
+	}
+
+	detections := make([]Detection, 0, len(s.targets))
+	for _, target := range s.targets {
+		s.move(target, elapsed.Seconds())
+		detections = append(detections, s.detect(target, now))
+	}
+	return detections
+}
+
+// spawn 在活动半径边缘生成新目标，初始航向指向设备附近
+func (s *simulatorSession) spawn(now time.Time) *simulatorTarget {
+	s.sequence++
+	profile := simulatorProfiles[s.rand.Intn(len(simulatorProfiles))]
+	bearing := s.rand.Float64() * 2 * math.Pi
+	distance := s.config.radius * (0.6 + 0.4*s.rand.Float64())
+
+	north := distance * math.Cos(bearing)
+	east := distance * math.Sin(bearing)
+	heading := math.Mod(math.Atan2(-east, -north)*180/math.Pi+360+s.rand.Float64()*60-30, 360)
+
+	return &simulatorTarget{
+		profile:  profile,
+		serial:   fmt.Sprintf("SIM%s%04d", s.device.ID.String()[:4], s.sequence),
+		targetID: strconv.Itoa(s.sequence),
+		north:    north,
+		east:     east,
+		altitude: 50 + s.rand.Float64()*250,
+		heading:  heading,
+		speed:    5 + s.rand.Float64()*15,
+		expires:  now.Add(simulatorLifetime/2 + time.Duration(s.rand.Int63n(int64(simulatorLifetime)))),
+	}
+}
+
+// move 按航向和速度移动目标，超出活动半径时折返
+func (s *simulatorSession) move(target *simulatorTarget, seconds float64) {
+	target.heading = math.Mod(target.heading+s.rand.NormFloat64()*5+360, 360)
+	if math.Hypot(target.north, target.east) > s.config.radius {
+		target.heading = math.Mod(math.Atan2(-target.east, -target.north)*180/math.Pi+360, 360)
+	}
+
+	radians := target.heading * math.Pi / 180
+	target.north += target.speed * seconds * math.Cos(radians)
+	target.east += target.speed * seconds * math.Sin(radians)
+	target.altitude = math.Min(math.Max(target.altitude+s.rand.NormFloat64()*2, 20), 500)
+	target.speed = math.Min(math.Max(target.speed+s.rand.NormFloat64()*0.5, 2), 25)
+}
+
+// detect 生成目标的侦测数据，信号强度随距离衰减
+func (s *simulatorSession) detect(target *simulatorTarget, now time.Time) Detection {
+	latitude := s.device.Latitude + target.north/metersPerDegree
+	longitude := s.device.Longitude + target.east/(metersPerDegree*math.Cos(s.device.Latitude*math.Pi/180))
+	distance := math.Max(math.Hypot(target.north, target.east), 1)
+	rssi := -40 - 20*math.Log10(distance/10) + s.rand.NormFloat64()*2
+
+	frequency := target.profile.frequency
+	altitude := math.Round(target.altitude*10) / 10
+	heading := math.Round(target.heading*10) / 10
+	speed := math.Round(target.speed*10) / 10
+	rssi = math.Round(rssi*10) / 10
+	latitude = math.Round(latitude*1e6) / 1e6
+	longitude = math.Round(longitude*1e6) / 1e6
+
+	return Detection{
+		Timestamp:   now.UTC(),
+		TargetID:    target.targetID,
+		Frequency:   &frequency,
+		Protocol:    target.profile.protocol,
+		Latitude:    &latitude,
+		Longitude:   &longitude,
+		Altitude:    &altitude,
+		Heading:     &heading,
+		Speed:       &speed,
+		RSSI:        &rssi,
+		DroneModel:  target.profile.model,
+		DroneSerial: target.serial,
+	}
+}
+
+// Health 实现 Session 接口
+func (s *simulatorSession) Health(ctx context.Context) error {
+	select {
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+		return nil
+	}
+}
+
+// Configure 实现 Session 接口，新配置在下一轮生效
+func (s *simulatorSession) Configure(ctx context.Context, values map[string]any) error {
+	config, err := parseSimulatorConfig(values)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.config = config
+	s.mu.Unlock()
+	return nil
+}
+
+// Events 实现 Session 接口
+func (s *simulatorSession) Events() <-chan Detection {
+	return s.events
+}
+
+// Send 实现 Session 接口，迫降和干扰指令会使目标消失
+func (s *simulatorSession) Send(ctx context.Context, command strike.Command) (*strike.Ack, error) {
+	if err := s.Health(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if command.Action == "stop" {
+		return &strike.Ack{Accepted: true, Message: "模拟器已停止打击"}, nil
+	}
+
+	for i, target := range s.targets {
+		if command.DroneSerial != "" && target.serial == command.DroneSerial {
+			s.targets = append(s.targets[:i], s.targets[i+1:]...)
+			return &strike.Ack{Accepted: true, Message: fmt.Sprintf("模拟器已对目标 %s 执行 %s", target.serial, command.Action)}, nil
+		}
+	}
+	return &strike.Ack{Accepted: true, Message: fmt.Sprintf("模拟器已执行 %s，未匹配到目标", command.Action)}, nil
+}
+
+// Close 实现 Session 接口
+func (s *simulatorSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,112 @@
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// 事件主题
+const (
+	TopicDeviceStatus = "device.status"     // 设备模块在线状态变化
+	TopicDetection    = "detection.created" // 新的侦测事件，内容为 models.DetectionEventModel
+	TopicTrack        = "track.updated"     // 航迹创建、更新或结束，内容为 models.TrackModel
+	TopicStrike       = "strike.updated"    // 打击指令状态变化，内容为 models.StrikeCommandModel
+	TopicAlert        = "alert.updated"     // 告警触发、确认或关闭，内容为 models.AlertModel
+)
+
+// Event 事件
+type Event struct {
+	Topic    string     `json:"topic"`               // 主题
+	TenantID *uuid.UUID `json:"tenant_id,omitempty"` // 租户ID，为空表示平台数据
+	Time     time.Time  `json:"time"`                // 发生时间
+	Payload  any        `json:"payload"`             // 事件内容
+}
+
+// DeviceStatusPayload 设备模块在线状态变化事件内容
+type DeviceStatusPayload struct {
+	DeviceID   uuid.UUID  `json:"device_id"`    // 设备ID
+	DeviceName string     `json:"device_name"`  // 设备名称
+	Module     string     `json:"module"`       // 模块
+	Address    string     `json:"address"`      // 探测地址
+	Online     bool       `json:"online"`       // 当前是否在线
+	Previous   *bool      `json:"previous"`     // 变化前是否在线，首次探测时为空
+	LastSeenAt *time.Time `json:"last_seen_at"` // 最后在线时间
+	Error      string     `json:"error"`        // 探测失败原因
+}
+
+// Handler 事件处理函数
+type Handler func(Event)
+
+// Bus 进程内事件总线
+//
+// 处理函数在发布者的 goroutine 中同步调用，耗时的处理应自行异步执行。
+type Bus struct {
+	mu       sync.RWMutex
+	nextID   uint64
+	handlers map[string]map[uint64]Handler // 键: 主题, 值: 订阅ID到处理函数的映射
+}
+
+var (
+	bus  *Bus
+	once sync.Once
+)
+
+// NewBus 创建事件总线实例（单例模式）
+func NewBus() *Bus {
+	once.Do(func() {
+		bus = &Bus{
+			handlers: make(map[string]map[uint64]Handler),
+		}
+	})
+	return bus
+}
+
+// Subscribe 订阅主题，返回取消订阅函数
+func (b *Bus) Subscribe(topic string, handler Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	if b.handlers[topic] == nil {
+		b.handlers[topic] = make(map[uint64]Handler)
+	}
+	b.handlers[topic][id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers[topic], id)
+	}
+}
+
+// Publish 发布事件，未设置发生时间时使用当前时间
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[e.Topic]))
+	for _, handler := range b.handlers[e.Topic] {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.dispatch(handler, e)
+	}
+}
+
+// dispatch 调用处理函数，避免单个处理函数 panic 影响发布者
+func (b *Bus) dispatch(handler Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("处理事件 %s 失败: %v", e.Topic, r)
+		}
+	}()
+	handler(e)
+}
@@ -0,0 +1,164 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/bytedance/sonic"
+)
+
+// earthRadius 地球平均半径(米)
+const earthRadius = 6371008.8
+
+// ErrInvalidGeometry 几何对象无效
+var ErrInvalidGeometry = errors.New("几何对象无效")
+
+// Shape 区域形状，坐标均为 WGS84 经纬度
+type Shape interface {
+	Contains(longitude, latitude float64) bool
+}
+
+// Polygon GeoJSON 多边形，第一个环为外边界，其余为内部空洞
+type Polygon [][][]float64
+
+// Contains 判断点是否在多边形内：在外边界内且不在任何空洞内
+func (p Polygon) Contains(longitude, latitude float64) bool {
+	if len(p) == 0 || !ringContains(p[0], longitude, latitude) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, longitude, latitude) {
+			return false
+		}
+	}
+	return true
+}
+
+// MultiPolygon GeoJSON 多多边形
+type MultiPolygon []Polygon
+
+// Contains 判断点是否在任一多边形内
+func (m MultiPolygon) Contains(longitude, latitude float64) bool {
+	for _, polygon := range m {
+		if polygon.Contains(longitude, latitude) {
+			return true
+		}
+	}
+	return false
+}
+
+// Circle 圆形区域
+type Circle struct {
+	Longitude float64 // 圆心经度
+	Latitude  float64 // 圆心纬度
+	Radius    float64 // 半径(米)
+}
+
+// Contains 判断点到圆心的距离是否不超过半径
+func (c Circle) Contains(longitude, latitude float64) bool {
+	return Distance(c.Longitude, c.Latitude, longitude, latitude) <= c.Radius
+}
+
+// NewShape 根据 GeoJSON 几何类型和坐标创建区域形状，Point 需配合半径表示圆形
+func NewShape(geometryType string, coordinates []byte, radius float64) (Shape, error) {
+	switch geometryType {
+	case "Polygon":
+		var polygon Polygon
+		if err := sonic.Unmarshal(coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("%w: Polygon 坐标格式错误", ErrInvalidGeometry)
+		}
+		if err := validatePolygon(polygon); err != nil {
+			return nil, err
+		}
+		return polygon, nil
+
+	case "MultiPolygon":
+		var multi MultiPolygon
+		if err := sonic.Unmarshal(coordinates, &multi); err != nil {
+			return nil, fmt.Errorf("%w: MultiPolygon 坐标格式错误", ErrInvalidGeometry)
+		}
+		if len(multi) == 0 {
+			return nil, fmt.Errorf("%w: MultiPolygon 至少包含一个多边形", ErrInvalidGeometry)
+		}
+		for _, polygon := range multi {
+			if err := validatePolygon(polygon); err != nil {
+				return nil, err
+			}
+		}
+		return multi, nil
+
+	case "Point":
+		var point []float64
+		if err := sonic.Unmarshal(coordinates, &point); err != nil || len(point) < 2 {
+			return nil, fmt.Errorf("%w: Point 坐标格式错误", ErrInvalidGeometry)
+		}
+		if err := validatePosition(point); err != nil {
+			return nil, err
+		}
+		if radius <= 0 {
+			return nil, fmt.Errorf("%w: 圆形区域半径必须大于 0", ErrInvalidGeometry)
+		}
+		return Circle{Longitude: point[0], Latitude: point[1], Radius: radius}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: 不支持的几何类型 %s", ErrInvalidGeometry, geometryType)
+	}
+}
+
+// Distance 计算两点间的大圆距离(米)
+func Distance(longitude1, latitude1, longitude2, latitude2 float64) float64 {
+	phi1 := latitude1 * math.Pi / 180
+	phi2 := latitude2 * math.Pi / 180
+	deltaPhi := (latitude2 - latitude1) * math.Pi / 180
+	deltaLambda := (longitude2 - longitude1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// ringContains 射线法判断点是否在环内，环为首尾相同的闭合坐标序列
+func ringContains(ring [][]float64, longitude, latitude float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > latitude) != (yj > latitude) && longitude < (xj-xi)*(latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// validatePolygon 校验多边形：至少一个环，每个环至少 4 个坐标且首尾相同
+func validatePolygon(polygon Polygon) error {
+	if len(polygon) == 0 {
+		return fmt.Errorf("%w: Polygon 至少包含一个环", ErrInvalidGeometry)
+	}
+	for _, ring := range polygon {
+		if len(ring) < 4 {
+			return fmt.Errorf("%w: 多边形的环至少包含 4 个坐标", ErrInvalidGeometry)
+		}
+		for _, position := range ring {
+			if err := validatePosition(position); err != nil {
+				return err
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("%w: 多边形的环必须首尾相同", ErrInvalidGeometry)
+		}
+	}
+	return nil
+}
+
+// validatePosition 校验坐标：[经度, 纬度] 或 [经度, 纬度, 高度]
+func validatePosition(position []float64) error {
+	if len(position) < 2 || len(position) > 3 {
+		return fmt.Errorf("%w: 坐标应为 [经度, 纬度]", ErrInvalidGeometry)
+	}
+	if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+		return fmt.Errorf("%w: 坐标超出经纬度范围", ErrInvalidGeometry)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// userKey 上下文中当前用户ID的键
+type userKey struct{}
+
+// WithUserID 返回携带当前用户ID的上下文，供数据范围等按用户过滤的逻辑使用
+func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserIDFromContext 获取上下文中的当前用户ID
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	userID, ok := ctx.Value(userKey{}).(uuid.UUID)
+	return userID, ok
+}
@@ -0,0 +1,133 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var errInvalidArgon2Hash = errors.New("argon2id 哈希格式无效")
+
+// argon2Params argon2id 参数
+type argon2Params struct {
+	memory      uint32 // 内存开销（KiB）
+	iterations  uint32 // 迭代次数
+	parallelism uint8  // 并行度
+	saltLength  uint32 // 盐长度
+	keyLength   uint32 // 哈希长度
+}
+
+// argon2idHasher argon2id 算法实现
+type argon2idHasher struct {
+	params argon2Params
+}
+
+// newArgon2id 创建 argon2id 哈希器，参数可通过 ARGON2_MEMORY、ARGON2_ITERATIONS、ARGON2_PARALLELISM 配置
+func newArgon2id() *argon2idHasher {
+	return &argon2idHasher{
+		params: argon2Params{
+			memory:      uint32(uintFromEnv("ARGON2_MEMORY", 64*1024, 32)),
+			iterations:  uint32(uintFromEnv("ARGON2_ITERATIONS", 3, 32)),
+			parallelism: uint8(uintFromEnv("ARGON2_PARALLELISM", 2, 8)),
+			saltLength:  16,
+			keyLength:   32,
+		},
+	}
+}
+
+// uintFromEnv 从环境变量读取正整数，未配置或格式错误时返回默认值
+func uintFromEnv(key string, fallback uint64, bitSize int) uint64 {
+	v, err := strconv.ParseUint(os.Getenv(key), 10, bitSize)
+	if err != nil || v == 0 {
+		return fallback
+	}
+	return v
+}
+
+// Name 算法名称
+func (h *argon2idHasher) Name() string {
+	return AlgorithmArgon2id
+}
+
+// Match 判断哈希是否由 argon2id 生成
+func (h *argon2idHasher) Match(hashed string) bool {
+	return hasPrefix(hashed, "$argon2id$")
+}
+
+// Hash 计算密码哈希，输出 PHC 字符串格式
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify 校验密码
+func (h *argon2idHasher) Verify(hashed, password string) (bool, error) {
+	p, salt, key, err := decodeArgon2id(hashed)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash 参数变化时需要重新计算
+func (h *argon2idHasher) NeedsRehash(hashed string) bool {
+	p, _, _, err := decodeArgon2id(hashed)
+	if err != nil {
+		return true
+	}
+	return p != h.params
+}
+
+// decodeArgon2id 解析 PHC 字符串格式的 argon2id 哈希
+func decodeArgon2id(hashed string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+
+	// 格式: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+
+	p.saltLength = uint32(len(salt))
+	p.keyLength = uint32(len(key))
+	return p, salt, key, nil
+}
@@ -0,0 +1,63 @@
+package password
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptHasher bcrypt 算法实现
+type bcryptHasher struct {
+	cost int
+}
+
+// newBcrypt 创建 bcrypt 哈希器，成本因子可通过 BCRYPT_COST 配置
+func newBcrypt() *bcryptHasher {
+	cost := bcrypt.DefaultCost
+	if v, err := strconv.Atoi(os.Getenv("BCRYPT_COST")); err == nil && v >= bcrypt.MinCost && v <= bcrypt.MaxCost {
+		cost = v
+	}
+	return &bcryptHasher{cost: cost}
+}
+
+// Name 算法名称
+func (h *bcryptHasher) Name() string {
+	return AlgorithmBcrypt
+}
+
+// Match 判断哈希是否由 bcrypt 生成
+func (h *bcryptHasher) Match(hashed string) bool {
+	return hasPrefix(hashed, "$2a$", "$2b$", "$2y$")
+}
+
+// Hash 计算密码哈希
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify 校验密码
+func (h *bcryptHasher) Verify(hashed, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// NeedsRehash 成本因子变化时需要重新计算
+func (h *bcryptHasher) NeedsRehash(hashed string) bool {
+	cost, err := bcrypt.Cost([]byte(hashed))
+	if err != nil {
+		return true
+	}
+	return cost != h.cost
+}
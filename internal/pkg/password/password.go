@@ -0,0 +1,126 @@
+package password
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// 支持的哈希算法
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// ErrUnknownHash 哈希格式无法识别，历史明文密码需先在启动迁移中转换为哈希
+var ErrUnknownHash = errors.New("无法识别的密码哈希格式")
+
+// Hasher 密码哈希器接口
+type Hasher interface {
+	// Hash 计算密码哈希
+	Hash(password string) (string, error)
+	// Verify 校验密码是否与哈希匹配
+	Verify(hashed, password string) (bool, error)
+	// NeedsRehash 判断哈希是否需要按当前参数重新计算
+	NeedsRehash(hashed string) bool
+}
+
+// NewHasher 根据环境变量 PASSWORD_HASHER 创建密码哈希器，默认使用 argon2id
+//
+// 返回的哈希器能够校验任意受支持算法生成的哈希，不接受明文密码，
+// 新哈希始终使用当前配置的算法和参数。
+func NewHasher() Hasher {
+	var current algorithm
+	switch algo := os.Getenv("PASSWORD_HASHER"); algo {
+	case AlgorithmBcrypt:
+		current = newBcrypt()
+	case "", AlgorithmArgon2id:
+		current = newArgon2id()
+	default:
+		log.Warnf("不支持的密码哈希算法: %s，使用 %s", algo, AlgorithmArgon2id)
+		current = newArgon2id()
+	}
+
+	return &multiHasher{
+		current:    current,
+		algorithms: supported(),
+	}
+}
+
+// algorithm 单个哈希算法实现
+type algorithm interface {
+	Hasher
+	// Name 算法名称
+	Name() string
+	// Match 判断哈希是否由该算法生成
+	Match(hashed string) bool
+}
+
+// multiHasher 组合多种算法的哈希器
+type multiHasher struct {
+	current    algorithm
+	algorithms []algorithm
+}
+
+// Hash 使用当前算法计算密码哈希
+func (h *multiHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify 根据哈希格式选择算法校验密码
+func (h *multiHasher) Verify(hashed, password string) (bool, error) {
+	if algo := h.find(hashed); algo != nil {
+		return algo.Verify(hashed, password)
+	}
+
+	return false, ErrUnknownHash
+}
+
+// NeedsRehash 算法或参数与当前配置不一致时需要重新计算
+func (h *multiHasher) NeedsRehash(hashed string) bool {
+	algo := h.find(hashed)
+	if algo == nil || algo.Name() != h.current.Name() {
+		return true
+	}
+	return h.current.NeedsRehash(hashed)
+}
+
+// IsHashed 判断值是否为受支持算法生成的哈希
+func IsHashed(hashed string) bool {
+	for _, algo := range supported() {
+		if algo.Match(hashed) {
+			return true
+		}
+	}
+	return false
+}
+
+// supported 返回所有受支持的哈希算法
+func supported() []algorithm {
+	return []algorithm{
+		newBcrypt(),
+		newArgon2id(),
+	}
+}
+
+// find 查找生成该哈希的算法
+func (h *multiHasher) find(hashed string) algorithm {
+	for _, algo := range h.algorithms {
+		if algo.Match(hashed) {
+			return algo
+		}
+	}
+	return nil
+}
+
+// hasPrefix 判断哈希是否以任一前缀开头
+func hasPrefix(hashed string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(hashed, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prober 探测器，检查地址是否可达
+type Prober interface {
+	Probe(ctx context.Context, address string) error
+}
+
+// ProberFunc 函数形式的探测器
+type ProberFunc func(ctx context.Context, address string) error
+
+// Probe 实现 Prober 接口
+func (f ProberFunc) Probe(ctx context.Context, address string) error {
+	return f(ctx, address)
+}
+
+var (
+	mu      sync.RWMutex
+	probers = map[string]Prober{
+		"tcp":  ProberFunc(probeTCP),
+		"http": ProberFunc(probeHTTP),
+		"rtsp": ProberFunc(probeRTSP),
+	}
+)
+
+// Register 注册指定协议的探测器，已存在时覆盖
+func Register(protocol string, prober Prober) {
+	mu.Lock()
+	defer mu.Unlock()
+	probers[protocol] = prober
+}
+
+// Get 获取指定协议的探测器
+func Get(protocol string) (Prober, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	prober, ok := probers[protocol]
+	return prober, ok
+}
+
+// probeTCP 建立 TCP 连接即视为可达
+func probeTCP(ctx context.Context, address string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeHTTP 收到任意 HTTP 响应即视为可达，服务端错误除外
+func probeHTTP(ctx context.Context, address string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address+"/", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("HTTP 状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// probeRTSP 地址为 host:port 时收到任意 RTSP 响应即视为可达；
+// 地址为 rtsp:// 开头的完整地址时发送 DESCRIBE，流存在(200)才视为可用
+func probeRTSP(ctx context.Context, address string) error {
+	method, target := "OPTIONS", "rtsp://"+address+"/"
+	if strings.HasPrefix(address, "rtsp://") {
+		method, target = "DESCRIBE", address
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "554")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+	}
+
+	request := fmt.Sprintf("%s %s RTSP/1.0\r\nCSeq: 1\r\nAccept: application/sdp\r\nUser-Agent: xacms\r\n\r\n", method, target)
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return err
+	}
+
+	// 状态行：RTSP/1.0 200 OK
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "RTSP/") {
+		return fmt.Errorf("无效的 RTSP 响应: %q", strings.TrimSpace(line))
+	}
+	if method == "DESCRIBE" && fields[1] != "200" {
+		return fmt.Errorf("RTSP 状态码 %s", fields[1])
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Package realtime 实时推送订阅管理，将事件总线中的事件分发给 WebSocket 和 SSE 客户端
+package realtime
+
+import (
+	"sort"
+	"sync"
+	"xacms/internal/pkg/event"
+
+	"github.com/bytedance/sonic"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// 推送消息类型
+const (
+	MessageEvent        = "event"        // 事件
+	MessageSubscribed   = "subscribed"   // 订阅主题已变化，Topics 为当前全部订阅主题
+	MessageError        = "error"        // 错误
+	MessageDisconnected = "disconnected" // 服务端断开连接，如客户端消费过慢或服务关闭
+)
+
+// Message 推送给客户端的消息
+type Message struct {
+	Type    string   `json:"type"`              // 消息类型
+	Topics  []string `json:"topics,omitempty"`  // 当前订阅主题
+	Message string   `json:"message,omitempty"` // 错误或断开原因
+	*event.Event
+}
+
+// Delivery 待发送给客户端的事件
+type Delivery struct {
+	Topic string // 主题
+	Data  []byte // 序列化后的 Message
+}
+
+// Client 订阅客户端
+type Client struct {
+	tenantID *uuid.UUID // 限定的租户，为空时接收全部租户的事件
+
+	mu     sync.RWMutex
+	topics map[string]struct{}
+
+	send      chan Delivery
+	done      chan struct{}
+	closeOnce sync.Once
+	reason    string
+}
+
+// Subscribe 订阅主题
+func (c *Client) Subscribe(topics ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, topic := range topics {
+		c.topics[topic] = struct{}{}
+	}
+}
+
+// Unsubscribe 取消订阅主题
+func (c *Client) Unsubscribe(topics ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, topic := range topics {
+		delete(c.topics, topic)
+	}
+}
+
+// Topics 当前订阅的全部主题
+func (c *Client) Topics() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	topics := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Deliveries 待发送的事件
+func (c *Client) Deliveries() <-chan Delivery {
+	return c.send
+}
+
+// Done 客户端被断开时关闭
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Reason 服务端断开客户端的原因，客户端主动断开时为空
+func (c *Client) Reason() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reason
+}
+
+// wants 判断客户端是否接收该事件
+func (c *Client) wants(e *event.Event) bool {
+	if c.tenantID != nil && (e.TenantID == nil || *e.TenantID != *c.tenantID) {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.topics[e.Topic]
+	return ok
+}
+
+// close 断开客户端
+func (c *Client) close(reason string) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.reason = reason
+		c.mu.Unlock()
+		close(c.done)
+	})
+}
+
+// Hub 订阅客户端集合
+//
+// 每个客户端有固定长度的发送队列，队列满时断开该客户端，避免慢客户端拖慢事件发布者。
+type Hub struct {
+	bufferSize int
+
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+}
+
+// NewHub 创建订阅客户端集合
+func NewHub(bufferSize int) *Hub {
+	return &Hub{
+		bufferSize: max(bufferSize, 1),
+		clients:    make(map[*Client]struct{}),
+	}
+}
+
+// Register 注册客户端
+func (h *Hub) Register(tenantID *uuid.UUID) *Client {
+	client := &Client{
+		tenantID: tenantID,
+		topics:   make(map[string]struct{}),
+		send:     make(chan Delivery, h.bufferSize),
+		done:     make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+	return client
+}
+
+// Unregister 注销客户端
+func (h *Hub) Unregister(client *Client) {
+	h.disconnect(client, "")
+}
+
+// Broadcast 将事件分发给订阅了该主题的客户端
+func (h *Hub) Broadcast(e event.Event) {
+	var data []byte
+	var lagging []*Client
+
+	h.mu.RLock()
+	for client := range h.clients {
+		if !client.wants(&e) {
+			continue
+		}
+
+		// 只在有客户端需要时序列化一次
+		if data == nil {
+			var err error
+			data, err = sonic.Marshal(Message{Type: MessageEvent, Event: &e})
+			if err != nil {
+				h.mu.RUnlock()
+				log.Errorf("序列化推送事件 %s 失败: %v", e.Topic, err)
+				return
+			}
+		}
+
+		select {
+		case client.send <- Delivery{Topic: e.Topic, Data: data}:
+		default:
+			lagging = append(lagging, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range lagging {
+		h.disconnect(client, "客户端消费过慢，连接已断开")
+	}
+}
+
+// Close 断开全部客户端
+func (h *Hub) Close(reason string) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		h.disconnect(client, reason)
+	}
+}
+
+// disconnect 移除并断开客户端
+func (h *Hub) disconnect(client *Client, reason string) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+	client.close(reason)
+}
@@ -0,0 +1,108 @@
+package strike
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+)
+
+// Command 下发到打击模块的指令
+type Command struct {
+	ID          uuid.UUID `json:"id"`                     // 指令ID
+	Action      string    `json:"action"`                 // 打击方式
+	Duration    int       `json:"duration"`               // 持续时间(秒)
+	TargetID    string    `json:"target_id,omitempty"`    // 目标ID
+	DroneSerial string    `json:"drone_serial,omitempty"` // 无人机序列号
+	Latitude    *float64  `json:"latitude,omitempty"`     // 目标最后纬度
+	Longitude   *float64  `json:"longitude,omitempty"`    // 目标最后经度
+	Altitude    *float64  `json:"altitude,omitempty"`     // 目标最后高度(米)
+}
+
+// Ack 打击模块的确认结果
+type Ack struct {
+	Accepted bool   `json:"accepted"` // 是否接受指令
+	Message  string `json:"message"`  // 说明
+}
+
+// Driver 打击模块驱动，将指令发送到打击模块并等待确认
+type Driver interface {
+	Send(ctx context.Context, address string, command Command) (*Ack, error)
+}
+
+// DriverFunc 函数形式的驱动
+type DriverFunc func(ctx context.Context, address string, command Command) (*Ack, error)
+
+// Send 实现 Driver 接口
+func (f DriverFunc) Send(ctx context.Context, address string, command Command) (*Ack, error) {
+	return f(ctx, address, command)
+}
+
+var (
+	mu      sync.RWMutex
+	drivers = map[string]Driver{
+		"tcp": DriverFunc(sendTCP),
+	}
+)
+
+// Register 注册指定名称的驱动，已存在时覆盖
+func Register(name string, driver Driver) {
+	mu.Lock()
+	defer mu.Unlock()
+	drivers[name] = driver
+}
+
+// Get 获取指定名称的驱动
+func Get(name string) (Driver, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	driver, ok := drivers[name]
+	return driver, ok
+}
+
+// ErrEmptyAck 打击模块未返回确认
+var ErrEmptyAck = errors.New("打击模块未返回确认")
+
+// sendTCP 通过 TCP 发送一行 JSON 指令，并读取一行 JSON 确认
+func sendTCP(ctx context.Context, address string, command Command) (*Ack, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	data, err := sonic.Marshal(command)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyAck
+		}
+		return nil, err
+	}
+
+	var ack Ack
+	if err := sonic.Unmarshal(line, &ack); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
@@ -0,0 +1,109 @@
+package tenant
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantKey 上下文中租户ID的键
+type tenantKey struct{}
+
+// WithTenant 返回携带租户ID的上下文，使用该上下文的数据库操作会自动限定在该租户内
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext 获取上下文中的租户ID
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	tenantID, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+	return tenantID, ok
+}
+
+// fieldName 租户字段名，包含该字段的模型自动按租户隔离
+const fieldName = "TenantID"
+
+// Plugin GORM 租户隔离插件
+//
+// 上下文中携带租户ID时，对包含 TenantID 字段的模型：
+// 查询、更新、删除自动追加 tenant_id 条件，创建时自动填充 TenantID。
+type Plugin struct{}
+
+// Name 插件名称
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+// Initialize 注册回调
+func (Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", assignTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant)
+}
+
+// scopeTenant 追加租户条件
+func scopeTenant(db *gorm.DB) {
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField(fieldName)
+	if field == nil {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// assignTenant 创建记录时填充租户ID
+func assignTenant(db *gorm.DB) {
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField(fieldName)
+	if field == nil {
+		return
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setTenant(db, field, reflect.Indirect(rv.Index(i)), tenantID)
+		}
+	case reflect.Struct:
+		setTenant(db, field, rv, tenantID)
+	}
+}
+
+// setTenant 租户ID为空时设置为当前租户
+func setTenant(db *gorm.DB, field *schema.Field, rv reflect.Value, tenantID uuid.UUID) {
+	if _, isZero := field.ValueOf(db.Statement.Context, rv); !isZero {
+		return
+	}
+	if err := field.Set(db.Statement.Context, rv, &tenantID); err != nil {
+		db.AddError(err)
+	}
+}
@@ -0,0 +1,251 @@
+package token
+
+import (
+	"crypto/rand"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// 令牌类型
+const (
+	TypeAccess    = "access"     // 访问令牌
+	TypeRefresh   = "refresh"    // 刷新令牌
+	TypeTwoFactor = "two_factor" // 两步验证令牌，密码校验通过后用于完成第二步登录
+	TypeApiKey    = "api_key"    // API密钥，认证中间件按密钥所有者构造载荷，不签发令牌
+)
+
+// 令牌权限范围
+const (
+	ScopeFull           = ""                 // 不限制
+	ScopeTwoFactorSetup = "two_factor_setup" // 角色要求双因素认证但用户尚未启用，仅可访问个人中心
+)
+
+var (
+	ErrInvalidToken = errors.New("令牌无效")
+	ErrExpiredToken = errors.New("令牌已过期")
+	ErrRevokedToken = errors.New("令牌已被撤销")
+)
+
+// Claims JWT载荷
+type Claims struct {
+	UserID   uuid.UUID  `json:"user_id"`             // 用户ID
+	RoleID   *uuid.UUID `json:"role_id,omitempty"`   // 角色ID
+	TenantID *uuid.UUID `json:"tenant_id,omitempty"` // 租户ID，为空表示平台用户
+	Type     string     `json:"type"`                // 令牌类型
+	Scope    string     `json:"scope,omitempty"`     // 令牌权限范围，为空表示不限制
+	Session  string     `json:"sid,omitempty"`       // 会话ID，同一次登录签发和刷新的令牌属于同一会话
+	jwt.RegisteredClaims
+}
+
+// Pair 访问令牌与刷新令牌
+type Pair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// Manager 令牌管理器，负责签发、校验和撤销令牌
+type Manager struct {
+	secret       []byte
+	issuer       string
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+	twoFactorTTL time.Duration
+
+	mu            sync.Mutex
+	revokedIDs    map[string]time.Time    // 键: 令牌ID, 值: 令牌过期时间
+	revokedBefore map[uuid.UUID]time.Time // 键: 用户ID, 值: 在此时间之前签发的令牌全部失效
+}
+
+var (
+	manager *Manager
+	once    sync.Once
+)
+
+func init() {
+	// 签发时间精确到毫秒，便于按时间点撤销用户令牌
+	jwt.TimePrecision = time.Millisecond
+}
+
+// NewManager 创建令牌管理器实例（单例模式）
+func NewManager() *Manager {
+	once.Do(func() {
+		secret := []byte(os.Getenv("JWT_SECRET"))
+		if len(secret) == 0 {
+			// 未配置密钥时随机生成，重启后已签发的令牌全部失效
+			log.Warn("未配置 JWT_SECRET，使用随机密钥")
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				log.Fatalf("生成JWT密钥失败: %v", err)
+			}
+		}
+
+		manager = &Manager{
+			secret:        secret,
+			issuer:        "XACMS",
+			accessTTL:     durationFromEnv("JWT_ACCESS_TTL", 2*time.Hour),
+			refreshTTL:    durationFromEnv("JWT_REFRESH_TTL", 7*24*time.Hour),
+			twoFactorTTL:  durationFromEnv("JWT_TWO_FACTOR_TTL", 5*time.Minute),
+			revokedIDs:    make(map[string]time.Time),
+			revokedBefore: make(map[uuid.UUID]time.Time),
+		}
+	})
+	return manager
+}
+
+// durationFromEnv 从环境变量读取时长，未配置或格式错误时返回默认值
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Warnf("环境变量 %s 格式错误: %s，使用默认值 %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// Issue 为用户签发属于指定会话的一对访问令牌与刷新令牌，scope 限制令牌的权限范围
+func (m *Manager) Issue(userID uuid.UUID, roleID, tenantID *uuid.UUID, scope, session string) (*Pair, error) {
+	now := time.Now()
+
+	accessToken, accessExpiresAt, err := m.sign(userID, roleID, tenantID, TypeAccess, scope, session, now, m.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshExpiresAt, err := m.sign(userID, roleID, tenantID, TypeRefresh, scope, session, now, m.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// IssueTwoFactor 签发两步验证令牌，密码校验通过后凭此令牌和验证码完成登录
+func (m *Manager) IssueTwoFactor(userID uuid.UUID) (string, time.Time, error) {
+	return m.sign(userID, nil, nil, TypeTwoFactor, ScopeFull, "", time.Now(), m.twoFactorTTL)
+}
+
+// sign 签发单个令牌
+func (m *Manager) sign(userID uuid.UUID, roleID, tenantID *uuid.UUID, tokenType, scope, session string, now time.Time, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := now.Add(ttl)
+	claims := Claims{
+		UserID:   userID,
+		RoleID:   roleID,
+		TenantID: tenantID,
+		Type:     tokenType,
+		Scope:    scope,
+		Session:  session,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    m.issuer,
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// Parse 校验令牌签名、有效期、类型以及撤销状态
+func (m *Manager) Parse(tokenString string, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
+		return m.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	if m.isRevoked(claims) {
+		return nil, ErrRevokedToken
+	}
+
+	return claims, nil
+}
+
+// Revoke 撤销单个令牌，直到其自然过期
+func (m *Manager) Revoke(claims *Claims) {
+	if claims == nil || claims.ExpiresAt == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeLocked(time.Now())
+	m.revokedIDs[claims.ID] = claims.ExpiresAt.Time
+}
+
+// RevokeUser 撤销用户当前已签发的全部令牌
+func (m *Manager) RevokeUser(userID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 签发时间只保留到 jwt.TimePrecision，截断到相同精度，避免撤销后同一毫秒内签发的新令牌被误判失效
+	m.revokedBefore[userID] = time.Now().Truncate(jwt.TimePrecision)
+}
+
+// isRevoked 判断令牌是否已被撤销
+func (m *Manager) isRevoked(claims *Claims) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.revokedIDs[claims.ID]; ok {
+		return true
+	}
+
+	if before, ok := m.revokedBefore[claims.UserID]; ok {
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(before) {
+			return true
+		}
+	}
+	return false
+}
+
+// purgeLocked 清理已自然过期的撤销记录，调用方需持有锁
+func (m *Manager) purgeLocked(now time.Time) {
+	for id, expiresAt := range m.revokedIDs {
+		if now.After(expiresAt) {
+			delete(m.revokedIDs, id)
+		}
+	}
+
+	for userID, before := range m.revokedBefore {
+		if now.After(before.Add(m.refreshTTL)) {
+			delete(m.revokedBefore, userID)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 附录 B 中 SHA-1 测试使用的 ASCII 密钥 "12345678901234567890"
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// 附录 B 给出 8 位验证码，这里取其后 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+
+	for _, tt := range tests {
+		step := Step(time.Unix(tt.unix, 0))
+		got, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("时间 %d 计算验证码失败: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("时间 %d 期望 %s，实际 %s", tt.unix, tt.want, got)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	upper, err := Code(rfcSecret, 1)
+	if err != nil {
+		t.Fatalf("计算验证码失败: %v", err)
+	}
+	lower, err := Code(strings.ToLower(rfcSecret), 1)
+	if err != nil {
+		t.Fatalf("小写密钥计算验证码失败: %v", err)
+	}
+	if upper != lower {
+		t.Fatalf("大小写密钥结果不一致: %s != %s", upper, lower)
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	codeAt := func(offset int64) string {
+		t.Helper()
+		code, err := Code(rfcSecret, current+offset)
+		if err != nil {
+			t.Fatalf("计算验证码失败: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		skew   int
+		ok     bool
+	}{
+		{"当前时间步", 0, 0, true},
+		{"上一时间步不允许偏差", -1, 0, false},
+		{"上一时间步允许偏差", -1, 1, true},
+		{"下一时间步允许偏差", 1, 1, true},
+		{"超出偏差窗口之前", -2, 1, false},
+		{"超出偏差窗口之后", 2, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, codeAt(tt.offset), now, tt.skew)
+			if ok != tt.ok {
+				t.Fatalf("期望 %v，实际 %v", tt.ok, ok)
+			}
+			if ok && step != current+tt.offset {
+				t.Fatalf("期望匹配时间步 %d，实际 %d", current+tt.offset, step)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"验证码过短", rfcSecret, "28708"},
+		{"验证码过长", rfcSecret, "94287082"},
+		{"验证码错误", rfcSecret, "000000"},
+		{"密钥格式错误", "not base32!", "287082"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(tt.secret, tt.code, now, 1); ok {
+				t.Fatal("期望校验失败")
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// AlertHandler 告警处理器
+type AlertHandler struct {
+	AlertService  services.AlertService
+	CommonService services.CommonService
+}
+
+// RegisterRoutes 注册告警相关路由
+func (h *AlertHandler) RegisterRoutes(router fiber.Router) {
+	ruleGroup := router.Group("/alert-rules").Name("告警规则.")
+
+	ruleGroup.Get("", h.GetRules).Name("获取告警规则列表")
+	ruleGroup.Post("", h.CreateRule).Name("创建告警规则")
+	ruleGroup.Get("/:id<guid>", h.GetRule).Name("获取告警规则详情")
+	ruleGroup.Put("/:id<guid>", h.UpdateRule).Name("更新告警规则")
+	ruleGroup.Delete("/:id<guid>", h.DeleteRule).Name("删除告警规则")
+
+	alertGroup := router.Group("/alerts").Name("告警管理.")
+
+	alertGroup.Get("", h.GetAlerts).Name("获取告警列表")
+	alertGroup.Get("/:id<guid>", h.GetAlert).Name("获取告警详情")
+	alertGroup.Post("/:id<guid>/acknowledge", h.AcknowledgeAlert).Name("确认告警")
+	alertGroup.Post("/:id<guid>/close", h.CloseAlert).Name("关闭告警")
+}
+
+// GetRules 获取告警规则列表
+func (h *AlertHandler) GetRules(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取告警规则列表
+	rules, err := h.AlertService.GetRules(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取告警规则列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取告警规则列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(rules))
+}
+
+// CreateRule 创建告警规则
+func (h *AlertHandler) CreateRule(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.CreateAlertRuleRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建告警规则
+	rule, err := h.AlertService.CreateRule(c.UserContext(), req)
+	if err != nil {
+		if h.isRuleInvalid(err) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警规则已存在"))
+		}
+		log.Errorf("创建告警规则失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建告警规则失败"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(rule))
+}
+
+// GetRule 获取告警规则详情
+func (h *AlertHandler) GetRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	ruleUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警规则ID格式无效"))
+	}
+
+	// 获取告警规则
+	rule, err := h.AlertService.GetRule(c.UserContext(), ruleUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrAlertRuleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取告警规则失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取告警规则失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(rule))
+}
+
+// UpdateRule 更新告警规则
+func (h *AlertHandler) UpdateRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	ruleUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警规则ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.UpdateAlertRuleRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 更新告警规则
+	rule, err := h.AlertService.UpdateRule(c.UserContext(), ruleUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrAlertRuleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if h.isRuleInvalid(err) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警规则已存在"))
+		}
+		log.Errorf("更新告警规则失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新告警规则失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(rule))
+}
+
+// DeleteRule 删除告警规则
+func (h *AlertHandler) DeleteRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	ruleUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警规则ID格式无效"))
+	}
+
+	// 删除告警规则
+	if err := h.AlertService.DeleteRule(c.UserContext(), ruleUUID); err != nil {
+		if errors.Is(err, services.ErrAlertRuleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("删除告警规则失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除告警规则失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// GetAlerts 获取告警列表
+func (h *AlertHandler) GetAlerts(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.AlertQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取告警列表
+	alerts, err := h.AlertService.GetAlerts(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取告警列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取告警列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(alerts))
+}
+
+// GetAlert 获取告警详情
+func (h *AlertHandler) GetAlert(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	alertUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警ID格式无效"))
+	}
+
+	// 获取告警
+	alert, err := h.AlertService.GetAlert(c.UserContext(), alertUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrAlertNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取告警失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取告警失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(alert))
+}
+
+// AcknowledgeAlert 确认告警
+func (h *AlertHandler) AcknowledgeAlert(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	alertUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.AlertActionRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 确认告警
+	alert, err := h.AlertService.AcknowledgeAlert(c.UserContext(), alertUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrAlertNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrAlertNotOpen) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse(fiber.StatusConflict, err.Error()))
+		}
+		log.Errorf("确认告警失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "确认告警失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(alert))
+}
+
+// CloseAlert 关闭告警
+func (h *AlertHandler) CloseAlert(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	alertUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "告警ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.AlertActionRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 关闭告警
+	alert, err := h.AlertService.CloseAlert(c.UserContext(), alertUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrAlertNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrAlertClosed) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse(fiber.StatusConflict, err.Error()))
+		}
+		log.Errorf("关闭告警失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "关闭告警失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(alert))
+}
+
+// isRuleInvalid 判断是否为告警规则校验错误
+func (h *AlertHandler) isRuleInvalid(err error) bool {
+	return errors.Is(err, services.ErrAlertRuleThreshold) ||
+		errors.Is(err, services.ErrAlertRuleZoneNotFound) ||
+		errors.Is(err, services.ErrAlertRuleDeviceNotFound)
+}
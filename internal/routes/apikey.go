@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// ApiKeyHandler API密钥处理器
+type ApiKeyHandler struct {
+	ApiKeyService services.ApiKeyService
+	CommonService services.CommonService
+}
+
+// RegisterRoutes 注册API密钥相关路由
+func (h *ApiKeyHandler) RegisterRoutes(router fiber.Router) {
+	apiKeyGroup := router.Group("/api-keys").Name("API密钥管理.")
+
+	apiKeyGroup.Get("", h.GetApiKeys).Name("获取API密钥列表")
+	apiKeyGroup.Post("", h.CreateApiKey).Name("创建API密钥")
+	apiKeyGroup.Get("/:id<guid>", h.GetApiKey).Name("获取API密钥详情")
+	apiKeyGroup.Put("/:id<guid>", h.UpdateApiKey).Name("更新API密钥")
+	apiKeyGroup.Delete("/:id<guid>", h.DeleteApiKey).Name("删除API密钥")
+}
+
+// GetApiKeys 获取API密钥列表
+func (h *ApiKeyHandler) GetApiKeys(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取API密钥列表
+	keys, err := h.ApiKeyService.GetApiKeys(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取API密钥列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取API密钥列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(keys))
+}
+
+// CreateApiKey 创建API密钥，完整密钥只在响应中返回一次
+func (h *ApiKeyHandler) CreateApiKey(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.CreateApiKeyRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建API密钥
+	key, err := h.ApiKeyService.CreateApiKey(c.UserContext(), req)
+	if err != nil {
+		return apiKeyFailed(c, err, "创建API密钥失败")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(key))
+}
+
+// GetApiKey 获取API密钥详情
+func (h *ApiKeyHandler) GetApiKey(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	keyUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "API密钥ID格式无效"))
+	}
+
+	// 获取API密钥
+	key, err := h.ApiKeyService.GetApiKey(c.UserContext(), keyUUID)
+	if err != nil {
+		return apiKeyFailed(c, err, "获取API密钥失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(key))
+}
+
+// UpdateApiKey 更新API密钥
+func (h *ApiKeyHandler) UpdateApiKey(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	keyUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "API密钥ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.UpdateApiKeyRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 更新API密钥
+	key, err := h.ApiKeyService.UpdateApiKey(c.UserContext(), keyUUID, req)
+	if err != nil {
+		return apiKeyFailed(c, err, "更新API密钥失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(key))
+}
+
+// DeleteApiKey 删除API密钥
+func (h *ApiKeyHandler) DeleteApiKey(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	keyUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "API密钥ID格式无效"))
+	}
+
+	// 删除API密钥
+	if err := h.ApiKeyService.DeleteApiKey(c.UserContext(), keyUUID); err != nil {
+		return apiKeyFailed(c, err, "删除API密钥失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// apiKeyFailed 返回API密钥操作失败的响应
+func apiKeyFailed(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, services.ErrApiKeyNotFound) || errors.Is(err, services.ErrUserNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+	}
+	if errors.Is(err, services.ErrApiKeyScopeDenied) || errors.Is(err, services.ErrApiKeyTwoFactor) {
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse(fiber.StatusForbidden, err.Error()))
+	}
+	if errors.Is(err, services.ErrApiKeyScopeInvalid) || errors.Is(err, services.ErrApiKeyDeviceNotFound) || errors.Is(err, services.ErrApiKeyExpiresAt) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+	log.Errorf("%s: %v", message, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, message))
+}
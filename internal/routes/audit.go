@@ -0,0 +1,71 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// AuditHandler 审计日志处理器
+type AuditHandler struct {
+	AuditService  services.AuditService
+	CommonService services.CommonService
+}
+
+// RegisterRoutes 注册审计日志相关路由
+func (h *AuditHandler) RegisterRoutes(router fiber.Router) {
+	auditGroup := router.Group("/audit-logs").Name("审计日志.")
+
+	auditGroup.Get("", h.GetAuditLogs).Name("获取审计日志列表")
+	auditGroup.Get("/export", h.ExportAuditLogs).Name("导出审计日志")
+}
+
+// GetAuditLogs 获取审计日志列表
+func (h *AuditHandler) GetAuditLogs(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.AuditLogQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取审计日志列表
+	logs, err := h.AuditService.GetAuditLogs(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取审计日志列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取审计日志列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(logs))
+}
+
+// ExportAuditLogs 按查询条件导出审计日志为 CSV 文件
+func (h *AuditHandler) ExportAuditLogs(c *fiber.Ctx) error {
+	// 解析查询参数，分页参数不生效
+	var req dto.AuditLogQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 导出完成后再写入响应，导出失败时可以返回错误信息
+	var buf bytes.Buffer
+	if err := h.AuditService.ExportAuditLogs(c.UserContext(), req, &buf); err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("导出审计日志失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "导出审计日志失败"))
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Attachment(fmt.Sprintf("audit-logs-%s.csv", time.Now().Format("20060102150405")))
+	return c.Send(buf.Bytes())
+}
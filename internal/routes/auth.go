@@ -0,0 +1,141 @@
+package routes
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"xacms/internal/pkg/token"
+	"xacms/internal/routes/dto"
+	"xacms/internal/server/middlewares"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// AuthHandler 认证处理器
+type AuthHandler struct {
+	AuthService   services.AuthService
+	CommonService services.CommonService
+}
+
+// RegisterPublicRoutes 注册无需认证的路由
+func (h *AuthHandler) RegisterPublicRoutes(router fiber.Router) {
+	authGroup := router.Group("/auth").Name("认证管理.")
+
+	authGroup.Post("/login", h.Login).Name("登录")
+	authGroup.Post("/login/2fa", h.LoginTwoFactor).Name("两步验证登录")
+	authGroup.Post("/refresh", h.Refresh).Name("刷新令牌")
+}
+
+// RegisterRoutes 注册需要认证的路由
+func (h *AuthHandler) RegisterRoutes(router fiber.Router) {
+	authGroup := router.Group("/auth").Name("认证管理.")
+
+	authGroup.Post("/logout", h.Logout).Name("退出登录")
+}
+
+// Login 登录
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.LoginRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 登录
+	tokens, err := h.AuthService.Login(req, loginClient(c))
+	if err != nil {
+		return loginFailed(c, err)
+	}
+
+	return c.JSON(dto.SuccessResponse(tokens))
+}
+
+// LoginTwoFactor 两步验证登录
+func (h *AuthHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.TwoFactorLoginRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 校验验证码并登录
+	tokens, err := h.AuthService.LoginTwoFactor(req, loginClient(c))
+	if err != nil {
+		return loginFailed(c, err)
+	}
+
+	return c.JSON(dto.SuccessResponse(tokens))
+}
+
+// loginClient 获取登录客户端信息
+func loginClient(c *fiber.Ctx) services.LoginClient {
+	return services.LoginClient{IP: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
+}
+
+// loginFailed 返回登录失败的响应
+func loginFailed(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrInvalidCredentials) || errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, err.Error()))
+	}
+	if errors.Is(err, token.ErrInvalidToken) || errors.Is(err, token.ErrExpiredToken) || errors.Is(err, token.ErrRevokedToken) {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, err.Error()))
+	}
+	var lockedErr *services.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(lockedErr.RetryAfter.Seconds())), 10))
+		return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse(fiber.StatusTooManyRequests, err.Error()))
+	}
+	if errors.Is(err, services.ErrUserDisabled) || errors.Is(err, services.ErrTenantDisabled) {
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse(fiber.StatusForbidden, err.Error()))
+	}
+	log.Errorf("登录失败: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "登录失败"))
+}
+
+// Refresh 刷新令牌
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.RefreshTokenRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 刷新令牌
+	tokens, err := h.AuthService.Refresh(req, loginClient(c))
+	if err != nil {
+		if errors.Is(err, token.ErrInvalidToken) || errors.Is(err, token.ErrExpiredToken) || errors.Is(err, token.ErrRevokedToken) {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, err.Error()))
+		}
+		if errors.Is(err, services.ErrUserDisabled) || errors.Is(err, services.ErrTenantDisabled) {
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse(fiber.StatusForbidden, err.Error()))
+		}
+		log.Errorf("刷新令牌失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "刷新令牌失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(tokens))
+}
+
+// Logout 退出登录
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	// 解析请求体，请求体可以为空
+	var req dto.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := h.CommonService.ValidateBody(c, &req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+	}
+
+	// 退出登录
+	if err := h.AuthService.Logout(middlewares.GetClaims(c), req); err != nil {
+		if errors.Is(err, token.ErrInvalidToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "刷新令牌与当前用户不匹配"))
+		}
+		log.Errorf("退出登录失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "退出登录失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DepartmentHandler 部门处理器
+type DepartmentHandler struct {
+	DepartmentService services.DepartmentService
+	CommonService     services.CommonService
+}
+
+// RegisterRoutes 注册部门相关路由
+func (h *DepartmentHandler) RegisterRoutes(router fiber.Router) {
+	departmentGroup := router.Group("/departments").Name("部门管理.")
+
+	departmentGroup.Get("", h.GetDepartments).Name("获取部门列表")
+	departmentGroup.Get("/tree", h.GetDepartmentTree).Name("获取部门树")
+	departmentGroup.Post("", h.CreateDepartment).Name("创建部门")
+	departmentGroup.Get("/:id<guid>", h.GetDepartment).Name("获取部门详情")
+	departmentGroup.Put("/:id<guid>", h.UpdateDepartment).Name("更新部门")
+	departmentGroup.Delete("/:id<guid>", h.DeleteDepartment).Name("删除部门")
+}
+
+// GetDepartments 获取部门列表
+func (h *DepartmentHandler) GetDepartments(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取部门列表
+	departments, err := h.DepartmentService.GetDepartments(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取部门列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取部门列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(departments))
+}
+
+// GetDepartmentTree 获取部门树
+func (h *DepartmentHandler) GetDepartmentTree(c *fiber.Ctx) error {
+	tree, err := h.DepartmentService.GetDepartmentTree(c.UserContext())
+	if err != nil {
+		log.Errorf("获取部门树失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取部门树失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(tree))
+}
+
+// CreateDepartment 创建部门
+func (h *DepartmentHandler) CreateDepartment(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.CreateDepartmentRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建部门
+	department, err := h.DepartmentService.CreateDepartment(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrParentDepartmentNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("创建部门失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建部门失败"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(department))
+}
+
+// GetDepartment 获取部门详情
+func (h *DepartmentHandler) GetDepartment(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	departmentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "部门ID格式无效"))
+	}
+
+	// 获取部门
+	var department models.DepartmentModel
+	if err := h.CommonService.GetItemByID(c.UserContext(), departmentUUID, &department); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, "部门不存在"))
+		}
+		log.Errorf("获取部门失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取部门失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(department))
+}
+
+// UpdateDepartment 更新部门
+func (h *DepartmentHandler) UpdateDepartment(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	departmentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "部门ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.UpdateDepartmentRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 更新部门
+	department, err := h.DepartmentService.UpdateDepartment(c.UserContext(), departmentUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrDepartmentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrParentDepartmentNotFound) || errors.Is(err, services.ErrDepartmentCycle) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("更新部门失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新部门失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(department))
+}
+
+// DeleteDepartment 删除部门
+func (h *DepartmentHandler) DeleteDepartment(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	departmentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "部门ID格式无效"))
+	}
+
+	// 删除部门
+	if err := h.DepartmentService.DeleteDepartment(c.UserContext(), departmentUUID); err != nil {
+		if errors.Is(err, services.ErrDepartmentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrDepartmentHasChildren) || errors.Is(err, services.ErrDepartmentHasUsers) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse(fiber.StatusConflict, err.Error()))
+		}
+		log.Errorf("删除部门失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除部门失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/routes/dto"
+	"xacms/internal/server/middlewares"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// DetectionHandler 侦测事件处理器
+type DetectionHandler struct {
+	DetectionService services.DetectionService
+	DeviceService    services.DeviceService
+	ApiKeyService    services.ApiKeyService
+	CommonService    services.CommonService
+}
+
+// RegisterPublicRoutes 注册设备上报路由，使用设备接入密钥或绑定设备的API密钥认证
+func (h *DetectionHandler) RegisterPublicRoutes(router fiber.Router) {
+	ingestGroup := router.Group("/ingest").Name("设备接入.")
+
+	// 认证中间件挂在具体路由上，以便按路由名称校验API密钥的授权范围
+	deviceAuth := middlewares.DeviceAuthMiddleware(h.DeviceService, h.ApiKeyService)
+
+	ingestGroup.Post("/detections", deviceAuth, h.IngestDetections).Name("上报侦测事件")
+}
+
+// RegisterRoutes 注册侦测事件相关路由
+func (h *DetectionHandler) RegisterRoutes(router fiber.Router) {
+	detectionGroup := router.Group("/detections").Name("侦测事件.")
+
+	detectionGroup.Get("", h.GetDetections).Name("获取侦测事件列表")
+}
+
+// IngestDetections 批量上报侦测事件
+func (h *DetectionHandler) IngestDetections(c *fiber.Ctx) error {
+	device := middlewares.GetDevice(c)
+	if device == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "设备未认证"))
+	}
+
+	// 解析请求体
+	var req dto.IngestDetectionsRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 写入侦测事件
+	resp, err := h.DetectionService.Ingest(c.UserContext(), device, req)
+	if err != nil {
+		log.Errorf("写入侦测事件失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "写入侦测事件失败"))
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.SuccessResponse(resp))
+}
+
+// GetDetections 获取侦测事件列表
+func (h *DetectionHandler) GetDetections(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.DetectionQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取侦测事件列表
+	detections, err := h.DetectionService.GetDetections(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取侦测事件列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取侦测事件列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(detections))
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"xacms/internal/models"
 	"xacms/internal/routes/dto"
 	"xacms/internal/services"
@@ -14,8 +15,18 @@ import (
 
 // DeviceHandler 设备处理器
 type DeviceHandler struct {
-	DeviceService services.DeviceService
-	CommonService services.CommonService
+	DeviceService        services.DeviceService
+	DeviceMonitorService services.DeviceMonitorService
+	DeviceDriverService  services.DeviceDriverService
+	StreamService        services.StreamService
+	CommonService        services.CommonService
+}
+
+// RegisterPublicRoutes 注册流媒体服务器鉴权回调路由
+func (h *DeviceHandler) RegisterPublicRoutes(router fiber.Router) {
+	streamGroup := router.Group("/streams").Name("流媒体.")
+
+	streamGroup.Post("/auth", h.AuthorizeStream).Name("流媒体鉴权")
 }
 
 // RegisterRoutes 注册设备相关路由
@@ -24,16 +35,31 @@ func (h *DeviceHandler) RegisterRoutes(router fiber.Router) {
 
 	deviceGroup.Get("", h.GetDevices).Name("获取设备列表")
 	deviceGroup.Post("", h.CreateDevice).Name("创建设备")
+	deviceGroup.Get("/drivers", h.GetDrivers).Name("获取设备驱动列表")
 	deviceGroup.Get("/:id<guid>", h.GetDevice).Name("获取设备详情")
+	deviceGroup.Get("/:id<guid>/status", h.GetDeviceStatus).Name("获取设备状态")
+	deviceGroup.Post("/:id<guid>/ingest-key", h.GenerateIngestKey).Name("生成接入密钥")
+	deviceGroup.Get("/:id<guid>/stream", h.GetDeviceStream).Name("获取视频流地址")
+	deviceGroup.Get("/:id<guid>/driver", h.GetDriverStatus).Name("获取设备驱动状态")
+	deviceGroup.Put("/:id<guid>/driver/config", h.ConfigureDriver).Name("配置设备驱动")
 	deviceGroup.Put("/:id<guid>", h.UpdateDevice).Name("更新设备")
 	deviceGroup.Delete("/:id<guid>", h.DeleteDevice).Name("删除设备")
 }
 
 // GetDevices 获取设备列表
 func (h *DeviceHandler) GetDevices(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
 	// 获取设备列表
-	var devices []models.DeviceModel
-	if err := h.CommonService.GetItems(&devices); err != nil {
+	devices, err := h.DeviceService.GetDevices(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
 		log.Errorf("获取设备列表失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取设备列表失败"))
 	}
@@ -50,8 +76,11 @@ func (h *DeviceHandler) CreateDevice(c *fiber.Ctx) error {
 	}
 
 	// 创建设备
-	device, err := h.DeviceService.CreateDevice(req)
+	device, err := h.DeviceService.CreateDevice(c.UserContext(), req)
 	if err != nil {
+		if errors.Is(err, services.ErrUnknownDriver) || errors.Is(err, services.ErrStreamPathExists) || errors.Is(err, services.ErrStreamPathReserved) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
 		log.Errorf("创建设备失败: %v", err)
 
 		if sqliteErr, ok := err.(sqlite3.Error); ok {
@@ -63,6 +92,9 @@ func (h *DeviceHandler) CreateDevice(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建设备失败"))
 	}
 
+	// 立即连接设备驱动
+	h.DeviceDriverService.Refresh()
+
 	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(device))
 }
 
@@ -78,7 +110,7 @@ func (h *DeviceHandler) GetDevice(c *fiber.Ctx) error {
 
 	// 获取设备
 	var device models.DeviceModel
-	if err := h.CommonService.GetItemByID(deviceUUID, &device); err != nil {
+	if err := h.CommonService.GetItemByID(c.UserContext(), deviceUUID, &device); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, "设备不存在"))
 		}
@@ -106,8 +138,14 @@ func (h *DeviceHandler) UpdateDevice(c *fiber.Ctx) error {
 	}
 
 	// 更新设备
-	device, err := h.DeviceService.UpdateDevice(deviceUUID, req)
+	device, err := h.DeviceService.UpdateDevice(c.UserContext(), deviceUUID, req)
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDeviceNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		case errors.Is(err, services.ErrUnknownDriver), errors.Is(err, services.ErrStreamPathExists), errors.Is(err, services.ErrStreamPathReserved):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
 		log.Errorf("更新设备失败: %v", err)
 		if sqliteErr, ok := err.(sqlite3.Error); ok {
 			if sqliteErr.Code == sqlite3.ErrConstraint {
@@ -119,6 +157,9 @@ func (h *DeviceHandler) UpdateDevice(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新设备失败"))
 	}
 
+	// 设备驱动或连接参数可能变化
+	h.DeviceDriverService.Refresh()
+
 	return c.JSON(dto.SuccessResponse(device))
 }
 
@@ -133,10 +174,179 @@ func (h *DeviceHandler) DeleteDevice(c *fiber.Ctx) error {
 	}
 
 	// 删除设备
-	if err := h.CommonService.DeleteItemByID(&models.DeviceModel{}, deviceUUID); err != nil {
+	if err := h.CommonService.DeleteItemByID(c.UserContext(), &models.DeviceModel{}, deviceUUID); err != nil {
 		log.Errorf("删除设备失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除设备失败"))
 	}
 
+	// 断开设备驱动
+	h.DeviceDriverService.Refresh()
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// GetDeviceStatus 获取设备各模块在线状态
+func (h *DeviceHandler) GetDeviceStatus(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	deviceUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "设备ID格式无效"))
+	}
+
+	// 获取设备状态
+	status, err := h.DeviceMonitorService.GetDeviceStatus(c.UserContext(), deviceUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrDeviceNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取设备状态失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取设备状态失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(status))
+}
+
+// GenerateIngestKey 生成设备数据接入密钥，旧密钥随即失效
+func (h *DeviceHandler) GenerateIngestKey(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	deviceUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "设备ID格式无效"))
+	}
+
+	// 生成密钥
+	key, err := h.DeviceService.GenerateIngestKey(c.UserContext(), deviceUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrDeviceNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("生成接入密钥失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "生成接入密钥失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(key))
+}
+
+// GetDrivers 获取可用的设备驱动
+func (h *DeviceHandler) GetDrivers(c *fiber.Ctx) error {
+	return c.JSON(dto.SuccessResponse(h.DeviceDriverService.Drivers()))
+}
+
+// GetDriverStatus 获取设备驱动连接状态
+func (h *DeviceHandler) GetDriverStatus(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	deviceUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "设备ID格式无效"))
+	}
+
+	// 获取驱动状态
+	status, err := h.DeviceDriverService.GetDriverStatus(c.UserContext(), deviceUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrDeviceNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取设备驱动状态失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取设备驱动状态失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(status))
+}
+
+// ConfigureDriver 配置设备驱动，驱动已连接时立即生效
+func (h *DeviceHandler) ConfigureDriver(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	deviceUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "设备ID格式无效"))
+	}
+
+	// 解析请求体
+	var config map[string]any
+	if err := c.BodyParser(&config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "请求体格式错误"))
+	}
+
+	// 下发配置
+	if err := h.DeviceDriverService.Configure(c.UserContext(), deviceUUID, config); err != nil {
+		switch {
+		case errors.Is(err, services.ErrDeviceNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		case errors.Is(err, services.ErrDeviceNoDriver), errors.Is(err, services.ErrInvalidDriverConfig):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("配置设备驱动失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "配置设备驱动失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// GetDeviceStream 获取设备视频流的签名播放地址
+func (h *DeviceHandler) GetDeviceStream(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	deviceUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "设备ID格式无效"))
+	}
+
+	// 生成播放地址
+	stream, err := h.StreamService.GetStream(c.UserContext(), deviceUUID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDeviceNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		case errors.Is(err, services.ErrStreamNotConfigured):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取视频流地址失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取视频流地址失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(stream))
+}
+
+// AuthorizeStream 流媒体服务器播放/推流鉴权回调，返回 200 表示允许
+//
+// 回调令牌通过 X-Stream-Auth-Token 头传递；MediaMTX 等不支持自定义请求头的流媒体服务器
+// 可在回调地址中使用 token 查询参数。
+func (h *DeviceHandler) AuthorizeStream(c *fiber.Ctx) error {
+	// 校验回调令牌
+	token := c.Get("X-Stream-Auth-Token")
+	if token == "" {
+		token = c.Query("token")
+	}
+	if !h.StreamService.VerifyCallback(token) {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "流媒体回调令牌无效"))
+	}
+
+	// 解析请求体
+	var req dto.StreamAuthRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 鉴权
+	if err := h.StreamService.Authorize(req); err != nil {
+		if errors.Is(err, services.ErrStreamUnauthorized) {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, err.Error()))
+		}
+		if errors.Is(err, services.ErrStreamLocked) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse(fiber.StatusTooManyRequests, err.Error()))
+		}
+		log.Errorf("流媒体鉴权失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "流媒体鉴权失败"))
+	}
+
 	return c.JSON(dto.SuccessResponse(nil))
 }
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"xacms/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CreateAlertRuleRequest 创建告警规则请求结构
+type CreateAlertRuleRequest struct {
+	Name      string                `json:"name" validate:"required,min=2,max=64"`
+	Condition models.AlertCondition `json:"condition" validate:"required,oneof=zone_enter zone_dwell speed_above altitude_above altitude_below unknown_serial device_offline"`
+	Severity  models.AlertSeverity  `json:"severity" validate:"required,oneof=info warning critical"`
+	Enabled   *bool                 `json:"enabled" validate:"omitempty"` // 是否启用，默认启用
+	Remark    string                `json:"remark" validate:"omitempty,max=255"`
+	ZoneID    *uuid.UUID            `json:"zone_id" validate:"omitempty"`   // 区域条件限定的区域，为空表示任意区域
+	DeviceID  *uuid.UUID            `json:"device_id" validate:"omitempty"` // 限定的设备，为空表示全部设备
+	Threshold float64               `json:"threshold" validate:"gte=0"`     // 阈值：停留时长(秒)、速度(米/秒)或高度(米)
+}
+
+// UpdateAlertRuleRequest 更新告警规则请求结构，区域和设备为全零UUID时表示不限定
+type UpdateAlertRuleRequest struct {
+	Name      *string                `json:"name" validate:"omitempty,min=2,max=64"`
+	Condition *models.AlertCondition `json:"condition" validate:"omitempty,oneof=zone_enter zone_dwell speed_above altitude_above altitude_below unknown_serial device_offline"`
+	Severity  *models.AlertSeverity  `json:"severity" validate:"omitempty,oneof=info warning critical"`
+	Enabled   *bool                  `json:"enabled" validate:"omitempty"`
+	Remark    *string                `json:"remark" validate:"omitempty,max=255"`
+	ZoneID    *uuid.UUID             `json:"zone_id" validate:"omitempty"`
+	DeviceID  *uuid.UUID             `json:"device_id" validate:"omitempty"`
+	Threshold *float64               `json:"threshold" validate:"omitempty,gte=0"`
+}
+
+// AlertQueryRequest 告警查询请求结构
+type AlertQueryRequest struct {
+	ListQueryRequest
+	StartTime string `query:"start_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // 开始时间，RFC3339，按首次触发时间过滤
+	EndTime   string `query:"end_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`   // 结束时间，RFC3339
+}
+
+// AlertActionRequest 确认或关闭告警请求结构
+type AlertActionRequest struct {
+	Note string `json:"note" validate:"omitempty,max=255"` // 处理说明
+}
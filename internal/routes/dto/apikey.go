@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+	"xacms/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CreateApiKeyRequest 创建API密钥请求结构
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=64"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required,max=128"` // 授权访问的路由名称，取自 API 列表
+	DeviceID  *uuid.UUID `json:"device_id" validate:"omitempty,uuid"`                    // 绑定设备
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`                        // 过期时间，为空表示长期有效
+	Remark    string     `json:"remark" validate:"omitempty,max=255"`
+}
+
+// UpdateApiKeyRequest 更新API密钥请求结构，过期时间为零值时间时表示长期有效，绑定设备为空UUID时表示解除绑定
+type UpdateApiKeyRequest struct {
+	Name      *string    `json:"name" validate:"omitempty,min=2,max=64"`
+	Scopes    *[]string  `json:"scopes" validate:"omitempty,min=1,dive,required,max=128"`
+	DeviceID  *uuid.UUID `json:"device_id" validate:"omitempty"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+	Remark    *string    `json:"remark" validate:"omitempty,max=255"`
+}
+
+// CreateApiKeyResponse 创建API密钥响应结构，完整密钥仅在创建时返回一次
+type CreateApiKeyResponse struct {
+	*models.ApiKeyModel
+	Key string `json:"key"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// AuditLogQueryRequest 审计日志查询请求结构
+type AuditLogQueryRequest struct {
+	ListQueryRequest
+	StartTime string `query:"start_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // 开始时间，RFC3339，按记录时间过滤
+	EndTime   string `query:"end_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`   // 结束时间，RFC3339
+}
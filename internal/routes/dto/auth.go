@@ -0,0 +1,37 @@
+package dto
+
+// LoginRequest 登录请求结构
+type LoginRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=64"`
+	Password string `json:"password" validate:"required,min=6,max=128"`
+}
+
+// TwoFactorLoginRequest 两步验证登录请求结构
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"` // 身份验证器应用中的验证码或恢复码
+}
+
+// RefreshTokenRequest 刷新令牌请求结构
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest 退出登录请求结构
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"omitempty"`
+}
+
+// TokenResponse 令牌响应结构
+//
+// 用户启用双因素认证时，密码校验通过后只返回 two_factor_token，凭此令牌和验证码调用两步验证登录。
+type TokenResponse struct {
+	AccessToken            string `json:"access_token,omitempty"`              // 访问令牌
+	RefreshToken           string `json:"refresh_token,omitempty"`             // 刷新令牌
+	TokenType              string `json:"token_type,omitempty"`                // 令牌类型
+	ExpiresIn              int64  `json:"expires_in"`                          // 访问令牌或两步验证令牌有效期（秒）
+	RefreshExpiresIn       int64  `json:"refresh_expires_in,omitempty"`        // 刷新令牌有效期（秒）
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`       // 是否需要两步验证
+	TwoFactorToken         string `json:"two_factor_token,omitempty"`          // 两步验证令牌
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"` // 角色要求启用双因素认证，启用前只能访问个人中心
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"xacms/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CreateButtonRequest 创建按钮请求结构
+type CreateButtonRequest struct {
+	Code     string           `json:"code" validate:"required,min=2,max=64"`
+	Name     string           `json:"name" validate:"required,min=2,max=64"`
+	ApiNames *models.ApiNames `json:"api_names" validate:"omitempty"`
+	Order    uint             `json:"order" validate:"omitempty,min=0"`
+}
+
+// UpdateButtonRequest 更新按钮请求结构
+type UpdateButtonRequest struct {
+	Code     *string          `json:"code" validate:"omitempty,min=2,max=64"`
+	Name     *string          `json:"name" validate:"omitempty,min=2,max=64"`
+	ApiNames *models.ApiNames `json:"api_names" validate:"omitempty"`
+	Order    *uint            `json:"order" validate:"omitempty,min=0"`
+}
+
+// AssignButtonsRequest 分配按钮请求结构
+type AssignButtonsRequest struct {
+	ButtonIDs []uuid.UUID `json:"button_ids" validate:"omitempty,dive,uuid"`
+}
+
+// UserPermissionsResponse 当前用户权限响应结构
+type UserPermissionsResponse struct {
+	IsSuper     bool               `json:"is_super"`     // 是否超级管理员
+	Menus       []models.MenuModel `json:"menus"`        // 可访问的菜单
+	ButtonCodes []string           `json:"button_codes"` // 可使用的按钮编码
+}
@@ -2,9 +2,29 @@ package dto
 
 // BaseQueryRequest 基础查询请求结构
 type BaseQueryRequest struct {
-	Page     int `query:"page,string" validate:"min=1"`
-	PageSize int `query:"page_size,string" validate:"min=1,max=100"`
-	// Keyword  string `query:"keyword" validate:"omitempty,max=100"`
+	Page     int    `query:"page,string" validate:"omitempty,min=1"`              // 页码，默认 1
+	PageSize int    `query:"page_size,string" validate:"omitempty,min=1,max=100"` // 每页数量，默认 20
+	Keyword  string `query:"keyword" validate:"omitempty,max=100"`
+	Sort     string `query:"sort" validate:"omitempty,max=255"` // 排序字段，逗号分隔，字段前加 - 表示降序，如 -created_at,username
+}
+
+// ListQueryRequest 通用列表查询请求结构
+//
+// 除结构体中声明的查询参数外，其余查询参数均视为字段过滤条件，格式为
+// field=value 或 field__op=value，op 可选 eq、ne、gt、gte、lt、lte、like、in、null。
+type ListQueryRequest struct {
+	BaseQueryRequest
+	Filters map[string]string `query:"-"` // 字段过滤条件，键: 查询参数名, 值: 查询参数值
+}
+
+// ListQuery 通用列表查询接口，嵌入 ListQueryRequest 的结构体自动实现该接口
+type ListQuery interface {
+	ListQuery() *ListQueryRequest
+}
+
+// ListQuery 返回通用列表查询请求
+func (r *ListQueryRequest) ListQuery() *ListQueryRequest {
+	return r
 }
 
 // // IDRequest 通用ID请求结构
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"xacms/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CreateDepartmentRequest 创建部门请求结构
+type CreateDepartmentRequest struct {
+	ParentID    *uuid.UUID `json:"parent_id" validate:"omitempty,uuid"`
+	Name        string     `json:"name" validate:"required,min=2,max=64"`
+	Description string     `json:"description" validate:"omitempty,max=255"`
+	Order       uint       `json:"order" validate:"omitempty,min=0"`
+}
+
+// UpdateDepartmentRequest 更新部门请求结构
+type UpdateDepartmentRequest struct {
+	ParentID    *uuid.UUID `json:"parent_id" validate:"omitempty,uuid"`
+	Name        *string    `json:"name" validate:"omitempty,min=2,max=64"`
+	Description *string    `json:"description" validate:"omitempty,max=255"`
+	Order       *uint      `json:"order" validate:"omitempty,min=0"`
+}
+
+type DepartmentTreeItem struct {
+	models.DepartmentModel
+	Children []DepartmentTreeItem `json:"children"`
+}
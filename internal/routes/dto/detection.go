@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DetectionEventRequest 侦测事件上报结构
+type DetectionEventRequest struct {
+	Timestamp   time.Time `json:"timestamp" validate:"required"`
+	TargetID    string    `json:"target_id" validate:"omitempty,max=64"`
+	Frequency   *float64  `json:"frequency" validate:"omitempty,gt=0"` // 频率(MHz)
+	Protocol    string    `json:"protocol" validate:"omitempty,max=32"`
+	Latitude    *float64  `json:"latitude" validate:"omitempty,latitude"`
+	Longitude   *float64  `json:"longitude" validate:"omitempty,longitude"`
+	Altitude    *float64  `json:"altitude" validate:"omitempty"`             // 高度(米)
+	Heading     *float64  `json:"heading" validate:"omitempty,gte=0,lt=360"` // 航向(度)
+	Speed       *float64  `json:"speed" validate:"omitempty,gte=0"`          // 速度(米/秒)
+	RSSI        *float64  `json:"rssi" validate:"omitempty"`                 // 信号强度(dBm)
+	DroneModel  string    `json:"drone_model" validate:"omitempty,max=64"`
+	DroneSerial string    `json:"drone_serial" validate:"omitempty,max=64"`
+}
+
+// IngestDetectionsRequest 批量上报侦测事件请求结构
+type IngestDetectionsRequest struct {
+	Events []DetectionEventRequest `json:"events" validate:"required,min=1,max=1000,dive"`
+}
+
+// IngestDetectionsResponse 批量上报侦测事件响应结构
+type IngestDetectionsResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+// DetectionQueryRequest 侦测事件查询请求结构
+type DetectionQueryRequest struct {
+	ListQueryRequest
+	DeviceID  *uuid.UUID `query:"device_id" validate:"omitempty,uuid"`
+	ZoneID    *uuid.UUID `query:"zone_id" validate:"omitempty,uuid"`                                  // 所在区域
+	StartTime string     `query:"start_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // 开始时间，RFC3339
+	EndTime   string     `query:"end_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`   // 结束时间，RFC3339
+	BBox      string     `query:"bbox" validate:"omitempty,max=128"`                                  // 经纬度范围：最小经度,最小纬度,最大经度,最大纬度
+}
+
+// DeviceIngestKeyResponse 设备数据接入密钥响应结构，密钥仅在生成时返回一次
+type DeviceIngestKeyResponse struct {
+	DeviceID  uuid.UUID `json:"device_id"`
+	IngestKey string    `json:"ingest_key"`
+}
@@ -1,5 +1,12 @@
 package dto
 
+import (
+	"time"
+	"xacms/internal/models"
+
+	"github.com/google/uuid"
+)
+
 // CreateDeviceRequest 创建设备请求结构
 type CreateDeviceRequest struct {
 	Name      string  `json:"name" validate:"required,min=2,max=64"`
@@ -16,12 +23,20 @@ type CreateDeviceRequest struct {
 	AnalysisIP string `json:"analysis_ip" validate:"required"` // 解析模块IP
 
 	// FPV模块
-	FPVIP          string `json:"fpv_ip" validate:"required"`           // FPV模块IP
-	StreamServerIP string `json:"stream_server_ip" validate:"required"` // 流媒体服务器IP
+	FPVIP          string `json:"fpv_ip" validate:"required"`                               // FPV模块IP
+	StreamServerIP string `json:"stream_server_ip" validate:"required"`                     // 流媒体服务器IP
+	StreamProtocol string `json:"stream_protocol" validate:"omitempty,oneof=rtsp rtmp srt"` // 推流协议，默认 rtsp
+	StreamPath     string `json:"stream_path" validate:"omitempty,max=128,stream_path"`     // 流路径，为空时使用 fpv/<设备ID>
+	StreamUsername string `json:"stream_username" validate:"omitempty,max=64"`              // 推流用户名
+	StreamPassword string `json:"stream_password" validate:"omitempty,max=128"`             // 推流密码
 
 	// 打击模块
 	StrikeIP   string `json:"strike_ip" validate:"required"`   // 打击模块IP
 	StrikePort int    `json:"strike_port" validate:"required"` // 打击模块端口
+
+	// 设备驱动
+	Driver       string         `json:"driver" validate:"omitempty,max=32"` // 设备驱动，为空时通过网络接入
+	DriverConfig map[string]any `json:"driver_config"`                      // 驱动配置
 }
 
 // UpdateDeviceRequest 更新设备请求结构
@@ -40,10 +55,64 @@ type UpdateDeviceRequest struct {
 	AnalysisIP *string `json:"analysis_ip" validate:"omitempty"` // 解析模块IP
 
 	// FPV模块
-	FPVIP          *string `json:"fpv_ip" validate:"omitempty"`           // FPV模块IP
-	StreamServerIP *string `json:"stream_server_ip" validate:"omitempty"` // 流媒体服务器IP
+	FPVIP          *string `json:"fpv_ip" validate:"omitempty"`                              // FPV模块IP
+	StreamServerIP *string `json:"stream_server_ip" validate:"omitempty"`                    // 流媒体服务器IP
+	StreamProtocol *string `json:"stream_protocol" validate:"omitempty,oneof=rtsp rtmp srt"` // 推流协议
+	StreamPath     *string `json:"stream_path" validate:"omitempty,max=128,stream_path"`     // 流路径，空字符串表示使用 fpv/<设备ID>
+	StreamUsername *string `json:"stream_username" validate:"omitempty,max=64"`              // 推流用户名
+	StreamPassword *string `json:"stream_password" validate:"omitempty,max=128"`             // 推流密码
 
 	// 打击模块
 	StrikeIP   *string `json:"strike_ip" validate:"omitempty"`   // 打击模块IP
 	StrikePort *int    `json:"strike_port" validate:"omitempty"` // 打击模块端口
+
+	// 设备驱动
+	Driver       *string        `json:"driver" validate:"omitempty,max=32"` // 设备驱动，空字符串表示改为网络接入
+	DriverConfig map[string]any `json:"driver_config"`                      // 驱动配置
+}
+
+// DeviceStatusResponse 设备在线状态响应结构
+type DeviceStatusResponse struct {
+	DeviceID uuid.UUID                        `json:"device_id"`
+	Online   bool                             `json:"online"` // 全部已探测模块在线时为 true
+	Modules  []models.DeviceModuleStatusModel `json:"modules"`
+}
+
+// DeviceDriverStatusResponse 设备驱动连接状态响应结构
+type DeviceDriverStatusResponse struct {
+	DeviceID      uuid.UUID  `json:"device_id"`
+	Driver        string     `json:"driver"`          // 设备驱动，为空表示通过网络接入
+	Connected     bool       `json:"connected"`       // 是否已建立驱动会话
+	Healthy       bool       `json:"healthy"`         // 最近一次健康检查是否正常
+	ConnectedAt   *time.Time `json:"connected_at"`    // 会话建立时间
+	LastCheckedAt *time.Time `json:"last_checked_at"` // 最近一次健康检查时间
+	LastError     string     `json:"last_error"`      // 最近一次连接或健康检查失败原因
+}
+
+// DeviceStreamURLs 设备视频播放地址
+type DeviceStreamURLs struct {
+	HLS    string `json:"hls"`    // HLS 播放地址
+	WebRTC string `json:"webrtc"` // WebRTC(WHEP) 播放地址
+	RTSP   string `json:"rtsp"`   // RTSP 播放地址
+}
+
+// DeviceStreamResponse 设备视频流响应结构，播放地址带签名且有时效
+type DeviceStreamResponse struct {
+	DeviceID   uuid.UUID        `json:"device_id"`
+	Path       string           `json:"path"`        // 流路径
+	Protocol   string           `json:"protocol"`    // FPV模块推流协议
+	PublishURL string           `json:"publish_url"` // FPV模块推流地址，不含凭据
+	URLs       DeviceStreamURLs `json:"urls"`
+	ExpiresAt  time.Time        `json:"expires_at"` // 播放地址过期时间
+}
+
+// StreamAuthRequest 流媒体服务器播放/推流鉴权回调请求结构
+type StreamAuthRequest struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	IP       string `json:"ip"`
+	Action   string `json:"action" validate:"required"` // read、playback、publish 等
+	Path     string `json:"path" validate:"required"`
+	Protocol string `json:"protocol"`
+	Query    string `json:"query"` // 请求地址中的查询参数，包含 expires 和 sign
 }
@@ -0,0 +1,7 @@
+package dto
+
+// RealtimeCommandRequest WebSocket 客户端订阅指令
+type RealtimeCommandRequest struct {
+	Action string   `json:"action"` // subscribe 或 unsubscribe
+	Topics []string `json:"topics"` // 主题列表
+}
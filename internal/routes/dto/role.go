@@ -1,21 +1,29 @@
 package dto
 
 import (
+	"xacms/internal/models"
+
 	"github.com/google/uuid"
 )
 
 // CreateRoleRequest 创建角色请求结构
 type CreateRoleRequest struct {
-	Name        string `json:"name" validate:"required,min=2,max=64"`
-	Description string `json:"description" validate:"omitempty,max=255"`
-	Order       uint   `json:"order" validate:"omitempty,min=0"`
+	Name        string           `json:"name" validate:"required,min=2,max=64"`
+	Description string           `json:"description" validate:"omitempty,max=255"`
+	Order       uint             `json:"order" validate:"omitempty,min=0"`
+	DataScope   models.DataScope `json:"data_scope" validate:"omitempty,oneof=1 2 3 4"` // 数据范围，默认全部
+
+	RequireTwoFactor bool `json:"require_two_factor"` // 是否要求该角色的用户启用双因素认证
 }
 
 // UpdateRoleRequest 更新角色请求结构
 type UpdateRoleRequest struct {
-	Name        *string `json:"name" validate:"omitempty,min=2,max=64"`
-	Description *string `json:"description" validate:"omitempty,max=255"`
-	Order       *uint   `json:"order" validate:"omitempty,min=0"`
+	Name        *string           `json:"name" validate:"omitempty,min=2,max=64"`
+	Description *string           `json:"description" validate:"omitempty,max=255"`
+	Order       *uint             `json:"order" validate:"omitempty,min=0"`
+	DataScope   *models.DataScope `json:"data_scope" validate:"omitempty,oneof=1 2 3 4"`
+
+	RequireTwoFactor *bool `json:"require_two_factor"`
 }
 
 // AssignMenusRequest 分配菜单请求结构
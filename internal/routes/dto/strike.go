@@ -0,0 +1,17 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreateStrikeRequest 申请打击请求结构
+type CreateStrikeRequest struct {
+	DeviceID uuid.UUID  `json:"device_id" validate:"required"`                        // 执行打击的设备ID
+	TrackID  *uuid.UUID `json:"track_id" validate:"omitempty"`                        // 打击目标航迹ID
+	Action   string     `json:"action" validate:"required,oneof=jam spoof land stop"` // 打击方式
+	Duration int        `json:"duration" validate:"gte=0,lte=3600"`                   // 持续时间(秒)，0 表示由打击模块决定
+	Reason   string     `json:"reason" validate:"required,max=255"`                   // 申请原因
+}
+
+// StrikeDecisionRequest 审批打击指令请求结构
+type StrikeDecisionRequest struct {
+	Note string `json:"note" validate:"omitempty,max=255"` // 审批意见
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "xacms/internal/models"
+
+// CreateTenantRequest 创建租户请求结构
+type CreateTenantRequest struct {
+	Name        string         `json:"name" validate:"required,min=2,max=64"`
+	Code        string         `json:"code" validate:"required,min=2,max=64,alphanum,lowercase"` // 租户编码，用于子域名识别
+	Description string         `json:"description" validate:"omitempty,max=255"`
+	Status      *models.Status `json:"status" validate:"omitempty,oneof=0 1"`
+}
+
+// UpdateTenantRequest 更新租户请求结构
+type UpdateTenantRequest struct {
+	Name        *string        `json:"name" validate:"omitempty,min=2,max=64"`
+	Code        *string        `json:"code" validate:"omitempty,min=2,max=64,alphanum,lowercase"`
+	Description *string        `json:"description" validate:"omitempty,max=255"`
+	Status      *models.Status `json:"status" validate:"omitempty,oneof=0 1"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"xacms/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// TrackQueryRequest 航迹查询请求结构
+type TrackQueryRequest struct {
+	ListQueryRequest
+	DeviceID  *uuid.UUID `query:"device_id" validate:"omitempty,uuid"`
+	StartTime string     `query:"start_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // 开始时间，RFC3339，返回与时间范围有重叠的航迹
+	EndTime   string     `query:"end_time" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`   // 结束时间，RFC3339
+}
+
+// GeoJSONLineString GeoJSON 线要素几何
+type GeoJSONLineString struct {
+	Type        string      `json:"type"`        // 固定为 LineString
+	Coordinates [][]float64 `json:"coordinates"` // 坐标点：[经度, 纬度]，所有点都有高度时为 [经度, 纬度, 高度]
+}
+
+// TrackDetailResponse 航迹详情响应结构
+type TrackDetailResponse struct {
+	models.TrackModel
+	Path GeoJSONLineString `json:"path"` // 按时间排序的完整航迹
+}
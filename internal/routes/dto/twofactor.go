@@ -0,0 +1,36 @@
+package dto
+
+// TwoFactorStatusResponse 双因素认证状态响应结构
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`                  // 是否已启用
+	Required               bool  `json:"required"`                 // 角色是否要求启用
+	Pending                bool  `json:"pending"`                  // 是否已生成密钥等待确认
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"` // 剩余可用的恢复码数量
+}
+
+// TwoFactorSetupRequest 生成双因素认证密钥请求结构
+type TwoFactorSetupRequest struct {
+	Password string `json:"password" validate:"required,max=128"` // 当前密码
+}
+
+// TwoFactorSetupResponse 生成双因素认证密钥响应结构
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`      // Base32 编码的密钥，用于手动输入
+	OtpauthURI string `json:"otpauth_uri"` // 身份验证器应用扫码使用的地址，可直接生成二维码
+}
+
+// TwoFactorCodeRequest 双因素认证验证码请求结构
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"` // 身份验证器应用中的验证码，启用时只能使用验证码
+}
+
+// DisableTwoFactorRequest 停用双因素认证请求结构
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required,max=128"` // 当前密码
+	Code     string `json:"code" validate:"required,max=32"`      // 身份验证器应用中的验证码或恢复码
+}
+
+// RecoveryCodesResponse 恢复码响应结构，恢复码仅在生成时返回一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
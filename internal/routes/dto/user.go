@@ -8,11 +8,11 @@ import (
 
 // UserQueryRequest 用户查询请求结构
 type UserQueryRequest struct {
-	BaseQueryRequest
-	// Status       *models.Status `query:"status" validate:"omitempty,oneof=0 1"`
-	// RoleID       *uuid.UUID     `query:"role_id" validate:"omitempty,uuid"`
-	// TenantID     *uuid.UUID     `query:"tenant_id" validate:"omitempty,uuid"`
-	// DepartmentID *uuid.UUID     `query:"department_id" validate:"omitempty,uuid"`
+	ListQueryRequest
+	Status       *models.Status `query:"status" validate:"omitempty,oneof=0 1"`
+	RoleID       *uuid.UUID     `query:"role_id" validate:"omitempty,uuid"`
+	TenantID     *uuid.UUID     `query:"tenant_id" validate:"omitempty,uuid"`
+	DepartmentID *uuid.UUID     `query:"department_id" validate:"omitempty,uuid"` // 部门ID，包含下级部门的用户
 }
 
 // CreateUserRequest 创建用户请求结构
@@ -36,19 +36,23 @@ type UpdateUserRequest struct {
 	Status   *models.Status `json:"status" validate:"omitempty,oneof=0 1"`
 }
 
+// AssignDepartmentRequest 分配部门请求结构，部门ID为空时移出部门
+type AssignDepartmentRequest struct {
+	DepartmentID *uuid.UUID `json:"department_id" validate:"omitempty,uuid"`
+}
+
 // AssignRoleRequest 分配角色请求结构
 type AssignRoleRequest struct {
 	RoleID uuid.UUID `json:"role_id" validate:"required,uuid"`
 }
 
-// // ChangePasswordRequest 修改密码请求结构
-// type ChangePasswordRequest struct {
-// 	OldPassword string `json:"old_password" validate:"required,min=6,max=128"`
-// 	NewPassword string `json:"new_password" validate:"required,min=6,max=128"`
-// }
-
-// // ResetPasswordRequest 重置密码请求结构
-// type ResetPasswordRequest struct {
-// 	UserID      uuid.UUID `json:"user_id" validate:"required,uuid"`
-// 	NewPassword string    `json:"new_password" validate:"required,min=6,max=128"`
-// }
+// ChangePasswordRequest 修改密码请求结构
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required,min=6,max=128"`
+	NewPassword string `json:"new_password" validate:"required,password_strength,max=128"`
+}
+
+// ResetPasswordRequest 重置密码请求结构
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" validate:"required,password_strength,max=128"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateWhitelistEntryRequest 创建白名单请求结构
+type CreateWhitelistEntryRequest struct {
+	Serial     string      `json:"serial" validate:"required,max=64"`
+	DroneModel string      `json:"drone_model" validate:"omitempty,max=64"`
+	Owner      string      `json:"owner" validate:"omitempty,max=64"`
+	ValidFrom  *time.Time  `json:"valid_from" validate:"omitempty"`  // 生效时间，为空表示立即生效
+	ValidUntil *time.Time  `json:"valid_until" validate:"omitempty"` // 失效时间，为空表示长期有效
+	Enabled    *bool       `json:"enabled" validate:"omitempty"`     // 是否启用，默认启用
+	Remark     string      `json:"remark" validate:"omitempty,max=255"`
+	ZoneIDs    []uuid.UUID `json:"zone_ids" validate:"omitempty,dive,uuid"` // 允许活动的区域，为空表示不限区域
+}
+
+// UpdateWhitelistEntryRequest 更新白名单请求结构，有效期为零值时间时表示清除，区域为空列表时表示不限区域
+type UpdateWhitelistEntryRequest struct {
+	Serial     *string      `json:"serial" validate:"omitempty,max=64"`
+	DroneModel *string      `json:"drone_model" validate:"omitempty,max=64"`
+	Owner      *string      `json:"owner" validate:"omitempty,max=64"`
+	ValidFrom  *time.Time   `json:"valid_from" validate:"omitempty"`
+	ValidUntil *time.Time   `json:"valid_until" validate:"omitempty"`
+	Enabled    *bool        `json:"enabled" validate:"omitempty"`
+	Remark     *string      `json:"remark" validate:"omitempty,max=255"`
+	ZoneIDs    *[]uuid.UUID `json:"zone_ids" validate:"omitempty"`
+}
+
+// WhitelistImportError 白名单导入失败的行
+type WhitelistImportError struct {
+	Line    int    `json:"line"`    // CSV 行号，表头为第 1 行
+	Message string `json:"message"` // 失败原因
+}
+
+// WhitelistImportResponse 白名单导入结果，按序列号新增或更新，失败的行不影响其他行
+type WhitelistImportResponse struct {
+	Created int                    `json:"created"` // 新增条数
+	Updated int                    `json:"updated"` // 更新条数
+	Errors  []WhitelistImportError `json:"errors"`  // 失败的行
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"xacms/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CreateZoneRequest 创建区域请求结构
+type CreateZoneRequest struct {
+	Name     string               `json:"name" validate:"required,min=2,max=64"`
+	Type     models.ZoneType      `json:"type" validate:"required,oneof=protected no_fly alert_buffer"`
+	Enabled  *bool                `json:"enabled" validate:"omitempty"` // 是否启用，默认启用
+	Remark   string               `json:"remark" validate:"omitempty,max=255"`
+	Geometry *models.ZoneGeometry `json:"geometry" validate:"required"` // GeoJSON 几何对象：Polygon、MultiPolygon 或 Point
+	Radius   float64              `json:"radius" validate:"gte=0"`      // 圆形区域半径(米)，几何对象为 Point 时必填
+}
+
+// UpdateZoneRequest 更新区域请求结构
+type UpdateZoneRequest struct {
+	Name     *string              `json:"name" validate:"omitempty,min=2,max=64"`
+	Type     *models.ZoneType     `json:"type" validate:"omitempty,oneof=protected no_fly alert_buffer"`
+	Enabled  *bool                `json:"enabled" validate:"omitempty"`
+	Remark   *string              `json:"remark" validate:"omitempty,max=255"`
+	Geometry *models.ZoneGeometry `json:"geometry" validate:"omitempty"`
+	Radius   *float64             `json:"radius" validate:"omitempty,gte=0"`
+}
+
+// AssignZoneDevicesRequest 分配区域设备请求结构，空列表表示清除关联
+type AssignZoneDevicesRequest struct {
+	DeviceIDs []uuid.UUID `json:"device_ids" validate:"required,dive,uuid"`
+}
@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// guardedRouter 在每个路由的处理器链前插入守卫处理器
+//
+// 通过 Use 注册的中间件中 c.Route() 指向中间件自身，无法获取目标路由名称；
+// 将守卫作为路由处理器注册后，c.Route().Name 即为当前路由的名称。
+type guardedRouter struct {
+	fiber.Router
+	guards []fiber.Handler
+}
+
+// newGuardedRouter 创建带守卫的路由器
+func newGuardedRouter(router fiber.Router, guards ...fiber.Handler) *guardedRouter {
+	return &guardedRouter{
+		Router: router,
+		guards: guards,
+	}
+}
+
+// with 将守卫处理器添加到处理器链前
+func (r *guardedRouter) with(handlers []fiber.Handler) []fiber.Handler {
+	chain := make([]fiber.Handler, 0, len(r.guards)+len(handlers))
+	chain = append(chain, r.guards...)
+	return append(chain, handlers...)
+}
+
+func (r *guardedRouter) Get(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Get(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Head(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Head(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Post(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Post(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Put(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Put(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Delete(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Delete(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Connect(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Connect(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Options(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Options(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Trace(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Trace(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Patch(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Patch(path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) Add(method, path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Add(method, path, r.with(handlers)...)
+	return r
+}
+
+func (r *guardedRouter) All(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.All(path, r.with(handlers)...)
+	return r
+}
+
+// Group 创建子路由组，子路由组同样带有守卫
+func (r *guardedRouter) Group(prefix string, handlers ...fiber.Handler) fiber.Router {
+	return newGuardedRouter(r.Router.Group(prefix, handlers...), r.guards...)
+}
+
+// Route 创建子路由组并在回调中注册路由
+func (r *guardedRouter) Route(prefix string, fn func(router fiber.Router), name ...string) fiber.Router {
+	group := r.Group(prefix)
+	if len(name) > 0 {
+		group.Name(name[0])
+	}
+	fn(group)
+	return group
+}
+
+// Name 设置路由或路由组名称，返回自身以保持守卫
+func (r *guardedRouter) Name(name string) fiber.Router {
+	r.Router.Name(name)
+	return r
+}
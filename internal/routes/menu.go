@@ -1,8 +1,10 @@
 package routes
 
 import (
+	"errors"
 	"xacms/internal/models"
 	"xacms/internal/routes/dto"
+	"xacms/internal/server/middlewares"
 	"xacms/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,29 +16,47 @@ import (
 
 // MenuHandler 菜单处理器
 type MenuHandler struct {
-	CommonService services.CommonService
-	MenuService   services.MenuService
+	CommonService     services.CommonService
+	MenuService       services.MenuService
+	ButtonService     services.ButtonService
+	PermissionService services.PermissionService
 }
 
 // RegisterRoutes 注册菜单相关路由
+//
+// 菜单和按钮由所有租户共享，租户用户只能查看，新增、修改和删除仅平台用户可操作。
 func (h *MenuHandler) RegisterRoutes(router fiber.Router) {
+	platformOnly := middlewares.PlatformOnlyMiddleware()
 
 	menuGroup := router.Group("/menus").Name("菜单管理.")
 
 	menuGroup.Get("", h.GetMenus).Name("获取菜单列表")
-	menuGroup.Post("", h.CreateMenu).Name("创建菜单")
+	menuGroup.Post("", platformOnly, h.CreateMenu).Name("创建菜单")
 	menuGroup.Get("/:id<guid>", h.GetMenu).Name("获取菜单详情")
-	menuGroup.Put("/:id<guid>", h.UpdateMenu).Name("更新菜单")
-	menuGroup.Delete("/:id<guid>", h.DeleteMenu).Name("删除菜单")
+	menuGroup.Put("/:id<guid>", platformOnly, h.UpdateMenu).Name("更新菜单")
+	menuGroup.Delete("/:id<guid>", platformOnly, h.DeleteMenu).Name("删除菜单")
 	menuGroup.Get("/tree", h.GetMenuTree).Name("获取菜单树")
 	menuGroup.Get("/apis", h.GetAPIs).Name("获取API列表")
-
+	menuGroup.Get("/:id<guid>/buttons", h.GetMenuButtons).Name("获取菜单按钮")
+	menuGroup.Post("/:id<guid>/buttons", platformOnly, h.CreateButton).Name("创建菜单按钮")
+	menuGroup.Put("/:id<guid>/buttons/:buttonId<guid>", platformOnly, h.UpdateButton).Name("更新菜单按钮")
+	menuGroup.Delete("/:id<guid>/buttons/:buttonId<guid>", platformOnly, h.DeleteButton).Name("删除菜单按钮")
 }
 
 // GetMenus 获取菜单列表
 func (h *MenuHandler) GetMenus(c *fiber.Ctx) error {
-	var menus []models.MenuModel
-	if err := h.CommonService.GetItems(&menus); err != nil {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取菜单列表
+	menus, err := h.MenuService.GetMenus(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
 		log.Errorf("获取菜单列表失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取菜单列表失败"))
 	}
@@ -53,7 +73,7 @@ func (h *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 	}
 
 	// 创建菜单
-	menu, err := h.MenuService.CreateMenu(&req)
+	menu, err := h.MenuService.CreateMenu(c.UserContext(), &req)
 	if err != nil {
 		log.Errorf("创建菜单失败: %v", err)
 		if sqliteErr, ok := err.(sqlite3.Error); ok {
@@ -80,7 +100,7 @@ func (h *MenuHandler) GetMenu(c *fiber.Ctx) error {
 
 	// 获取菜单
 	var menu models.MenuModel
-	if err := h.CommonService.GetItemByID(menuUUID, &menu); err != nil {
+	if err := h.CommonService.GetItemByID(c.UserContext(), menuUUID, &menu); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, "菜单不存在"))
 		}
@@ -107,7 +127,7 @@ func (h *MenuHandler) UpdateMenu(c *fiber.Ctx) error {
 	}
 
 	// 更新菜单
-	menu, err := h.MenuService.UpdateMenu(menuUUID, &req)
+	menu, err := h.MenuService.UpdateMenu(c.UserContext(), menuUUID, &req)
 	if err != nil {
 		log.Errorf("更新菜单失败: %v", err)
 		if sqliteErr, ok := err.(sqlite3.Error); ok {
@@ -133,18 +153,24 @@ func (h *MenuHandler) DeleteMenu(c *fiber.Ctx) error {
 	}
 
 	// 删除菜单
-	if err := h.CommonService.DeleteItemByID(&models.MenuModel{}, menuUUID); err != nil {
+	if err := h.CommonService.DeleteItemByID(c.UserContext(), &models.MenuModel{}, menuUUID); err != nil {
 		log.Errorf("删除菜单失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除菜单失败"))
 	}
 
+	// 删除菜单下的按钮，同时清除权限缓存
+	if err := h.ButtonService.DeleteMenuButtons(c.UserContext(), menuUUID); err != nil {
+		log.Errorf("删除菜单按钮失败: %v", err)
+	}
+	h.PermissionService.InvalidateAll()
+
 	return c.JSON(dto.SuccessResponse(nil))
 }
 
 // GetMenuTree 获取菜单树结构
 func (h *MenuHandler) GetMenuTree(c *fiber.Ctx) error {
 	// 组装为树形结构
-	menuTree, err := h.MenuService.GetMenuTree()
+	menuTree, err := h.MenuService.GetMenuTree(c.UserContext())
 	if err != nil {
 		log.Errorf("获取菜单树失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取菜单树失败"))
@@ -157,3 +183,122 @@ func (h *MenuHandler) GetMenuTree(c *fiber.Ctx) error {
 func (h *MenuHandler) GetAPIs(c *fiber.Ctx) error {
 	return c.JSON(dto.SuccessResponse(h.CommonService.GetAPIs()))
 }
+
+// GetMenuButtons 获取菜单按钮列表
+func (h *MenuHandler) GetMenuButtons(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	menuUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "菜单ID格式无效"))
+	}
+
+	// 获取按钮列表
+	buttons, err := h.ButtonService.GetMenuButtons(c.UserContext(), menuUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrMenuNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取菜单按钮失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取菜单按钮失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(buttons))
+}
+
+// CreateButton 创建菜单按钮
+func (h *MenuHandler) CreateButton(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	menuUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "菜单ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.CreateButtonRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建按钮
+	button, err := h.ButtonService.CreateButton(c.UserContext(), menuUUID, &req)
+	if err != nil {
+		log.Errorf("创建菜单按钮失败: %v", err)
+		if errors.Is(err, services.ErrMenuNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok {
+			if sqliteErr.Code == sqlite3.ErrConstraint {
+				return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "按钮编码已存在"))
+			}
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建菜单按钮失败"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(button))
+}
+
+// UpdateButton 更新菜单按钮
+func (h *MenuHandler) UpdateButton(c *fiber.Ctx) error {
+	// 验证 UUID 格式
+	menuUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "菜单ID格式无效"))
+	}
+	buttonUUID, err := uuid.Parse(c.Params("buttonId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "按钮ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.UpdateButtonRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 更新按钮
+	button, err := h.ButtonService.UpdateButton(c.UserContext(), menuUUID, buttonUUID, &req)
+	if err != nil {
+		log.Errorf("更新菜单按钮失败: %v", err)
+		if errors.Is(err, services.ErrButtonNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok {
+			if sqliteErr.Code == sqlite3.ErrConstraint {
+				return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "按钮编码已存在"))
+			}
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新菜单按钮失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(button))
+}
+
+// DeleteButton 删除菜单按钮
+func (h *MenuHandler) DeleteButton(c *fiber.Ctx) error {
+	// 验证 UUID 格式
+	menuUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "菜单ID格式无效"))
+	}
+	buttonUUID, err := uuid.Parse(c.Params("buttonId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "按钮ID格式无效"))
+	}
+
+	// 删除按钮
+	if err := h.ButtonService.DeleteButton(c.UserContext(), menuUUID, buttonUUID); err != nil {
+		if errors.Is(err, services.ErrButtonNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("删除菜单按钮失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除菜单按钮失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
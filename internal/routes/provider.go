@@ -7,5 +7,17 @@ var RoutesSet = wire.NewSet(
 	wire.Struct(new(MenuHandler), "*"),
 	wire.Struct(new(UserHandler), "*"),
 	wire.Struct(new(DeviceHandler), "*"),
+	wire.Struct(new(AuthHandler), "*"),
+	wire.Struct(new(TenantHandler), "*"),
+	wire.Struct(new(DepartmentHandler), "*"),
+	wire.Struct(new(DetectionHandler), "*"),
+	wire.Struct(new(TrackHandler), "*"),
+	wire.Struct(new(RealtimeHandler), "*"),
+	wire.Struct(new(StrikeHandler), "*"),
+	wire.Struct(new(ZoneHandler), "*"),
+	wire.Struct(new(AlertHandler), "*"),
+	wire.Struct(new(WhitelistHandler), "*"),
+	wire.Struct(new(AuditHandler), "*"),
+	wire.Struct(new(ApiKeyHandler), "*"),
 	NewRouter,
 )
@@ -0,0 +1,298 @@
+package routes
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"xacms/internal/pkg/realtime"
+	"xacms/internal/routes/dto"
+	"xacms/internal/server/middlewares"
+	"xacms/internal/services"
+
+	"github.com/bytedance/sonic"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+const (
+	realtimeWriteTimeout = 10 * time.Second // 单次写入超时
+	realtimePingInterval = 30 * time.Second // WebSocket ping、SSE 保活以及凭据复核间隔
+	realtimeReadLimit    = 4096             // 客户端指令最大长度
+)
+
+// RealtimeHandler 实时推送处理器
+type RealtimeHandler struct {
+	RealtimeService services.RealtimeService
+	SessionService  services.SessionService
+	ApiKeyService   services.ApiKeyService
+}
+
+// RegisterRoutes 注册实时推送相关路由
+func (h *RealtimeHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/ws", h.WebSocket).Name("实时推送.WebSocket订阅")
+	router.Get("/events", h.EventStream).Name("实时推送.SSE订阅")
+}
+
+// WebSocket 建立 WebSocket 连接，可通过 topics 查询参数指定初始订阅主题，
+// 连接后发送 {"action":"subscribe|unsubscribe","topics":[...]} 调整订阅
+func (h *RealtimeHandler) WebSocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(dto.ErrorResponse(fiber.StatusUpgradeRequired, "需要 WebSocket 升级请求"))
+	}
+
+	userID, _ := middlewares.GetUserID(c)
+	tenantID := requestTenant(c)
+
+	// 校验初始订阅主题
+	topics := parseTopics(c.Query("topics"))
+	if err := h.RealtimeService.Authorize(userID, topics); err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	// 连接接管后不能再使用 c
+	recheck := h.streamRecheck(c)
+	return websocket.New(func(conn *websocket.Conn) {
+		client := h.RealtimeService.Connect(tenantID)
+		defer h.RealtimeService.Disconnect(client)
+
+		client.Subscribe(topics...)
+		h.serveWebSocket(conn, client, userID, recheck)
+	})(c)
+}
+
+// serveWebSocket 读取客户端订阅指令并推送事件，直到任一方断开或凭据失效
+func (h *RealtimeHandler) serveWebSocket(conn *websocket.Conn, client *realtime.Client, userID uuid.UUID, recheck func(topics []string) string) {
+	// 连接不支持并发写入，读取协程的回复与事件推送需要互斥
+	var writeMu sync.Mutex
+	write := func(messageType int, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(realtimeWriteTimeout))
+		return conn.WriteMessage(messageType, data)
+	}
+	reply := func(message realtime.Message) error {
+		data, _ := sonic.Marshal(message)
+		return write(websocket.TextMessage, data)
+	}
+
+	if err := reply(realtime.Message{Type: realtime.MessageSubscribed, Topics: client.Topics()}); err != nil {
+		return
+	}
+
+	// 读取客户端指令
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		conn.SetReadLimit(realtimeReadLimit)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var command dto.RealtimeCommandRequest
+			if err := sonic.Unmarshal(data, &command); err != nil || len(command.Topics) == 0 {
+				_ = reply(realtime.Message{Type: realtime.MessageError, Message: "指令格式无效"})
+				continue
+			}
+
+			switch command.Action {
+			case "subscribe":
+				if err := h.RealtimeService.Authorize(userID, command.Topics); err != nil {
+					_ = reply(realtime.Message{Type: realtime.MessageError, Message: topicErrorMessage(err)})
+					continue
+				}
+				client.Subscribe(command.Topics...)
+			case "unsubscribe":
+				client.Unsubscribe(command.Topics...)
+			default:
+				_ = reply(realtime.Message{Type: realtime.MessageError, Message: "不支持的指令"})
+				continue
+			}
+			_ = reply(realtime.Message{Type: realtime.MessageSubscribed, Topics: client.Topics()})
+		}
+	}()
+
+	ticker := time.NewTicker(realtimePingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case delivery := <-client.Deliveries():
+			if err := write(websocket.TextMessage, delivery.Data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if reason := recheck(client.Topics()); reason != "" {
+				_ = reply(realtime.Message{Type: realtime.MessageDisconnected, Message: reason})
+				_ = write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ""))
+				return
+			}
+			if err := write(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-client.Done():
+			// 服务端断开：客户端消费过慢或服务关闭，客户端可稍后重连
+			_ = reply(realtime.Message{Type: realtime.MessageDisconnected, Message: client.Reason()})
+			_ = write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, ""))
+			return
+		case <-readerDone:
+			return
+		}
+	}
+}
+
+// EventStream 建立 SSE 连接，通过 topics 查询参数指定订阅主题，事件名称为主题
+func (h *RealtimeHandler) EventStream(c *fiber.Ctx) error {
+	userID, _ := middlewares.GetUserID(c)
+	tenantID := requestTenant(c)
+
+	// 校验订阅主题
+	topics := parseTopics(c.Query("topics"))
+	if len(topics) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "请指定订阅主题"))
+	}
+	if err := h.RealtimeService.Authorize(userID, topics); err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	recheck := h.streamRecheck(c)
+	client := h.RealtimeService.Connect(tenantID)
+	client.Subscribe(topics...)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.RealtimeService.Disconnect(client)
+
+		ticker := time.NewTicker(realtimePingInterval)
+		defer ticker.Stop()
+
+		fmt.Fprintf(w, "retry: 3000\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case delivery := <-client.Deliveries():
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", delivery.Topic, delivery.Data)
+			case <-ticker.C:
+				if reason := recheck(client.Topics()); reason != "" {
+					data, _ := sonic.Marshal(realtime.Message{Type: realtime.MessageDisconnected, Message: reason})
+					fmt.Fprintf(w, "event: %s\ndata: %s\n\n", realtime.MessageDisconnected, data)
+					_ = w.Flush()
+					return
+				}
+				fmt.Fprintf(w, ": keepalive\n\n")
+			case <-client.Done():
+				data, _ := sonic.Marshal(realtime.Message{Type: realtime.MessageDisconnected, Message: client.Reason()})
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", realtime.MessageDisconnected, data)
+				_ = w.Flush()
+				return
+			}
+
+			// 写入失败说明客户端已断开
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// streamRecheck 返回长连接的凭据复核函数，凭据有效时返回空字符串，否则返回断开原因
+//
+// 长连接只在建立时经过认证中间件，之后定期复核：访问令牌过期、会话被撤销（退出登录、
+// 强制下线、修改密码、禁用用户等）、API密钥失效或失去订阅主题的权限时断开连接。
+// 复核函数在连接接管后调用，所需的请求信息需在此处复制。
+func (h *RealtimeHandler) streamRecheck(c *fiber.Ctx) func(topics []string) string {
+	userID, _ := middlewares.GetUserID(c)
+	claims := middlewares.GetClaims(c)
+	ip := strings.Clone(c.IP())
+	routeName := c.Route().Name
+
+	var apiKey string
+	if middlewares.GetApiKey(c) != nil {
+		apiKey = strings.Clone(c.Get("X-API-Key"))
+	}
+
+	return func(topics []string) string {
+		if apiKey != "" {
+			key, ok, err := h.ApiKeyService.AuthenticateApiKey(apiKey, ip)
+			if err != nil {
+				log.Errorf("复核API密钥失败: %v", err)
+				return "凭据校验失败，请重新连接"
+			}
+			if !ok || !key.HasScope(routeName) {
+				return "API密钥已失效"
+			}
+		} else {
+			if claims == nil || claims.ExpiresAt == nil || time.Now().After(claims.ExpiresAt.Time) {
+				return "访问令牌已过期"
+			}
+			active, err := h.SessionService.ValidateSession(claims, ip)
+			if err != nil {
+				log.Errorf("复核会话失败: %v", err)
+				return "凭据校验失败，请重新连接"
+			}
+			if !active {
+				return "登录会话已失效"
+			}
+		}
+
+		if err := h.RealtimeService.Authorize(userID, topics); err != nil {
+			return topicErrorMessage(err)
+		}
+		return ""
+	}
+}
+
+// parseTopics 解析逗号分隔的主题列表
+func parseTopics(value string) []string {
+	var topics []string
+	for _, topic := range strings.Split(value, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// requestTenant 获取请求限定的租户，平台用户未指定租户时返回 nil
+func requestTenant(c *fiber.Ctx) *uuid.UUID {
+	if tenantID, ok := middlewares.GetTenantID(c); ok {
+		return &tenantID
+	}
+	return nil
+}
+
+// topicErrorResponse 返回订阅主题校验失败响应
+func topicErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUnknownTopic):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	case errors.Is(err, services.ErrTopicDenied):
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse(fiber.StatusForbidden, err.Error()))
+	default:
+		log.Errorf("校验订阅主题失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "校验订阅主题失败"))
+	}
+}
+
+// topicErrorMessage 获取订阅主题校验失败时返回给 WebSocket 客户端的消息
+func topicErrorMessage(err error) string {
+	if errors.Is(err, services.ErrUnknownTopic) || errors.Is(err, services.ErrTopicDenied) {
+		return err.Error()
+	}
+	log.Errorf("校验订阅主题失败: %v", err)
+	return "校验订阅主题失败"
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"xacms/internal/models"
 	"xacms/internal/routes/dto"
 	"xacms/internal/services"
@@ -13,8 +14,9 @@ import (
 
 // RoleHandler 角色处理器
 type RoleHandler struct {
-	RoleService   services.RoleService
-	CommonService services.CommonService
+	RoleService       services.RoleService
+	CommonService     services.CommonService
+	PermissionService services.PermissionService
 }
 
 // RegisterRoutes 注册角色相关路由
@@ -28,14 +30,28 @@ func (h *RoleHandler) RegisterRoutes(router fiber.Router) {
 	roleGroup.Delete("/:id<guid>", h.DeleteRole).Name("删除角色")
 	roleGroup.Get("/:id<guid>/menus", h.GetRoleMenus).Name("获取角色菜单")
 	roleGroup.Post("/:id<guid>/menus", h.AssignMenus).Name("分配角色菜单")
+	roleGroup.Get("/:id<guid>/buttons", h.GetRoleButtons).Name("获取角色按钮")
+	roleGroup.Post("/:id<guid>/buttons", h.AssignButtons).Name("分配角色按钮")
 }
 
 // GetRoles 获取角色列表
 func (h *RoleHandler) GetRoles(c *fiber.Ctx) error {
-	var roles []models.RoleModel
-	if err := h.CommonService.GetItems(&roles); err != nil {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取角色列表
+	roles, err := h.RoleService.GetRoles(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取角色列表失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取角色列表失败"))
 	}
+
 	return c.JSON(dto.SuccessResponse(roles))
 }
 
@@ -48,7 +64,7 @@ func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
 	}
 
 	// 创建角色
-	role, err := h.RoleService.CreateRole(req)
+	role, err := h.RoleService.CreateRole(c.UserContext(), req)
 	if err != nil {
 		log.Errorf("创建角色失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建角色失败"))
@@ -69,7 +85,7 @@ func (h *RoleHandler) GetRole(c *fiber.Ctx) error {
 
 	// 获取角色
 	var role models.RoleModel
-	if err := h.CommonService.GetItemByID(roleUUID, &role); err != nil {
+	if err := h.CommonService.GetItemByID(c.UserContext(), roleUUID, &role); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, "角色不存在"))
 		}
@@ -97,8 +113,11 @@ func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
 	}
 
 	// 更新角色
-	role, err := h.RoleService.UpdateRole(roleUUID, req)
+	role, err := h.RoleService.UpdateRole(c.UserContext(), roleUUID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
 		log.Errorf("更新角色失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新角色失败"))
 	}
@@ -117,11 +136,14 @@ func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
 	}
 
 	// 删除角色
-	if err := h.CommonService.DeleteItemByID(&models.RoleModel{}, roleUUID); err != nil {
+	if err := h.CommonService.DeleteItemByID(c.UserContext(), &models.RoleModel{}, roleUUID); err != nil {
 		log.Errorf("删除角色失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除角色失败"))
 	}
 
+	// 清除该角色的权限缓存
+	h.PermissionService.InvalidateRole(roleUUID)
+
 	return c.JSON(dto.SuccessResponse(nil))
 }
 
@@ -136,8 +158,11 @@ func (h *RoleHandler) GetRoleMenus(c *fiber.Ctx) error {
 	}
 
 	// 获取角色菜单
-	menus, err := h.RoleService.GetRoleMenus(roleUUID)
+	menus, err := h.RoleService.GetRoleMenus(c.UserContext(), roleUUID)
 	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
 		log.Errorf("获取角色菜单失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取角色菜单失败"))
 	}
@@ -161,11 +186,65 @@ func (h *RoleHandler) AssignMenus(c *fiber.Ctx) error {
 	}
 
 	// 分配菜单
-	role, err := h.RoleService.AssignMenus(roleUUID, req)
+	role, err := h.RoleService.AssignMenus(c.UserContext(), roleUUID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
 		log.Errorf("分配菜单失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "分配菜单失败"))
 	}
 
 	return c.JSON(dto.SuccessResponse(role))
 }
+
+// GetRoleButtons 获取角色按钮
+func (h *RoleHandler) GetRoleButtons(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	roleUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "角色ID格式无效"))
+	}
+
+	// 获取角色按钮
+	buttons, err := h.RoleService.GetRoleButtons(c.UserContext(), roleUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取角色按钮失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取角色按钮失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(buttons))
+}
+
+// AssignButtons 分配按钮给角色
+func (h *RoleHandler) AssignButtons(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	roleUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "角色ID格式无效"))
+	}
+
+	var req dto.AssignButtonsRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 分配按钮
+	role, err := h.RoleService.AssignButtons(c.UserContext(), roleUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("分配按钮失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "分配按钮失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(role))
+}
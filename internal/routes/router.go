@@ -1,10 +1,13 @@
 package routes
 
 import (
+	"os"
+	"xacms/internal/pkg/token"
 	"xacms/internal/server"
+	"xacms/internal/server/middlewares"
+	"xacms/internal/services"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
 )
 
 // RouteModule 定义路由模块接口
@@ -12,26 +15,79 @@ type RouteModule interface {
 	RegisterRoutes(router fiber.Router)
 }
 
+// PublicRouteModule 定义包含公开路由（不经过用户认证）的路由模块接口
+type PublicRouteModule interface {
+	RegisterPublicRoutes(router fiber.Router)
+}
+
+// authenticatedOnlyPrefixes 只要求登录、无需授权的路由名称前缀
+var authenticatedOnlyPrefixes = []string{
+	"认证管理.",
+	"个人中心.",
+}
+
 // Router 路由注册器
 type Router struct {
-	server  *server.FiberServer
-	modules []RouteModule
+	server            *server.FiberServer
+	tokenManager      *token.Manager
+	permissionService services.PermissionService
+	tenantService     services.TenantService
+	auditService      services.AuditService
+	apiKeyService     services.ApiKeyService
+	sessionService    services.SessionService
+	modules           []RouteModule
 }
 
 // NewRouter 创建路由注册器
 func NewRouter(server *server.FiberServer,
+	tokenManager *token.Manager,
+	permissionService services.PermissionService,
+	tenantService services.TenantService,
+	auditService services.AuditService,
+	apiKeyService services.ApiKeyService,
+	sessionService services.SessionService,
+	authHandler *AuthHandler,
 	userHandler *UserHandler,
 	menuHandler *MenuHandler,
 	roleHandler *RoleHandler,
 	deviceHandler *DeviceHandler,
+	tenantHandler *TenantHandler,
+	departmentHandler *DepartmentHandler,
+	detectionHandler *DetectionHandler,
+	trackHandler *TrackHandler,
+	realtimeHandler *RealtimeHandler,
+	strikeHandler *StrikeHandler,
+	zoneHandler *ZoneHandler,
+	alertHandler *AlertHandler,
+	whitelistHandler *WhitelistHandler,
+	auditHandler *AuditHandler,
+	apiKeyHandler *ApiKeyHandler,
 ) *Router {
 	return &Router{
-		server: server,
+		server:            server,
+		tokenManager:      tokenManager,
+		permissionService: permissionService,
+		tenantService:     tenantService,
+		auditService:      auditService,
+		apiKeyService:     apiKeyService,
+		sessionService:    sessionService,
 		modules: []RouteModule{
+			authHandler,
 			userHandler,
 			menuHandler,
 			roleHandler,
 			deviceHandler,
+			tenantHandler,
+			departmentHandler,
+			detectionHandler,
+			trackHandler,
+			realtimeHandler,
+			strikeHandler,
+			zoneHandler,
+			alertHandler,
+			whitelistHandler,
+			auditHandler,
+			apiKeyHandler,
 		},
 	}
 }
@@ -41,25 +97,31 @@ func (r *Router) RegisterRoutes() {
 	// 创建 API 版本组
 	apiV1 := r.server.App.Group("/api/v1")
 
-	// 注册公开路由（不需要认证）
+	// 注册公开路由（不需要认证），必须在认证中间件之前注册
 	// publicRoutes := apiV1.Group("/public")
 	// publicRoutes.Get("/health", r.HealthCheck)
+	for _, module := range r.modules {
+		if public, ok := module.(PublicRouteModule); ok {
+			public.RegisterPublicRoutes(apiV1)
+		}
+	}
 
 	// 注册需要认证的路由
 	protectedRoutes := apiV1.Group("/")
 
-	protectedRoutes.Use(func(c *fiber.Ctx) error {
-		// 如何匹配路由是否有权限
-		c.Next()
-		log.Info(c.Route().Name, c.Route().Path, c.Route().Method)
+	protectedRoutes.Use(middlewares.AuthMiddleware(r.tokenManager, r.apiKeyService, r.sessionService))
+	// 子域名识别租户需要配置 TENANT_BASE_DOMAIN，如 example.com
+	protectedRoutes.Use(middlewares.TenantMiddleware(r.tenantService, os.Getenv("TENANT_BASE_DOMAIN")))
 
-		return nil
-	})
-	// protectedRoutes.Use(middlewares.AuthMiddleware())
-	// protectedRoutes.Use(middlewares.TenantMiddleware())
+	// 审计和权限校验需要知道目标路由名称，作为每个路由的首个处理器注册；
+	// 审计在权限校验之前，无权限的操作也会被记录
+	guardedRoutes := newGuardedRouter(protectedRoutes,
+		middlewares.AuditMiddleware(r.auditService),
+		middlewares.PermissionMiddleware(r.permissionService, authenticatedOnlyPrefixes...),
+	)
 
 	// 注册所有模块路由到受保护的路由组
 	for _, module := range r.modules {
-		module.RegisterRoutes(protectedRoutes)
+		module.RegisterRoutes(guardedRoutes)
 	}
 }
@@ -0,0 +1,142 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// StrikeHandler 打击指令处理器
+type StrikeHandler struct {
+	StrikeService services.StrikeService
+	CommonService services.CommonService
+}
+
+// RegisterRoutes 注册打击指令相关路由
+func (h *StrikeHandler) RegisterRoutes(router fiber.Router) {
+	strikeGroup := router.Group("/strikes").Name("打击管理.")
+
+	strikeGroup.Get("", h.GetStrikes).Name("获取打击指令列表")
+	strikeGroup.Post("", h.RequestStrike).Name("申请打击")
+	strikeGroup.Get("/:id<guid>", h.GetStrike).Name("获取打击指令详情")
+	strikeGroup.Post("/:id<guid>/approve", h.ApproveStrike).Name("批准打击")
+	strikeGroup.Post("/:id<guid>/reject", h.RejectStrike).Name("驳回打击")
+}
+
+// GetStrikes 获取打击指令列表
+func (h *StrikeHandler) GetStrikes(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取打击指令列表
+	strikes, err := h.StrikeService.GetStrikes(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取打击指令列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取打击指令列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(strikes))
+}
+
+// GetStrike 获取打击指令详情
+func (h *StrikeHandler) GetStrike(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	strikeUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "打击指令ID格式无效"))
+	}
+
+	// 获取打击指令
+	command, err := h.StrikeService.GetStrike(c.UserContext(), strikeUUID)
+	if err != nil {
+		return h.strikeErrorResponse(c, err, "获取打击指令失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(command))
+}
+
+// RequestStrike 申请打击
+func (h *StrikeHandler) RequestStrike(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.CreateStrikeRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建打击指令
+	command, err := h.StrikeService.RequestStrike(c.UserContext(), &req)
+	if err != nil {
+		return h.strikeErrorResponse(c, err, "申请打击失败")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(command))
+}
+
+// ApproveStrike 批准打击
+func (h *StrikeHandler) ApproveStrike(c *fiber.Ctx) error {
+	return h.decide(c, h.StrikeService.ApproveStrike, "批准打击失败")
+}
+
+// RejectStrike 驳回打击
+func (h *StrikeHandler) RejectStrike(c *fiber.Ctx) error {
+	return h.decide(c, h.StrikeService.RejectStrike, "驳回打击失败")
+}
+
+// decide 处理审批请求
+func (h *StrikeHandler) decide(c *fiber.Ctx, decision func(ctx context.Context, strikeId uuid.UUID, req *dto.StrikeDecisionRequest) (*models.StrikeCommandModel, error), failure string) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	strikeUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "打击指令ID格式无效"))
+	}
+
+	// 解析请求体，审批意见可为空
+	var req dto.StrikeDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := h.CommonService.ValidateBody(c, &req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+	}
+
+	command, err := decision(c.UserContext(), strikeUUID, &req)
+	if err != nil {
+		return h.strikeErrorResponse(c, err, failure)
+	}
+
+	return c.JSON(dto.SuccessResponse(command))
+}
+
+// strikeErrorResponse 返回打击指令操作失败响应
+func (h *StrikeHandler) strikeErrorResponse(c *fiber.Ctx, err error, failure string) error {
+	switch {
+	case errors.Is(err, services.ErrStrikeNotFound),
+		errors.Is(err, services.ErrDeviceNotFound),
+		errors.Is(err, services.ErrTrackNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+	case errors.Is(err, services.ErrStrikeNotConfigured):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	case errors.Is(err, services.ErrStrikeSelfApproval):
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse(fiber.StatusForbidden, err.Error()))
+	case errors.Is(err, services.ErrStrikeNotPending), errors.Is(err, services.ErrStrikeExpired):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse(fiber.StatusConflict, err.Error()))
+	default:
+		log.Errorf("%s: %v", failure, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, failure))
+	}
+}
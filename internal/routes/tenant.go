@@ -0,0 +1,154 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+	"xacms/internal/server/middlewares"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// TenantHandler 租户处理器
+type TenantHandler struct {
+	TenantService services.TenantService
+	CommonService services.CommonService
+}
+
+// RegisterRoutes 注册租户相关路由，仅平台用户可访问
+func (h *TenantHandler) RegisterRoutes(router fiber.Router) {
+	tenantGroup := router.Group("/tenants", middlewares.PlatformOnlyMiddleware()).Name("租户管理.")
+
+	tenantGroup.Get("", h.GetTenants).Name("获取租户列表")
+	tenantGroup.Post("", h.CreateTenant).Name("创建租户")
+	tenantGroup.Get("/:id<guid>", h.GetTenant).Name("获取租户详情")
+	tenantGroup.Put("/:id<guid>", h.UpdateTenant).Name("更新租户")
+	tenantGroup.Delete("/:id<guid>", h.DeleteTenant).Name("删除租户")
+}
+
+// GetTenants 获取租户列表
+func (h *TenantHandler) GetTenants(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取租户列表
+	tenants, err := h.TenantService.GetTenants(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取租户列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取租户列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(tenants))
+}
+
+// CreateTenant 创建租户
+func (h *TenantHandler) CreateTenant(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.CreateTenantRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建租户
+	tenant, err := h.TenantService.CreateTenant(c.UserContext(), req)
+	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "租户名称或编码已存在"))
+		}
+		log.Errorf("创建租户失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建租户失败"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(tenant))
+}
+
+// GetTenant 获取租户详情
+func (h *TenantHandler) GetTenant(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	tenantUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "租户ID格式无效"))
+	}
+
+	// 获取租户
+	var tenant models.TenantModel
+	if err := h.CommonService.GetItemByID(c.UserContext(), tenantUUID, &tenant); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, "租户不存在"))
+		}
+		log.Errorf("获取租户失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取租户失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(tenant))
+}
+
+// UpdateTenant 更新租户
+func (h *TenantHandler) UpdateTenant(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	tenantUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "租户ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.UpdateTenantRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 更新租户
+	tenant, err := h.TenantService.UpdateTenant(c.UserContext(), tenantUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrTenantNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "租户名称或编码已存在"))
+		}
+		log.Errorf("更新租户失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新租户失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(tenant))
+}
+
+// DeleteTenant 删除租户
+func (h *TenantHandler) DeleteTenant(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	tenantUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "租户ID格式无效"))
+	}
+
+	// 删除租户
+	if err := h.TenantService.DeleteTenant(c.UserContext(), tenantUUID); err != nil {
+		if errors.Is(err, services.ErrTenantNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrTenantInUse) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse(fiber.StatusConflict, err.Error()))
+		}
+		log.Errorf("删除租户失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除租户失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// TrackHandler 航迹处理器
+type TrackHandler struct {
+	TrackService  services.TrackService
+	CommonService services.CommonService
+}
+
+// RegisterRoutes 注册航迹相关路由
+func (h *TrackHandler) RegisterRoutes(router fiber.Router) {
+	trackGroup := router.Group("/tracks").Name("航迹管理.")
+
+	trackGroup.Get("", h.GetTracks).Name("获取航迹列表")
+	trackGroup.Get("/:id<guid>", h.GetTrack).Name("获取航迹详情")
+}
+
+// GetTracks 获取航迹列表
+func (h *TrackHandler) GetTracks(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.TrackQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取航迹列表
+	tracks, err := h.TrackService.GetTracks(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取航迹列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取航迹列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(tracks))
+}
+
+// GetTrack 获取航迹详情
+func (h *TrackHandler) GetTrack(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	trackUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "航迹ID格式无效"))
+	}
+
+	// 获取航迹
+	track, err := h.TrackService.GetTrack(c.UserContext(), trackUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrTrackNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取航迹失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取航迹失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(track))
+}
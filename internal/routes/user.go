@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"context"
+	"errors"
 	"xacms/internal/models"
 	"xacms/internal/routes/dto"
+	"xacms/internal/server/middlewares"
 	"xacms/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,8 +16,12 @@ import (
 
 // UserHandler 用户处理器
 type UserHandler struct {
-	UserService   services.UserService
-	CommonService services.CommonService
+	UserService         services.UserService
+	CommonService       services.CommonService
+	PermissionService   services.PermissionService
+	LoginAttemptService services.LoginAttemptService
+	TwoFactorService    services.TwoFactorService
+	SessionService      services.SessionService
 }
 
 // RegisterRoutes 注册用户相关路由
@@ -27,20 +34,42 @@ func (h *UserHandler) RegisterRoutes(router fiber.Router) {
 	userGroup.Put("/:id<guid>", h.UpdateUser).Name("更新用户")
 	userGroup.Delete("/:id<guid>", h.DeleteUser).Name("删除用户")
 	userGroup.Post("/:id<guid>/role", h.AssignRole).Name("分配角色")
+	userGroup.Post("/:id<guid>/department", h.AssignDepartment).Name("分配部门")
+	userGroup.Post("/:id<guid>/password/reset", h.ResetPassword).Name("重置密码")
+	userGroup.Post("/:id<guid>/unlock", h.UnlockUser).Name("解锁用户")
+	userGroup.Post("/:id<guid>/logout", h.ForceLogout).Name("强制下线")
+	userGroup.Post("/:id<guid>/2fa/reset", h.ResetTwoFactor).Name("重置双因素认证")
+
+	// 当前用户相关路由，只要求登录
+	meGroup := router.Group("/users/me").Name("个人中心.")
+
+	meGroup.Get("/permissions", h.GetMyPermissions).Name("获取我的权限")
+	meGroup.Post("/password", h.ChangePassword).Name("修改密码")
+	meGroup.Get("/logins", h.GetMyLogins).Name("获取我的登录记录")
+	meGroup.Get("/sessions", h.GetMySessions).Name("获取我的会话")
+	meGroup.Delete("/sessions/:id<guid>", h.RevokeMySession).Name("注销我的会话")
+	meGroup.Get("/2fa", h.GetTwoFactorStatus).Name("获取双因素认证状态")
+	meGroup.Post("/2fa/totp", h.SetupTwoFactor).Name("生成双因素认证密钥")
+	meGroup.Post("/2fa/totp/confirm", h.EnableTwoFactor).Name("启用双因素认证")
+	meGroup.Post("/2fa/recovery-codes", h.RegenerateRecoveryCodes).Name("重新生成恢复码")
+	meGroup.Delete("/2fa", h.DisableTwoFactor).Name("停用双因素认证")
 }
 
 // GetUsers 获取用户列表
 func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 	// 解析查询参数
 	var req dto.UserQueryRequest
-	err := h.CommonService.ValidateQuery(c, &req)
+	err := h.CommonService.ValidateListQuery(c, &req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
 	}
 
 	// 获取用户列表
-	users, err := h.UserService.GetUsers(req)
+	users, err := h.UserService.GetUsers(c.UserContext(), req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
 		log.Errorf("获取用户列表失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取用户列表失败"))
 	}
@@ -57,7 +86,7 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 	}
 
 	// 创建用户
-	user, err := h.UserService.CreateUser(req)
+	user, err := h.UserService.CreateUser(c.UserContext(), req)
 	if err != nil {
 		log.Errorf("创建用户失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建用户失败"))
@@ -78,7 +107,7 @@ func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 
 	// 获取用户
 	var user models.UserModel
-	if err := h.CommonService.GetItemByID(userUUID, &user); err != nil {
+	if err := h.CommonService.GetItemByID(c.UserContext(), userUUID, &user); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, "用户不存在"))
 		}
@@ -106,7 +135,7 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	}
 
 	// 更新用户
-	user, err := h.UserService.UpdateUser(userUUID, req)
+	user, err := h.UserService.UpdateUser(c.UserContext(), userUUID, req)
 	if err != nil {
 		log.Errorf("更新用户失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新用户失败"))
@@ -126,7 +155,7 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	}
 
 	// 删除用户
-	if err := h.CommonService.DeleteItemByID(&models.UserModel{}, userUUID); err != nil {
+	if err := h.CommonService.DeleteItemByID(c.UserContext(), &models.UserModel{}, userUUID); err != nil {
 		log.Errorf("删除用户失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除用户失败"))
 	}
@@ -151,11 +180,368 @@ func (h *UserHandler) AssignRole(c *fiber.Ctx) error {
 	}
 
 	// 分配角色
-	user, err := h.UserService.AssignRole(userUUID, req)
+	user, err := h.UserService.AssignRole(c.UserContext(), userUUID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
 		log.Errorf("分配角色失败: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "分配角色失败"))
 	}
 
 	return c.JSON(dto.SuccessResponse(user))
 }
+
+// AssignDepartment 分配部门
+func (h *UserHandler) AssignDepartment(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	userUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "用户ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.AssignDepartmentRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 分配部门
+	user, err := h.UserService.AssignDepartment(c.UserContext(), userUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) || errors.Is(err, services.ErrDepartmentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("分配部门失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "分配部门失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(user))
+}
+
+// GetMyPermissions 获取当前用户的菜单和按钮权限
+func (h *UserHandler) GetMyPermissions(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	permissions, err := h.PermissionService.GetUserPermissions(userID)
+	if err != nil {
+		log.Errorf("获取用户权限失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取用户权限失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(permissions))
+}
+
+// GetMyLogins 获取当前用户的登录记录
+func (h *UserHandler) GetMyLogins(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取登录记录
+	logins, err := h.LoginAttemptService.GetUserLogins(userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取登录记录失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取登录记录失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(logins))
+}
+
+// GetMySessions 获取当前用户的登录会话
+func (h *UserHandler) GetMySessions(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 获取会话列表
+	sessions, err := h.SessionService.GetUserSessions(userID, middlewares.GetClaims(c).Session)
+	if err != nil {
+		log.Errorf("获取会话列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取会话列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(sessions))
+}
+
+// RevokeMySession 注销当前用户的指定会话，会话的令牌随即失效
+func (h *UserHandler) RevokeMySession(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 验证 UUID 格式
+	sessionUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "会话ID格式无效"))
+	}
+
+	// 注销会话
+	if err := h.SessionService.RevokeSession(userID, sessionUUID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("注销会话失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "注销会话失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// ChangePassword 修改当前用户密码
+func (h *UserHandler) ChangePassword(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 解析请求体
+	var req dto.ChangePasswordRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 修改密码
+	// 当前用户由令牌确定，平台用户选择租户后仍需能找到自身，不按租户过滤
+	if err := h.UserService.ChangePassword(context.Background(), userID, req); err != nil {
+		if errors.Is(err, services.ErrOldPasswordMismatch) || errors.Is(err, services.ErrPasswordUnchanged) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("修改密码失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "修改密码失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// ResetPassword 重置用户密码
+func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	userUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "用户ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.ResetPasswordRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 重置密码
+	if err := h.UserService.ResetPassword(c.UserContext(), userUUID, req); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("重置密码失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "重置密码失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// UnlockUser 解除用户因连续登录失败导致的锁定
+func (h *UserHandler) UnlockUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	userUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "用户ID格式无效"))
+	}
+
+	// 解锁用户
+	if err := h.LoginAttemptService.Unlock(c.UserContext(), userUUID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("解锁用户失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "解锁用户失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// ForceLogout 强制用户下线，撤销其全部会话
+func (h *UserHandler) ForceLogout(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	userUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "用户ID格式无效"))
+	}
+
+	// 撤销会话
+	if err := h.SessionService.ForceLogout(c.UserContext(), userUUID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("强制下线失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "强制下线失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// ResetTwoFactor 重置用户的双因素认证
+func (h *UserHandler) ResetTwoFactor(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	userUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "用户ID格式无效"))
+	}
+
+	// 重置双因素认证
+	if err := h.TwoFactorService.Reset(c.UserContext(), userUUID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("重置双因素认证失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "重置双因素认证失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// GetTwoFactorStatus 获取当前用户的双因素认证状态
+func (h *UserHandler) GetTwoFactorStatus(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	status, err := h.TwoFactorService.GetStatus(userID)
+	if err != nil {
+		return twoFactorFailed(c, err, "获取双因素认证状态失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(status))
+}
+
+// SetupTwoFactor 为当前用户生成待确认的双因素认证密钥
+func (h *UserHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 解析请求体
+	var req dto.TwoFactorSetupRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 生成密钥
+	setup, err := h.TwoFactorService.Setup(userID, req)
+	if err != nil {
+		return twoFactorFailed(c, err, "生成双因素认证密钥失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(setup))
+}
+
+// EnableTwoFactor 确认验证码并启用当前用户的双因素认证
+func (h *UserHandler) EnableTwoFactor(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 解析请求体
+	var req dto.TwoFactorCodeRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 启用双因素认证
+	codes, err := h.TwoFactorService.Enable(userID, req)
+	if err != nil {
+		return twoFactorFailed(c, err, "启用双因素认证失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(codes))
+}
+
+// RegenerateRecoveryCodes 重新生成当前用户的恢复码
+func (h *UserHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 解析请求体
+	var req dto.TwoFactorCodeRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 重新生成恢复码
+	codes, err := h.TwoFactorService.RegenerateRecoveryCodes(userID, req)
+	if err != nil {
+		return twoFactorFailed(c, err, "重新生成恢复码失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(codes))
+}
+
+// DisableTwoFactor 停用当前用户的双因素认证
+func (h *UserHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	userID, ok := middlewares.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse(fiber.StatusUnauthorized, "未登录"))
+	}
+
+	// 解析请求体
+	var req dto.DisableTwoFactorRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 停用双因素认证
+	if err := h.TwoFactorService.Disable(userID, req); err != nil {
+		return twoFactorFailed(c, err, "停用双因素认证失败")
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// twoFactorFailed 返回双因素认证操作失败的响应
+func twoFactorFailed(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse(fiber.StatusForbidden, err.Error()))
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled), errors.Is(err, services.ErrTwoFactorNotEnabled):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse(fiber.StatusConflict, err.Error()))
+	case errors.Is(err, services.ErrPasswordMismatch), errors.Is(err, services.ErrInvalidTwoFactorCode), errors.Is(err, services.ErrTwoFactorNotPending):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+	log.Errorf("%s: %v", message, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, message))
+}
@@ -0,0 +1,182 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// WhitelistHandler 白名单处理器
+type WhitelistHandler struct {
+	WhitelistService services.WhitelistService
+	CommonService    services.CommonService
+}
+
+// RegisterRoutes 注册白名单相关路由
+func (h *WhitelistHandler) RegisterRoutes(router fiber.Router) {
+	whitelistGroup := router.Group("/whitelist").Name("白名单管理.")
+
+	whitelistGroup.Get("", h.GetEntries).Name("获取白名单列表")
+	whitelistGroup.Post("", h.CreateEntry).Name("创建白名单")
+	whitelistGroup.Post("/import", h.ImportEntries).Name("导入白名单")
+	whitelistGroup.Get("/:id<guid>", h.GetEntry).Name("获取白名单详情")
+	whitelistGroup.Put("/:id<guid>", h.UpdateEntry).Name("更新白名单")
+	whitelistGroup.Delete("/:id<guid>", h.DeleteEntry).Name("删除白名单")
+}
+
+// GetEntries 获取白名单列表
+func (h *WhitelistHandler) GetEntries(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取白名单列表
+	entries, err := h.WhitelistService.GetEntries(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取白名单列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取白名单列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(entries))
+}
+
+// CreateEntry 创建白名单
+func (h *WhitelistHandler) CreateEntry(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.CreateWhitelistEntryRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建白名单
+	entry, err := h.WhitelistService.CreateEntry(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrWhitelistValidity) || errors.Is(err, services.ErrWhitelistZoneNotFound) || errors.Is(err, services.ErrWhitelistSerialExists) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "序列号已在白名单中"))
+		}
+		log.Errorf("创建白名单失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建白名单失败"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(entry))
+}
+
+// ImportEntries 从上传的 CSV 文件导入白名单
+func (h *WhitelistHandler) ImportEntries(c *fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "请上传 CSV 文件"))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		log.Errorf("读取白名单文件失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "读取白名单文件失败"))
+	}
+	defer file.Close()
+
+	// 导入白名单
+	result, err := h.WhitelistService.ImportEntries(c.UserContext(), file)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWhitelistCSV) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("导入白名单失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "导入白名单失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(result))
+}
+
+// GetEntry 获取白名单详情
+func (h *WhitelistHandler) GetEntry(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	entryUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "白名单ID格式无效"))
+	}
+
+	// 获取白名单
+	entry, err := h.WhitelistService.GetEntry(c.UserContext(), entryUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrWhitelistEntryNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取白名单失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取白名单失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(entry))
+}
+
+// UpdateEntry 更新白名单
+func (h *WhitelistHandler) UpdateEntry(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	entryUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "白名单ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.UpdateWhitelistEntryRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 更新白名单
+	entry, err := h.WhitelistService.UpdateEntry(c.UserContext(), entryUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrWhitelistEntryNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrWhitelistValidity) || errors.Is(err, services.ErrWhitelistZoneNotFound) || errors.Is(err, services.ErrWhitelistSerialExists) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "序列号已在白名单中"))
+		}
+		log.Errorf("更新白名单失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新白名单失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(entry))
+}
+
+// DeleteEntry 删除白名单
+func (h *WhitelistHandler) DeleteEntry(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	entryUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "白名单ID格式无效"))
+	}
+
+	// 删除白名单
+	if err := h.WhitelistService.DeleteEntry(c.UserContext(), entryUUID); err != nil {
+		if errors.Is(err, services.ErrWhitelistEntryNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("删除白名单失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除白名单失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"errors"
+	"xacms/internal/routes/dto"
+	"xacms/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// ZoneHandler 区域处理器
+type ZoneHandler struct {
+	ZoneService   services.ZoneService
+	CommonService services.CommonService
+}
+
+// RegisterRoutes 注册区域相关路由
+func (h *ZoneHandler) RegisterRoutes(router fiber.Router) {
+	zoneGroup := router.Group("/zones").Name("区域管理.")
+
+	zoneGroup.Get("", h.GetZones).Name("获取区域列表")
+	zoneGroup.Post("", h.CreateZone).Name("创建区域")
+	zoneGroup.Get("/:id<guid>", h.GetZone).Name("获取区域详情")
+	zoneGroup.Put("/:id<guid>", h.UpdateZone).Name("更新区域")
+	zoneGroup.Delete("/:id<guid>", h.DeleteZone).Name("删除区域")
+	zoneGroup.Post("/:id<guid>/devices", h.AssignDevices).Name("分配区域设备")
+}
+
+// GetZones 获取区域列表
+func (h *ZoneHandler) GetZones(c *fiber.Ctx) error {
+	// 解析查询参数
+	var req dto.ListQueryRequest
+	if err := h.CommonService.ValidateListQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 获取区域列表
+	zones, err := h.ZoneService.GetZones(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("获取区域列表失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取区域列表失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(zones))
+}
+
+// CreateZone 创建区域
+func (h *ZoneHandler) CreateZone(c *fiber.Ctx) error {
+	// 解析请求体
+	var req dto.CreateZoneRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 创建区域
+	zone, err := h.ZoneService.CreateZone(c.UserContext(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGeometry) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "区域已存在"))
+		}
+		log.Errorf("创建区域失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "创建区域失败"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.SuccessResponse(zone))
+}
+
+// GetZone 获取区域详情
+func (h *ZoneHandler) GetZone(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	zoneUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "区域ID格式无效"))
+	}
+
+	// 获取区域
+	zone, err := h.ZoneService.GetZone(c.UserContext(), zoneUUID)
+	if err != nil {
+		if errors.Is(err, services.ErrZoneNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("获取区域失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "获取区域失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(zone))
+}
+
+// UpdateZone 更新区域
+func (h *ZoneHandler) UpdateZone(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	zoneUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "区域ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.UpdateZoneRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 更新区域
+	zone, err := h.ZoneService.UpdateZone(c.UserContext(), zoneUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrZoneNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrInvalidGeometry) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "区域已存在"))
+		}
+		log.Errorf("更新区域失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "更新区域失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(zone))
+}
+
+// DeleteZone 删除区域
+func (h *ZoneHandler) DeleteZone(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	zoneUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "区域ID格式无效"))
+	}
+
+	// 删除区域
+	if err := h.ZoneService.DeleteZone(c.UserContext(), zoneUUID); err != nil {
+		if errors.Is(err, services.ErrZoneNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		log.Errorf("删除区域失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "删除区域失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(nil))
+}
+
+// AssignDevices 设置区域关联的设备
+func (h *ZoneHandler) AssignDevices(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// 验证 UUID 格式
+	zoneUUID, err := uuid.Parse(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, "区域ID格式无效"))
+	}
+
+	// 解析请求体
+	var req dto.AssignZoneDevicesRequest
+	if err := h.CommonService.ValidateBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	// 分配设备
+	zone, err := h.ZoneService.AssignDevices(c.UserContext(), zoneUUID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrZoneNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+		if errors.Is(err, services.ErrZoneDeviceNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+		log.Errorf("分配区域设备失败: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse(fiber.StatusInternalServerError, "分配区域设备失败"))
+	}
+
+	return c.JSON(dto.SuccessResponse(zone))
+}
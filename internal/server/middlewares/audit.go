@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+	"xacms/internal/models"
+	"xacms/internal/pkg/audit"
+
+	"github.com/bytedance/sonic"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// AuditLogger 审计日志记录器
+type AuditLogger interface {
+	// LogAudit 保存审计日志，失败不影响请求结果
+	LogAudit(entry *models.AuditLogModel) error
+}
+
+// AuditMiddleware 审计中间件，记录新增、修改和删除类请求的操作人、结果和数据变更
+//
+// 与 PermissionMiddleware 相同，必须作为路由处理器注册才能获取路由名称；
+// 注册在权限校验之前时，无权限的操作也会被记录。
+func AuditMiddleware(logger AuditLogger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+
+		// 处理器使用 c.UserContext() 访问数据库时，数据变更写入记录器
+		recorder := &audit.Recorder{}
+		c.SetUserContext(audit.WithRecorder(c.UserContext(), recorder))
+
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		message := ""
+		if err != nil {
+			// 处理器返回的错误由全局错误处理器转换为响应，此时尚未写入状态码
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+			message = err.Error()
+		} else if status >= fiber.StatusBadRequest {
+			message = responseMessage(c.Response().Body())
+		}
+
+		entry := &models.AuditLogModel{
+			RouteName:  c.Route().Name,
+			Method:     c.Method(),
+			Path:       truncate(c.Path(), 255),
+			TargetID:   truncate(c.Params("id"), 64),
+			Status:     status,
+			Success:    status < fiber.StatusBadRequest,
+			Error:      truncate(message, 255),
+			IP:         c.IP(),
+			UserAgent:  truncate(c.Get(fiber.HeaderUserAgent), 255),
+			DurationMs: time.Since(start).Milliseconds(),
+			Changes:    recorder.Changes(),
+		}
+		if userID, ok := GetUserID(c); ok {
+			entry.UserID = &userID
+		}
+		if tenantID, ok := GetTenantID(c); ok {
+			entry.TenantID = &tenantID
+		} else if claims := GetClaims(c); claims != nil {
+			entry.TenantID = claims.TenantID
+		}
+		// 新增数据时路径中没有ID，使用新增的第一条数据的主键
+		if entry.TargetID == "" {
+			for _, change := range entry.Changes {
+				if change.Action == audit.ActionCreate && change.ID != "" {
+					entry.TargetID = truncate(change.ID, 64)
+					break
+				}
+			}
+		}
+
+		if logErr := logger.LogAudit(entry); logErr != nil {
+			log.Errorf("保存审计日志失败: %v", logErr)
+		}
+		return err
+	}
+}
+
+// responseMessage 读取错误响应中的提示信息
+func responseMessage(body []byte) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+	if err := sonic.Unmarshal(body, &response); err != nil {
+		return ""
+	}
+	return response.Message
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
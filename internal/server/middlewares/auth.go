@@ -1,17 +1,49 @@
 package middlewares
 
 import (
+	"errors"
 	"strings"
+	"xacms/internal/models"
+	"xacms/internal/pkg/identity"
+	"xacms/internal/pkg/tenant"
+	"xacms/internal/pkg/token"
+	"xacms/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
 )
 
-// AuthMiddleware JWT认证中间件
-func AuthMiddleware() fiber.Handler {
+// ApiKeyAuthenticator API密钥认证器
+type ApiKeyAuthenticator interface {
+	// AuthenticateApiKey 校验API密钥，密钥无效、已过期或所有者被禁用时返回 false
+	AuthenticateApiKey(key, ip string) (*models.ApiKeyModel, bool, error)
+}
+
+// SessionValidator 登录会话校验器
+type SessionValidator interface {
+	// ValidateSession 校验令牌所属会话未撤销、未过期，会话无效时返回 false
+	ValidateSession(claims *token.Claims, ip string) (bool, error)
+}
+
+// AuthMiddleware 认证中间件，支持 JWT 访问令牌和 X-API-Key 头传递的API密钥
+//
+// 访问令牌所属的登录会话被撤销后，令牌即使未过期也会被拒绝。
+// 使用API密钥时以密钥所有者的身份访问，租户固定为密钥所属租户，密钥存入 c.Locals("api_key")。
+func AuthMiddleware(tokenManager *token.Manager, apiKeys ApiKeyAuthenticator, sessions SessionValidator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if key := c.Get("X-API-Key"); key != "" {
+			return authenticateApiKey(c, apiKeys, key)
+		}
+
 		// 获取Authorization头
 		authHeader := c.Get("Authorization")
 
+		// 浏览器的 WebSocket 和 EventSource 无法设置请求头，长连接请求允许通过查询参数传递令牌
+		if authHeader == "" && utils.IsStreamRequest(c) && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
+
 		if authHeader == "" {
 			return c.Status(401).JSON(fiber.Map{
 				"code":    401,
@@ -28,58 +60,272 @@ func AuthMiddleware() fiber.Handler {
 		}
 
 		// 提取token
-		token := strings.TrimPrefix(authHeader, "Bearer ")
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// TODO: 验证JWT token
-		// 这里你需要实现JWT token验证逻辑
-		if token == "" {
+		// 验证签名、有效期及撤销状态
+		claims, err := tokenManager.Parse(tokenString, token.TypeAccess)
+		if err != nil {
+			message := "Invalid token"
+			if errors.Is(err, token.ErrExpiredToken) {
+				message = "Token expired"
+			}
 			return c.Status(401).JSON(fiber.Map{
 				"code":    401,
-				"message": "Invalid token",
+				"message": message,
 			})
 		}
 
-		// 将用户信息存储到上下文中
-		// c.Locals("user_id", userID)
-		// c.Locals("tenant_id", tenantID)
+		active, err := sessions.ValidateSession(claims, c.IP())
+		if err != nil {
+			log.Errorf("校验会话失败: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"code":    500,
+				"message": "Session check failed",
+			})
+		}
 
+		if !active {
+			return c.Status(401).JSON(fiber.Map{
+				"code":    401,
+				"message": "Session revoked",
+			})
+		}
+
+		setClaims(c, claims)
 		return c.Next()
 	}
 }
 
-// TenantMiddleware 多租户中间件
-func TenantMiddleware() fiber.Handler {
+// authenticateApiKey 校验API密钥，并按密钥所有者构造令牌载荷
+func authenticateApiKey(c *fiber.Ctx, apiKeys ApiKeyAuthenticator, key string) error {
+	apiKey, ok, err := apiKeys.AuthenticateApiKey(key, c.IP())
+	if err != nil {
+		log.Errorf("API密钥认证失败: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"code":    500,
+			"message": "API key authentication failed",
+		})
+	}
+
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"code":    401,
+			"message": "Invalid API key",
+		})
+	}
+
+	c.Locals("api_key", apiKey)
+	setClaims(c, &token.Claims{
+		UserID:   apiKey.UserID,
+		RoleID:   apiKey.User.RoleID,
+		TenantID: apiKey.TenantID,
+		Type:     token.TypeApiKey,
+	})
+	return c.Next()
+}
+
+// setClaims 将用户信息存储到上下文中
+func setClaims(c *fiber.Ctx, claims *token.Claims) {
+	c.Locals("claims", claims)
+	c.Locals("user_id", claims.UserID)
+	if claims.RoleID != nil {
+		c.Locals("role_id", *claims.RoleID)
+	}
+	c.SetUserContext(identity.WithUserID(c.UserContext(), claims.UserID))
+}
+
+// GetClaims 获取当前请求的令牌载荷，未认证时返回 nil
+func GetClaims(c *fiber.Ctx) *token.Claims {
+	claims, _ := c.Locals("claims").(*token.Claims)
+	return claims
+}
+
+// GetApiKey 获取当前请求使用的API密钥，使用令牌认证时返回 nil
+func GetApiKey(c *fiber.Ctx) *models.ApiKeyModel {
+	apiKey, _ := c.Locals("api_key").(*models.ApiKeyModel)
+	return apiKey
+}
+
+// GetUserID 获取当前请求的用户ID
+func GetUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	return userID, ok
+}
+
+// GetRoleID 获取当前请求的角色ID
+func GetRoleID(c *fiber.Ctx) (uuid.UUID, bool) {
+	roleID, ok := c.Locals("role_id").(uuid.UUID)
+	return roleID, ok
+}
+
+// PermissionChecker 权限校验器
+type PermissionChecker interface {
+	HasPermission(userID uuid.UUID, routeName string) (bool, error)
+}
+
+// PermissionMiddleware 路由权限中间件
+//
+// 必须作为路由处理器注册（而不是通过 Use 注册），才能通过 c.Route().Name 获取当前路由名称。
+// 名称以 skipPrefixes 中任一前缀开头的路由只要求登录，其余路由不接受仅用于绑定双因素认证的令牌。
+// API密钥只能访问授权范围内且所有者有权访问的路由，不能访问只要求登录的路由。
+func PermissionMiddleware(checker PermissionChecker, skipPrefixes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// 从头部或子域名获取租户信息
-		tenantID := c.Get("X-Tenant-ID")
-		if tenantID == "" {
-			// 也可以从子域名中提取租户信息
-			// host := c.Get("Host")
-			// tenantID = extractTenantFromHost(host)
+		routeName := c.Route().Name
+		apiKey := GetApiKey(c)
+		for _, prefix := range skipPrefixes {
+			if strings.HasPrefix(routeName, prefix) {
+				if apiKey != nil {
+					return c.Status(403).JSON(fiber.Map{
+						"code":    403,
+						"message": "Not available for API keys",
+					})
+				}
+				return c.Next()
+			}
+		}
+
+		if apiKey != nil && !apiKey.HasScope(routeName) {
+			return c.Status(403).JSON(fiber.Map{
+				"code":    403,
+				"message": "API key scope denied",
+			})
+		}
+
+		userID, ok := GetUserID(c)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{
+				"code":    401,
+				"message": "Unauthorized",
+			})
+		}
+
+		// 角色要求双因素认证而用户尚未启用时，令牌只能访问无需授权的路由
+		if claims := GetClaims(c); claims != nil && claims.Scope == token.ScopeTwoFactorSetup {
+			return c.Status(403).JSON(fiber.Map{
+				"code":    403,
+				"message": "Two-factor authentication setup required",
+			})
 		}
 
-		if tenantID == "" {
-			return c.Status(400).JSON(fiber.Map{
-				"code":    400,
-				"message": "Missing tenant information",
+		allowed, err := checker.HasPermission(userID, routeName)
+		if err != nil {
+			log.Errorf("校验权限失败: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"code":    500,
+				"message": "Permission check failed",
 			})
 		}
 
+		if !allowed {
+			return c.Status(403).JSON(fiber.Map{
+				"code":    403,
+				"message": "Permission denied",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// TenantResolver 租户解析器
+type TenantResolver interface {
+	// ResolveTenant 按租户ID或编码查找启用的租户，租户不存在或已禁用时返回 false
+	ResolveTenant(identifier string) (uuid.UUID, bool, error)
+}
+
+// TenantMiddleware 多租户中间件，必须在 AuthMiddleware 之后注册
+//
+// 租户用户固定使用令牌中的租户，请求其他租户时拒绝访问；
+// 平台用户可通过 X-Tenant-ID 头（租户ID或编码）或子域名选择租户，未指定时不限定租户。
+// 确定租户后写入 c.UserContext()，数据库操作据此自动按租户隔离。
+func TenantMiddleware(resolver TenantResolver, baseDomain string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// 从头部或子域名获取租户信息
+		requested := c.Get("X-Tenant-ID")
+		if requested == "" {
+			requested = extractTenantFromHost(c.Hostname(), baseDomain)
+		}
+
+		var tenantID *uuid.UUID
+		if claims := GetClaims(c); claims != nil && claims.TenantID != nil {
+			tenantID = claims.TenantID
+			if requested == "" {
+				requested = tenantID.String()
+			}
+		}
+
+		if requested == "" {
+			// 平台用户未指定租户
+			return c.Next()
+		}
+
 		// 验证租户是否存在且有效
-		// TODO: 实现租户验证逻辑
+		resolved, ok, err := resolver.ResolveTenant(requested)
+		if err != nil {
+			log.Errorf("解析租户失败: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"code":    500,
+				"message": "Tenant resolution failed",
+			})
+		}
+
+		if !ok || (tenantID != nil && *tenantID != resolved) {
+			return c.Status(403).JSON(fiber.Map{
+				"code":    403,
+				"message": "Invalid tenant",
+			})
+		}
 
 		// 将租户ID存储到上下文中
-		c.Locals("tenant_id", tenantID)
+		c.Locals("tenant_id", resolved)
+		c.SetUserContext(tenant.WithTenant(c.UserContext(), resolved))
 
 		return c.Next()
 	}
 }
 
-// LoggerMiddleware 自定义日志中间件
-func LoggerMiddleware() fiber.Handler {
+// extractTenantFromHost 从子域名提取租户编码，如 baseDomain 为 example.com 时 acme.example.com 对应 acme
+func extractTenantFromHost(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+
+	host = strings.ToLower(host)
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+
+	subdomain := strings.TrimSuffix(host, suffix)
+	if subdomain == "" || strings.Contains(subdomain, ".") {
+		return ""
+	}
+	return subdomain
+}
+
+// GetTenantID 获取当前请求限定的租户ID
+func GetTenantID(c *fiber.Ctx) (uuid.UUID, bool) {
+	tenantID, ok := c.Locals("tenant_id").(uuid.UUID)
+	return tenantID, ok
+}
+
+// PlatformOnlyMiddleware 只允许不属于任何租户的平台用户访问
+func PlatformOnlyMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// TODO: 实现自定义日志记录
-		// 可以记录请求信息、响应时间等
+		claims := GetClaims(c)
+		if claims == nil {
+			return c.Status(401).JSON(fiber.Map{
+				"code":    401,
+				"message": "Unauthorized",
+			})
+		}
+
+		if claims.TenantID != nil {
+			return c.Status(403).JSON(fiber.Map{
+				"code":    403,
+				"message": "Platform administrators only",
+			})
+		}
 
 		return c.Next()
 	}
@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"xacms/internal/models"
+	"xacms/internal/pkg/tenant"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// DeviceAuthenticator 设备认证器
+type DeviceAuthenticator interface {
+	// AuthenticateDevice 校验设备接入密钥，设备不存在或密钥错误时返回 false
+	AuthenticateDevice(deviceID uuid.UUID, key string) (*models.DeviceModel, bool, error)
+}
+
+// DeviceAuthMiddleware 设备认证中间件，用于设备上报数据的接口
+//
+// 设备通过 X-Device-ID 和 X-Device-Key 头认证，也可以使用绑定该设备的API密钥（X-API-Key 头）认证，
+// 认证通过后设备存入 c.Locals("device")，并按设备所属租户限定数据库操作。
+func DeviceAuthMiddleware(authenticator DeviceAuthenticator, apiKeys ApiKeyAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if key := c.Get("X-API-Key"); key != "" {
+			return authenticateDeviceApiKey(c, apiKeys, key)
+		}
+
+		deviceID, err := uuid.Parse(c.Get("X-Device-ID"))
+		key := c.Get("X-Device-Key")
+		if err != nil || key == "" {
+			return c.Status(401).JSON(fiber.Map{
+				"code":    401,
+				"message": "Missing device credentials",
+			})
+		}
+
+		device, ok, err := authenticator.AuthenticateDevice(deviceID, key)
+		if err != nil {
+			log.Errorf("设备认证失败: %v", err)
+			return c.Status(500).JSON(fiber.Map{
+				"code":    500,
+				"message": "Device authentication failed",
+			})
+		}
+
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{
+				"code":    401,
+				"message": "Invalid device credentials",
+			})
+		}
+
+		setDevice(c, device)
+		return c.Next()
+	}
+}
+
+// authenticateDeviceApiKey 校验API密钥，只接受绑定了设备且授权范围包含当前接口的密钥
+func authenticateDeviceApiKey(c *fiber.Ctx, apiKeys ApiKeyAuthenticator, key string) error {
+	apiKey, ok, err := apiKeys.AuthenticateApiKey(key, c.IP())
+	if err != nil {
+		log.Errorf("API密钥认证失败: %v", err)
+		return c.Status(500).JSON(fiber.Map{
+			"code":    500,
+			"message": "Device authentication failed",
+		})
+	}
+
+	if !ok || apiKey.Device == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"code":    401,
+			"message": "Invalid device credentials",
+		})
+	}
+
+	// 设备接口不经过权限中间件，需要在此校验授权范围
+	if !apiKey.HasScope(c.Route().Name) {
+		return c.Status(403).JSON(fiber.Map{
+			"code":    403,
+			"message": "API key scope denied",
+		})
+	}
+
+	c.Locals("api_key", apiKey)
+	setDevice(c, apiKey.Device)
+	return c.Next()
+}
+
+// setDevice 将已认证的设备存储到上下文中
+func setDevice(c *fiber.Ctx, device *models.DeviceModel) {
+	c.Locals("device", device)
+	if device.TenantID != nil {
+		c.SetUserContext(tenant.WithTenant(c.UserContext(), *device.TenantID))
+	}
+}
+
+// GetDevice 获取当前请求已认证的设备
+func GetDevice(c *fiber.Ctx) *models.DeviceModel {
+	device, _ := c.Locals("device").(*models.DeviceModel)
+	return device
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"xacms/internal/utils"
+
 	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
@@ -23,6 +25,8 @@ func NewFiberServer() *FiberServer {
 
 	// 设置压缩中间件
 	app.Use(compress.New(compress.Config{
+		// 压缩会缓冲响应，跳过 WebSocket 和 SSE 长连接
+		Next:  utils.IsStreamRequest,
 		Level: compress.LevelBestCompression, // 2
 	}))
 
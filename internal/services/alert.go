@@ -0,0 +1,780 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/event"
+	"xacms/internal/pkg/identity"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAlertRuleNotFound       = errors.New("告警规则不存在")
+	ErrAlertRuleZoneNotFound   = errors.New("告警规则关联的区域不存在")
+	ErrAlertRuleDeviceNotFound = errors.New("告警规则关联的设备不存在")
+	ErrAlertRuleThreshold      = errors.New("该告警条件需要大于 0 的阈值")
+	ErrAlertNotFound           = errors.New("告警不存在")
+	ErrAlertNotOpen            = errors.New("告警已确认或已关闭")
+	ErrAlertClosed             = errors.New("告警已关闭")
+)
+
+// AlertService 告警服务接口
+type AlertService interface {
+	Start()
+	Stop()
+	GetRules(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.AlertRuleModel], error)
+	GetRule(ctx context.Context, ruleId uuid.UUID) (*models.AlertRuleModel, error)
+	CreateRule(ctx context.Context, req dto.CreateAlertRuleRequest) (*models.AlertRuleModel, error)
+	UpdateRule(ctx context.Context, ruleId uuid.UUID, req dto.UpdateAlertRuleRequest) (*models.AlertRuleModel, error)
+	DeleteRule(ctx context.Context, ruleId uuid.UUID) error
+	GetAlerts(ctx context.Context, req dto.AlertQueryRequest) (*dto.PaginatedResponse[models.AlertModel], error)
+	GetAlert(ctx context.Context, alertId uuid.UUID) (*models.AlertModel, error)
+	AcknowledgeAlert(ctx context.Context, alertId uuid.UUID, req dto.AlertActionRequest) (*models.AlertModel, error)
+	CloseAlert(ctx context.Context, alertId uuid.UUID, req dto.AlertActionRequest) (*models.AlertModel, error)
+}
+
+// alertInput 待评估的事件，侦测事件和设备状态变化二选一
+type alertInput struct {
+	tenantID  *uuid.UUID
+	time      time.Time
+	detection *models.DetectionEventModel
+	status    *event.DeviceStatusPayload
+}
+
+// presenceTarget 设备侦测到的目标
+type presenceTarget struct {
+	deviceID  uuid.UUID
+	targetKey string
+}
+
+// presence 目标在区域内的停留状态
+type presence struct {
+	enteredAt time.Time
+	lastSeen  time.Time
+}
+
+// alertService 告警服务实现
+type alertService struct {
+	db            *gorm.DB
+	commonService CommonService
+	bus           *event.Bus
+
+	queueSize     int
+	presenceGap   time.Duration
+	cleanInterval time.Duration
+
+	// 各租户已启用规则的缓存，规则变化时清空，键为租户ID（无租户时为 uuid.Nil）
+	mu      sync.RWMutex
+	rules   map[uuid.UUID][]models.AlertRuleModel
+	version int
+
+	// 目标所在区域，仅由后台处理协程访问
+	presences map[presenceTarget]map[uuid.UUID]*presence
+
+	queue       chan alertInput
+	unsubscribe []func()
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewAlertService 创建告警服务实例
+//
+// 配置项：ALERT_QUEUE_SIZE 待评估事件队列长度，ALERT_PRESENCE_GAP 目标超过该时长未在区域内出现时视为离开，
+// ALERT_CLEAN_INTERVAL 清理离开区域目标的间隔。
+func NewAlertService(db *gorm.DB, commonService CommonService, bus *event.Bus) AlertService {
+	return &alertService{
+		db:            db,
+		commonService: commonService,
+		bus:           bus,
+		queueSize:     max(utils.EnvInt("ALERT_QUEUE_SIZE", 1024), 1),
+		presenceGap:   utils.EnvDuration("ALERT_PRESENCE_GAP", time.Minute),
+		cleanInterval: utils.EnvDuration("ALERT_CLEAN_INTERVAL", 30*time.Second),
+		rules:         make(map[uuid.UUID][]models.AlertRuleModel),
+		presences:     make(map[presenceTarget]map[uuid.UUID]*presence),
+	}
+}
+
+// alertRuleQueryOptions 告警规则列表查询选项
+var alertRuleQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"name":       {Column: "name", Type: FieldString},
+		"condition":  {Column: "condition", Type: FieldString},
+		"severity":   {Column: "severity", Type: FieldString},
+		"enabled":    {Column: "enabled", Type: FieldBool},
+		"zone_id":    {Column: "zone_id", Type: FieldUUID},
+		"device_id":  {Column: "device_id", Type: FieldUUID},
+		"created_at": {Column: "created_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"name":       "name",
+		"severity":   "severity",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	KeywordFields: []string{"name", "remark"},
+	DefaultSort:   "created_at DESC",
+}
+
+// alertQueryOptions 告警列表查询选项
+var alertQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"rule_id":    {Column: "rule_id", Type: FieldUUID},
+		"condition":  {Column: "condition", Type: FieldString},
+		"severity":   {Column: "severity", Type: FieldString},
+		"status":     {Column: "status", Type: FieldString},
+		"device_id":  {Column: "device_id", Type: FieldUUID},
+		"zone_id":    {Column: "zone_id", Type: FieldUUID},
+		"target_key": {Column: "target_key", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"first_seen_at": "first_seen_at",
+		"last_seen_at":  "last_seen_at",
+		"severity":      "severity",
+		"count":         "count",
+	},
+	KeywordFields: []string{"rule_name", "message", "target_key"},
+	DefaultSort:   "last_seen_at DESC",
+}
+
+// Start 订阅侦测事件和设备状态变化并启动规则评估
+func (s *alertService) Start() {
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.queue = make(chan alertInput, s.queueSize)
+
+	// 与航迹聚合相同，放入队列后由后台统一处理，队列满时阻塞发布者形成背压
+	enqueue := func(input alertInput) {
+		select {
+		case s.queue <- input:
+		case <-ctx.Done():
+		}
+	}
+	s.unsubscribe = []func(){
+		s.bus.Subscribe(event.TopicDetection, func(e event.Event) {
+			if detection, ok := e.Payload.(models.DetectionEventModel); ok {
+				enqueue(alertInput{tenantID: e.TenantID, time: detection.Timestamp, detection: &detection})
+			}
+		}),
+		s.bus.Subscribe(event.TopicDeviceStatus, func(e event.Event) {
+			if status, ok := e.Payload.(event.DeviceStatusPayload); ok {
+				enqueue(alertInput{tenantID: e.TenantID, time: e.Time, status: &status})
+			}
+		}),
+	}
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.cleanInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.drain()
+				return
+			case input := <-s.queue:
+				s.evaluate(input)
+			case <-ticker.C:
+				s.cleanPresences()
+			}
+		}
+	}()
+
+	log.Infof("告警规则评估已启动")
+}
+
+// Stop 取消订阅并等待队列中的事件评估完毕
+func (s *alertService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	for _, unsubscribe := range s.unsubscribe {
+		unsubscribe()
+	}
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+}
+
+// GetRules 获取告警规则列表
+func (s *alertService) GetRules(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.AlertRuleModel], error) {
+	return QueryList[models.AlertRuleModel](s.db.WithContext(ctx).Model(&models.AlertRuleModel{}), &req, alertRuleQueryOptions)
+}
+
+// GetRule 获取告警规则详情
+func (s *alertService) GetRule(ctx context.Context, ruleId uuid.UUID) (*models.AlertRuleModel, error) {
+	var rule models.AlertRuleModel
+	if err := s.commonService.GetItemByID(ctx, ruleId, &rule); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrAlertRuleNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRule 创建告警规则
+func (s *alertService) CreateRule(ctx context.Context, req dto.CreateAlertRuleRequest) (*models.AlertRuleModel, error) {
+	rule := &models.AlertRuleModel{
+		Name:      req.Name,
+		Condition: req.Condition,
+		Severity:  req.Severity,
+		Enabled:   true,
+		Remark:    req.Remark,
+		ZoneID:    req.ZoneID,
+		DeviceID:  req.DeviceID,
+		Threshold: req.Threshold,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return rule, nil
+}
+
+// UpdateRule 更新告警规则
+func (s *alertService) UpdateRule(ctx context.Context, ruleId uuid.UUID, req dto.UpdateAlertRuleRequest) (*models.AlertRuleModel, error) {
+	rule, err := s.GetRule(ctx, ruleId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+
+	if req.Condition != nil {
+		rule.Condition = *req.Condition
+	}
+
+	if req.Severity != nil {
+		rule.Severity = *req.Severity
+	}
+
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if req.Remark != nil {
+		rule.Remark = *req.Remark
+	}
+
+	if req.ZoneID != nil {
+		rule.ZoneID = req.ZoneID
+		if *req.ZoneID == uuid.Nil {
+			rule.ZoneID = nil
+		}
+	}
+
+	if req.DeviceID != nil {
+		rule.DeviceID = req.DeviceID
+		if *req.DeviceID == uuid.Nil {
+			rule.DeviceID = nil
+		}
+	}
+
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Save(rule).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return rule, nil
+}
+
+// DeleteRule 删除告警规则，已产生的告警保留
+func (s *alertService) DeleteRule(ctx context.Context, ruleId uuid.UUID) error {
+	rule, err := s.GetRule(ctx, ruleId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Delete(rule).Error; err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// GetAlerts 获取告警列表，支持按首次触发时间过滤
+func (s *alertService) GetAlerts(ctx context.Context, req dto.AlertQueryRequest) (*dto.PaginatedResponse[models.AlertModel], error) {
+	query := s.db.WithContext(ctx).Model(&models.AlertModel{})
+
+	if req.StartTime != "" {
+		start, err := time.Parse(time.RFC3339, req.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: start_time 格式无效", ErrInvalidQuery)
+		}
+		query = query.Where("first_seen_at >= ?", start.UTC())
+	}
+
+	if req.EndTime != "" {
+		end, err := time.Parse(time.RFC3339, req.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: end_time 格式无效", ErrInvalidQuery)
+		}
+		query = query.Where("first_seen_at <= ?", end.UTC())
+	}
+
+	return QueryList[models.AlertModel](query, &req.ListQueryRequest, alertQueryOptions)
+}
+
+// GetAlert 获取告警详情
+func (s *alertService) GetAlert(ctx context.Context, alertId uuid.UUID) (*models.AlertModel, error) {
+	var alert models.AlertModel
+	if err := s.commonService.GetItemByID(ctx, alertId, &alert); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// AcknowledgeAlert 确认告警，仅未处理的告警可以确认
+func (s *alertService) AcknowledgeAlert(ctx context.Context, alertId uuid.UUID, req dto.AlertActionRequest) (*models.AlertModel, error) {
+	alert, err := s.GetAlert(ctx, alertId)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _ := identity.UserIDFromContext(ctx)
+	now := time.Now().UTC()
+	updates := map[string]any{
+		"status":          models.AlertStatusAcknowledged,
+		"acknowledged_by": userID,
+		"acknowledged_at": now,
+		"note":            req.Note,
+	}
+	if err := s.update(s.db.WithContext(ctx), alert, updates, models.AlertStatusOpen); err != nil {
+		if errors.Is(err, errAlertStatusChanged) {
+			return nil, ErrAlertNotOpen
+		}
+		return nil, err
+	}
+	return alert, nil
+}
+
+// CloseAlert 关闭告警，未处理和已确认的告警均可关闭
+func (s *alertService) CloseAlert(ctx context.Context, alertId uuid.UUID, req dto.AlertActionRequest) (*models.AlertModel, error) {
+	alert, err := s.GetAlert(ctx, alertId)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _ := identity.UserIDFromContext(ctx)
+	now := time.Now().UTC()
+	updates := map[string]any{
+		"status":    models.AlertStatusClosed,
+		"closed_by": userID,
+		"closed_at": now,
+		"note":      req.Note,
+	}
+	if err := s.update(s.db.WithContext(ctx), alert, updates, models.AlertStatusOpen, models.AlertStatusAcknowledged); err != nil {
+		if errors.Is(err, errAlertStatusChanged) {
+			return nil, ErrAlertClosed
+		}
+		return nil, err
+	}
+	return alert, nil
+}
+
+// errAlertStatusChanged 告警状态已不满足更新条件
+var errAlertStatusChanged = errors.New("告警状态已变化")
+
+// update 以当前状态为条件更新告警，成功后重新加载并发布事件
+func (s *alertService) update(db *gorm.DB, alert *models.AlertModel, updates map[string]any, from ...models.AlertStatus) error {
+	result := db.Model(&models.AlertModel{}).Where("id = ? AND status IN ?", alert.ID, from).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errAlertStatusChanged
+	}
+
+	if err := db.First(alert, "id = ?", alert.ID).Error; err != nil {
+		return err
+	}
+	s.publish(alert)
+	return nil
+}
+
+// validateRule 校验规则阈值以及关联的区域和设备
+func (s *alertService) validateRule(ctx context.Context, rule *models.AlertRuleModel) error {
+	if rule.Condition.UsesThreshold() && rule.Threshold <= 0 {
+		return ErrAlertRuleThreshold
+	}
+
+	// 非区域条件不限定区域
+	if !rule.Condition.UsesZone() {
+		rule.ZoneID = nil
+	}
+
+	if rule.ZoneID != nil {
+		var zone models.ZoneModel
+		if err := s.commonService.GetItemByID(ctx, *rule.ZoneID, &zone); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrAlertRuleZoneNotFound
+			}
+			return err
+		}
+	}
+
+	if rule.DeviceID != nil {
+		var device models.DeviceModel
+		if err := s.commonService.GetItemByID(ctx, *rule.DeviceID, &device); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrAlertRuleDeviceNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// drain 处理队列中剩余的事件
+func (s *alertService) drain() {
+	for {
+		select {
+		case input := <-s.queue:
+			s.evaluate(input)
+		default:
+			return
+		}
+	}
+}
+
+// evaluate 使用租户的已启用规则评估事件
+func (s *alertService) evaluate(input alertInput) {
+	rules, err := s.loadRules(input.tenantID)
+	if err != nil {
+		log.Errorf("加载告警规则失败: %v", err)
+		return
+	}
+
+	if input.detection != nil {
+		s.evaluateDetection(rules, input)
+	}
+	if input.status != nil {
+		s.evaluateStatus(rules, input)
+	}
+}
+
+// evaluateDetection 评估侦测事件，友方目标不触发告警
+func (s *alertService) evaluateDetection(rules []models.AlertRuleModel, input alertInput) {
+	detection := input.detection
+	targetKey := models.TrackKey(detection)
+	if detection.Friendly {
+		// 不记录友方目标的停留，离开允许区域或有效期后所在区域均视为新进入
+		delete(s.presences, presenceTarget{deviceID: detection.DeviceID, targetKey: targetKey})
+		return
+	}
+	entered, inside := s.updatePresence(detection, targetKey)
+
+	// 无目标标识时以侦测事件为告警对象
+	object := targetKey
+	if object == "" {
+		object = "detection:" + detection.ID.String()
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.DeviceID != nil && *rule.DeviceID != detection.DeviceID {
+			continue
+		}
+
+		alert := models.AlertModel{
+			TenantID:    input.tenantID,
+			DeviceID:    &detection.DeviceID,
+			DetectionID: &detection.ID,
+			TargetKey:   targetKey,
+		}
+
+		switch rule.Condition {
+		case models.AlertConditionZoneEnter:
+			for _, zone := range entered {
+				if rule.ZoneID == nil || *rule.ZoneID == zone.ID {
+					alert := alert
+					alert.ZoneID = &zone.ID
+					alert.Key = "zone:" + zone.ID.String() + ":" + object
+					alert.Message = fmt.Sprintf("目标 %s 进入区域 %s", object, zone.Name)
+					s.fire(rule, alert, input.time)
+				}
+			}
+
+		case models.AlertConditionZoneDwell:
+			for _, zone := range detection.Zones {
+				state := inside[zone.ID]
+				if state == nil || (rule.ZoneID != nil && *rule.ZoneID != zone.ID) {
+					continue
+				}
+				dwell := detection.Timestamp.Sub(state.enteredAt).Seconds()
+				if dwell >= rule.Threshold {
+					alert := alert
+					alert.ZoneID = &zone.ID
+					alert.Key = "dwell:" + zone.ID.String() + ":" + object
+					alert.Value = &dwell
+					alert.Message = fmt.Sprintf("目标 %s 在区域 %s 内停留 %.0f 秒", object, zone.Name, dwell)
+					s.fire(rule, alert, input.time)
+				}
+			}
+
+		case models.AlertConditionSpeedAbove:
+			if detection.Speed != nil && *detection.Speed > rule.Threshold {
+				alert.Key = "target:" + object
+				alert.Value = detection.Speed
+				alert.Message = fmt.Sprintf("目标 %s 速度 %.1f 米/秒，超过 %.1f 米/秒", object, *detection.Speed, rule.Threshold)
+				s.fire(rule, alert, input.time)
+			}
+
+		case models.AlertConditionAltitudeAbove:
+			if detection.Altitude != nil && *detection.Altitude > rule.Threshold {
+				alert.Key = "target:" + object
+				alert.Value = detection.Altitude
+				alert.Message = fmt.Sprintf("目标 %s 高度 %.1f 米，超过 %.1f 米", object, *detection.Altitude, rule.Threshold)
+				s.fire(rule, alert, input.time)
+			}
+
+		case models.AlertConditionAltitudeBelow:
+			if detection.Altitude != nil && *detection.Altitude < rule.Threshold {
+				alert.Key = "target:" + object
+				alert.Value = detection.Altitude
+				alert.Message = fmt.Sprintf("目标 %s 高度 %.1f 米，低于 %.1f 米", object, *detection.Altitude, rule.Threshold)
+				s.fire(rule, alert, input.time)
+			}
+
+		case models.AlertConditionUnknownSerial:
+			if detection.DroneSerial == "" {
+				alert.Key = "target:" + object
+				alert.Message = fmt.Sprintf("目标 %s 未识别到序列号", object)
+				s.fire(rule, alert, input.time)
+			} else if detection.WhitelistEntryID == nil {
+				alert.Key = "target:" + object
+				alert.Message = fmt.Sprintf("目标 %s 序列号不在白名单中", object)
+				s.fire(rule, alert, input.time)
+			}
+		}
+	}
+}
+
+// evaluateStatus 评估设备模块状态变化，模块恢复在线时自动关闭对应告警
+func (s *alertService) evaluateStatus(rules []models.AlertRuleModel, input alertInput) {
+	status := input.status
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Condition != models.AlertConditionDeviceOffline || (rule.DeviceID != nil && *rule.DeviceID != status.DeviceID) {
+			continue
+		}
+
+		key := "device:" + status.DeviceID.String() + ":" + status.Module
+		if status.Online {
+			s.resolve(rule, key, "设备模块恢复在线")
+			continue
+		}
+
+		message := fmt.Sprintf("设备 %s 模块 %s 离线", status.DeviceName, status.Module)
+		if status.Error != "" {
+			message += "：" + status.Error
+		}
+		s.fire(rule, models.AlertModel{
+			TenantID: input.tenantID,
+			DeviceID: &status.DeviceID,
+			Key:      key,
+			Message:  truncate(message, 255),
+		}, input.time)
+	}
+}
+
+// fire 触发告警：同一规则同一对象存在未关闭的告警时累加触发次数，否则创建新告警
+func (s *alertService) fire(rule *models.AlertRuleModel, alert models.AlertModel, at time.Time) {
+	at = at.UTC()
+
+	var existing models.AlertModel
+	err := s.db.Where(&models.AlertModel{RuleID: rule.ID, Key: alert.Key}).
+		Where("status IN ?", []models.AlertStatus{models.AlertStatusOpen, models.AlertStatusAcknowledged}).
+		Order("first_seen_at DESC").
+		First(&existing).Error
+	if err == nil {
+		// 未关闭期间只更新触发次数和最近触发信息，不重复推送
+		updates := map[string]any{
+			"last_seen_at": at,
+			"count":        gorm.Expr("`count` + 1"),
+			"message":      alert.Message,
+			"value":        alert.Value,
+			"detection_id": alert.DetectionID,
+		}
+		if err := s.db.Model(&existing).Updates(updates).Error; err != nil {
+			log.Errorf("更新告警 %s 失败: %v", existing.ID, err)
+		}
+		return
+	}
+	if err != gorm.ErrRecordNotFound {
+		log.Errorf("查询告警失败: %v", err)
+		return
+	}
+
+	alert.RuleID = rule.ID
+	alert.RuleName = rule.Name
+	alert.Condition = rule.Condition
+	alert.Severity = rule.Severity
+	alert.Status = models.AlertStatusOpen
+	alert.FirstSeenAt = at
+	alert.LastSeenAt = at
+	alert.Count = 1
+	if err := s.db.Create(&alert).Error; err != nil {
+		log.Errorf("创建告警失败: %v", err)
+		return
+	}
+
+	log.Warnf("触发告警 [%s] %s: %s", rule.Severity, rule.Name, alert.Message)
+	s.publish(&alert)
+}
+
+// resolve 自动关闭规则对应对象未关闭的告警
+func (s *alertService) resolve(rule *models.AlertRuleModel, key, note string) {
+	var alerts []models.AlertModel
+	if err := s.db.Where(&models.AlertModel{RuleID: rule.ID, Key: key}).
+		Where("status IN ?", []models.AlertStatus{models.AlertStatusOpen, models.AlertStatusAcknowledged}).
+		Find(&alerts).Error; err != nil {
+		log.Errorf("查询告警失败: %v", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range alerts {
+		updates := map[string]any{
+			"status":    models.AlertStatusClosed,
+			"closed_at": now,
+			"note":      note,
+		}
+		if err := s.update(s.db, &alerts[i], updates, alerts[i].Status); err != nil && !errors.Is(err, errAlertStatusChanged) {
+			log.Errorf("关闭告警 %s 失败: %v", alerts[i].ID, err)
+		}
+	}
+}
+
+// updatePresence 更新目标所在区域，返回新进入的区域和当前所在区域的停留状态
+func (s *alertService) updatePresence(detection *models.DetectionEventModel, targetKey string) (entered []*models.ZoneModel, inside map[uuid.UUID]*presence) {
+	if targetKey == "" || !detection.HasPosition() {
+		return nil, nil
+	}
+
+	target := presenceTarget{deviceID: detection.DeviceID, targetKey: targetKey}
+	previous := s.presences[target]
+	inside = make(map[uuid.UUID]*presence, len(detection.Zones))
+	for _, zone := range detection.Zones {
+		state := previous[zone.ID]
+		// 超过间隔未出现在区域内视为离开后重新进入
+		if state == nil || detection.Timestamp.Sub(state.lastSeen) > s.presenceGap {
+			state = &presence{enteredAt: detection.Timestamp}
+			entered = append(entered, zone)
+		}
+		if detection.Timestamp.After(state.lastSeen) {
+			state.lastSeen = detection.Timestamp
+		}
+		inside[zone.ID] = state
+	}
+
+	if len(inside) == 0 {
+		delete(s.presences, target)
+	} else {
+		s.presences[target] = inside
+	}
+	return entered, inside
+}
+
+// cleanPresences 清理长时间未出现的目标
+func (s *alertService) cleanPresences() {
+	now := time.Now()
+	for target, zones := range s.presences {
+		for zoneID, state := range zones {
+			if now.Sub(state.lastSeen) > s.presenceGap {
+				delete(zones, zoneID)
+			}
+		}
+		if len(zones) == 0 {
+			delete(s.presences, target)
+		}
+	}
+}
+
+// loadRules 加载租户已启用的规则，优先使用缓存
+func (s *alertService) loadRules(tenantId *uuid.UUID) ([]models.AlertRuleModel, error) {
+	key := uuid.Nil
+	if tenantId != nil {
+		key = *tenantId
+	}
+
+	s.mu.RLock()
+	rules, ok := s.rules[key]
+	version := s.version
+	s.mu.RUnlock()
+	if ok {
+		return rules, nil
+	}
+
+	query := s.db.Where("enabled = ?", true)
+	if tenantId != nil {
+		query = query.Where("tenant_id = ?", *tenantId)
+	} else {
+		query = query.Where("tenant_id IS NULL")
+	}
+	if err := query.Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.version == version {
+		s.rules[key] = rules
+	}
+	s.mu.Unlock()
+	return rules, nil
+}
+
+// invalidate 清空规则缓存
+func (s *alertService) invalidate() {
+	s.mu.Lock()
+	s.rules = make(map[uuid.UUID][]models.AlertRuleModel)
+	s.version++
+	s.mu.Unlock()
+}
+
+// publish 发布告警变化事件
+func (s *alertService) publish(alert *models.AlertModel) {
+	s.bus.Publish(event.Event{
+		Topic:    event.TopicAlert,
+		TenantID: alert.TenantID,
+		Payload:  *alert,
+	})
+}
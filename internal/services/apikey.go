@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/identity"
+	"xacms/internal/routes/dto"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// API密钥格式为 "xak_" + 12位十六进制前缀 + "_" + 64位十六进制密钥，前缀明文保存用于查找和展示
+const (
+	apiKeyPrefix      = "xak_"
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+)
+
+// apiKeyTouchInterval 最近使用时间的更新间隔，避免每个请求都写数据库
+const apiKeyTouchInterval = time.Minute
+
+var (
+	ErrApiKeyNotFound       = errors.New("API密钥不存在")
+	ErrApiKeyScopeInvalid   = errors.New("部分API名称不存在")
+	ErrApiKeyScopeDenied    = errors.New("不能授权密钥所有者无权访问的API")
+	ErrApiKeyDeviceNotFound = errors.New("绑定的设备不存在")
+	ErrApiKeyExpiresAt      = errors.New("过期时间必须晚于当前时间")
+	ErrApiKeyTwoFactor      = errors.New("密钥所有者的角色要求启用双因素认证，请先启用")
+)
+
+// ApiKeyService API密钥服务接口
+type ApiKeyService interface {
+	GetApiKeys(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.ApiKeyModel], error)
+	GetApiKey(ctx context.Context, keyId uuid.UUID) (*models.ApiKeyModel, error)
+	CreateApiKey(ctx context.Context, req dto.CreateApiKeyRequest) (*dto.CreateApiKeyResponse, error)
+	UpdateApiKey(ctx context.Context, keyId uuid.UUID, req dto.UpdateApiKeyRequest) (*models.ApiKeyModel, error)
+	DeleteApiKey(ctx context.Context, keyId uuid.UUID) error
+	AuthenticateApiKey(key, ip string) (*models.ApiKeyModel, bool, error)
+}
+
+// apiKeyService API密钥服务实现
+//
+// API密钥不经过双因素认证，角色要求双因素认证的用户未启用前不能创建或修改密钥，已有密钥也随即失效。
+type apiKeyService struct {
+	db                *gorm.DB
+	commonService     CommonService
+	permissionService PermissionService
+	twoFactorService  TwoFactorService
+}
+
+// NewApiKeyService 创建API密钥服务实例
+func NewApiKeyService(db *gorm.DB, commonService CommonService, permissionService PermissionService, twoFactorService TwoFactorService) ApiKeyService {
+	return &apiKeyService{
+		db:                db,
+		commonService:     commonService,
+		permissionService: permissionService,
+		twoFactorService:  twoFactorService,
+	}
+}
+
+// apiKeyQueryOptions API密钥列表查询选项
+var apiKeyQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"name":      {Column: "name", Type: FieldString},
+		"prefix":    {Column: "prefix", Type: FieldString},
+		"user_id":   {Column: "user_id", Type: FieldString},
+		"device_id": {Column: "device_id", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"name":         "name",
+		"expires_at":   "expires_at",
+		"last_used_at": "last_used_at",
+		"created_at":   "created_at",
+	},
+	KeywordFields: []string{"name", "prefix", "remark"},
+	DefaultSort:   "created_at DESC",
+	Preloads:      []string{"User", "Device"},
+}
+
+// GetApiKeys 获取API密钥列表
+func (s *apiKeyService) GetApiKeys(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.ApiKeyModel], error) {
+	return QueryList[models.ApiKeyModel](s.db.WithContext(ctx).Model(&models.ApiKeyModel{}), &req, apiKeyQueryOptions)
+}
+
+// GetApiKey 获取API密钥详情，包含所有者和绑定设备
+func (s *apiKeyService) GetApiKey(ctx context.Context, keyId uuid.UUID) (*models.ApiKeyModel, error) {
+	var key models.ApiKeyModel
+	if err := s.db.WithContext(ctx).Preload("User").Preload("Device").First(&key, "id = ?", keyId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrApiKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateApiKey 创建API密钥，所有者为当前用户，完整密钥只在创建时返回
+func (s *apiKeyService) CreateApiKey(ctx context.Context, req dto.CreateApiKeyRequest) (*dto.CreateApiKeyResponse, error) {
+	userID, ok := identity.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrApiKeyExpiresAt
+	}
+
+	if err := s.checkOwner(userID); err != nil {
+		return nil, err
+	}
+
+	scopes, err := s.checkScopes(userID, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkDevice(ctx, req.DeviceID); err != nil {
+		return nil, err
+	}
+
+	plain, prefix, err := generateApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.ApiKeyModel{
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hashIngestKey(plain),
+		Scopes:     &scopes,
+		Remark:     req.Remark,
+		UserID:     userID,
+		DeviceID:   req.DeviceID,
+		ExpiresAt:  utcTime(req.ExpiresAt),
+	}
+	if err := s.db.WithContext(ctx).Create(key).Error; err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateApiKeyResponse{ApiKeyModel: key, Key: plain}, nil
+}
+
+// UpdateApiKey 更新API密钥，授权范围不能超过密钥所有者的权限
+func (s *apiKeyService) UpdateApiKey(ctx context.Context, keyId uuid.UUID, req dto.UpdateApiKeyRequest) (*models.ApiKeyModel, error) {
+	var key models.ApiKeyModel
+	if err := s.commonService.GetItemByID(ctx, keyId, &key); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrApiKeyNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.checkOwner(key.UserID); err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		key.Name = *req.Name
+	}
+
+	if req.Scopes != nil {
+		scopes, err := s.checkScopes(key.UserID, *req.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		key.Scopes = &scopes
+	}
+
+	if req.DeviceID != nil {
+		key.DeviceID = nil
+		if *req.DeviceID != uuid.Nil {
+			if err := s.checkDevice(ctx, req.DeviceID); err != nil {
+				return nil, err
+			}
+			key.DeviceID = req.DeviceID
+		}
+	}
+
+	if req.ExpiresAt != nil {
+		key.ExpiresAt = nil
+		if !req.ExpiresAt.IsZero() {
+			if !req.ExpiresAt.After(time.Now()) {
+				return nil, ErrApiKeyExpiresAt
+			}
+			key.ExpiresAt = utcTime(req.ExpiresAt)
+		}
+	}
+
+	if req.Remark != nil {
+		key.Remark = *req.Remark
+	}
+
+	if err := s.db.WithContext(ctx).Save(&key).Error; err != nil {
+		return nil, err
+	}
+	return s.GetApiKey(ctx, key.ID)
+}
+
+// DeleteApiKey 删除API密钥，删除后立即失效
+func (s *apiKeyService) DeleteApiKey(ctx context.Context, keyId uuid.UUID) error {
+	var key models.ApiKeyModel
+	if err := s.commonService.GetItemByID(ctx, keyId, &key); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrApiKeyNotFound
+		}
+		return err
+	}
+	return s.db.WithContext(ctx).Delete(&key).Error
+}
+
+// AuthenticateApiKey 校验API密钥，密钥不存在、错误、已过期、所有者被禁用或未按角色要求启用双因素认证时返回 false
+//
+// 认证发生在确定租户之前，按前缀全局查找；认证通过后按间隔更新最近使用时间和IP。
+func (s *apiKeyService) AuthenticateApiKey(plain, ip string) (*models.ApiKeyModel, bool, error) {
+	prefix, ok := parseApiKey(plain)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var key models.ApiKeyModel
+	if err := s.db.Preload("User.Role").Preload("Device").First(&key, "prefix = ?", prefix).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashIngestKey(plain))) != 1 {
+		return nil, false, nil
+	}
+
+	now := time.Now()
+	if key.Expired(now) {
+		return nil, false, nil
+	}
+	if key.User == nil || key.User.Status == nil || !key.User.Status.IsEnabled() {
+		return nil, false, nil
+	}
+	if key.User.Role != nil && key.User.Role.RequireTwoFactor && !key.User.TwoFactorEnabled {
+		return nil, false, nil
+	}
+	// 绑定的设备已删除时密钥失效，避免被当作未绑定设备的密钥使用
+	if key.DeviceID != nil && key.Device == nil {
+		return nil, false, nil
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval || key.LastUsedIP != ip {
+		s.touch(&key, now.UTC(), ip)
+	}
+	return &key, true, nil
+}
+
+// touch 更新最近使用时间和IP，失败不影响本次认证
+func (s *apiKeyService) touch(key *models.ApiKeyModel, now time.Time, ip string) {
+	ip = truncate(ip, 64)
+	err := s.db.Model(&models.ApiKeyModel{}).Where("id = ?", key.ID).
+		UpdateColumns(map[string]any{"last_used_at": now, "last_used_ip": ip}).Error
+	if err != nil {
+		log.Errorf("更新API密钥 %s 使用时间失败: %v", key.Prefix, err)
+		return
+	}
+	key.LastUsedAt = &now
+	key.LastUsedIP = ip
+}
+
+// checkOwner 检查密钥所有者的角色要求双因素认证时已经启用
+func (s *apiKeyService) checkOwner(ownerID uuid.UUID) error {
+	var owner models.UserModel
+	if err := s.db.Select("id", "role_id", "two_factor_enabled").Take(&owner, "id = ?", ownerID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if owner.TwoFactorEnabled {
+		return nil
+	}
+
+	required, err := s.twoFactorService.Required(&owner)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrApiKeyTwoFactor
+	}
+	return nil
+}
+
+// checkScopes 校验授权的API名称均存在且密钥所有者有权访问，返回去重后的名称
+func (s *apiKeyService) checkScopes(ownerID uuid.UUID, names []string) (models.ApiNames, error) {
+	known := make(map[string]struct{})
+	for _, route := range s.commonService.GetAPIs() {
+		if route.Name != "" {
+			known[route.Name] = struct{}{}
+		}
+	}
+
+	scopes := make(models.ApiNames, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if slices.Contains(scopes, name) {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			return nil, ErrApiKeyScopeInvalid
+		}
+
+		allowed, err := s.permissionService.HasPermission(ownerID, name)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrApiKeyScopeDenied
+		}
+		scopes = append(scopes, name)
+	}
+	return scopes, nil
+}
+
+// checkDevice 检查绑定的设备存在于当前租户
+func (s *apiKeyService) checkDevice(ctx context.Context, deviceId *uuid.UUID) error {
+	if deviceId == nil {
+		return nil
+	}
+
+	var device models.DeviceModel
+	if err := s.commonService.GetItemByID(ctx, *deviceId, &device); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrApiKeyDeviceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// generateApiKey 生成随机API密钥，返回完整密钥和前缀
+func generateApiKey() (string, string, error) {
+	buf := make([]byte, apiKeyPrefixBytes+apiKeySecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	prefix := apiKeyPrefix + hex.EncodeToString(buf[:apiKeyPrefixBytes])
+	return prefix + "_" + hex.EncodeToString(buf[apiKeyPrefixBytes:]), prefix, nil
+}
+
+// parseApiKey 从完整密钥中解析前缀，格式不正确时返回 false
+func parseApiKey(plain string) (string, bool) {
+	rest, ok := strings.CutPrefix(plain, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != apiKeyPrefixBytes*2 || len(secret) != apiKeySecretBytes*2 {
+		return "", false
+	}
+	return apiKeyPrefix + prefix, true
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/password"
+	"xacms/internal/pkg/token"
+	"xacms/internal/routes/dto"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	ErrUserDisabled       = errors.New("用户已被禁用")
+)
+
+// AuthService 认证服务接口
+type AuthService interface {
+	Login(req dto.LoginRequest, client LoginClient) (*dto.TokenResponse, error)
+	LoginTwoFactor(req dto.TwoFactorLoginRequest, client LoginClient) (*dto.TokenResponse, error)
+	Refresh(req dto.RefreshTokenRequest, client LoginClient) (*dto.TokenResponse, error)
+	Logout(claims *token.Claims, req dto.LogoutRequest) error
+}
+
+// authService 认证服务实现
+type authService struct {
+	db                  *gorm.DB
+	tokenManager        *token.Manager
+	hasher              password.Hasher
+	loginAttemptService LoginAttemptService
+	twoFactorService    TwoFactorService
+	sessionService      SessionService
+	dummyHash           string // 用户不存在时用于校验的占位哈希
+}
+
+// NewAuthService 创建认证服务实例
+func NewAuthService(db *gorm.DB, tokenManager *token.Manager, hasher password.Hasher, loginAttemptService LoginAttemptService, twoFactorService TwoFactorService, sessionService SessionService) AuthService {
+	return &authService{
+		db:                  db,
+		tokenManager:        tokenManager,
+		hasher:              hasher,
+		loginAttemptService: loginAttemptService,
+		twoFactorService:    twoFactorService,
+		sessionService:      sessionService,
+		dummyHash:           dummyPasswordHash(hasher),
+	}
+}
+
+// dummyPasswordHash 使用当前哈希参数生成占位哈希，使用户不存在时的登录耗时与密码错误一致
+func dummyPasswordHash(hasher password.Hasher) string {
+	hashed, err := hasher.Hash("xacms-dummy-password")
+	if err != nil {
+		log.Errorf("生成占位密码哈希失败: %v", err)
+	}
+	return hashed
+}
+
+// Login 用户名密码登录，每次登录都会留下记录，连续失败过多时临时锁定账号和IP
+//
+// 用户启用双因素认证时只返回两步验证令牌，登录记录在两步验证完成或失败时写入。
+func (s *authService) Login(req dto.LoginRequest, client LoginClient) (*dto.TokenResponse, error) {
+	user, err := s.authenticate(req, client)
+	if err == nil && user.TwoFactorEnabled {
+		return s.challenge(user)
+	}
+	if err != nil && !isLoginFailure(err) {
+		// 数据库错误等服务端错误不属于登录失败，不留下记录
+		return nil, err
+	}
+
+	s.loginAttemptService.Record(req.Username, user, client, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startSession(user, client)
+}
+
+// authenticate 校验锁定状态和用户名密码，用户存在时始终返回用户
+func (s *authService) authenticate(req dto.LoginRequest, client LoginClient) (*models.UserModel, error) {
+	var user models.UserModel
+	if err := s.db.First(&user, "username = ?", req.Username).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			if err := s.loginAttemptService.Check(req.Username, client.IP); err != nil {
+				return nil, err
+			}
+			// 同样执行一次哈希校验，避免通过响应时间探测用户名是否存在
+			_, _ = s.hasher.Verify(s.dummyHash, req.Password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// 锁定期间不校验密码
+	if err := s.loginAttemptService.Check(req.Username, client.IP); err != nil {
+		return &user, err
+	}
+
+	ok, err := s.hasher.Verify(user.Password, req.Password)
+	if err != nil {
+		log.Errorf("校验用户 %s 密码失败: %v", user.Username, err)
+		return &user, ErrInvalidCredentials
+	}
+	if !ok {
+		return &user, ErrInvalidCredentials
+	}
+
+	if err := s.checkAccount(&user); err != nil {
+		return &user, err
+	}
+
+	// 哈希算法或参数变化，登录成功后透明地重新计算
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehash(&user, req.Password)
+	}
+
+	return &user, nil
+}
+
+// LoginTwoFactor 凭两步验证令牌和验证码或恢复码完成登录
+func (s *authService) LoginTwoFactor(req dto.TwoFactorLoginRequest, client LoginClient) (*dto.TokenResponse, error) {
+	claims, err := s.tokenManager.Parse(req.TwoFactorToken, token.TypeTwoFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.UserModel
+	if err := s.db.First(&user, "id = ?", claims.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, token.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	err = s.verifyTwoFactor(&user, req.Code, client)
+	if err != nil && !isLoginFailure(err) {
+		return nil, err
+	}
+
+	s.loginAttemptService.Record(user.Username, &user, client, err)
+	if err != nil {
+		return nil, err
+	}
+
+	// 两步验证令牌只能使用一次
+	s.tokenManager.Revoke(claims)
+
+	return s.startSession(&user, client)
+}
+
+// verifyTwoFactor 校验锁定状态、验证码以及账号状态
+func (s *authService) verifyTwoFactor(user *models.UserModel, code string, client LoginClient) error {
+	if err := s.loginAttemptService.Check(user.Username, client.IP); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorService.Verify(user, code); err != nil {
+		if errors.Is(err, ErrTwoFactorNotEnabled) {
+			// 签发两步验证令牌后双因素认证被重置
+			return token.ErrInvalidToken
+		}
+		return err
+	}
+
+	// 密码校验通过后账号可能已被禁用
+	return s.checkAccount(user)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧的刷新令牌随即失效，新令牌沿用原会话
+func (s *authService) Refresh(req dto.RefreshTokenRequest, client LoginClient) (*dto.TokenResponse, error) {
+	claims, err := s.tokenManager.Parse(req.RefreshToken, token.TypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.sessionService.ValidateSession(claims, client.IP)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, token.ErrRevokedToken
+	}
+
+	// 重新读取用户，确保角色变更和禁用状态立即生效
+	var user models.UserModel
+	if err := s.db.First(&user, "id = ?", claims.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, token.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if err := s.checkAccount(&user); err != nil {
+		return nil, err
+	}
+
+	s.tokenManager.Revoke(claims)
+
+	sessionId, _ := uuid.Parse(claims.Session)
+	resp, expiresAt, err := s.issue(&user, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.Extend(sessionId, expiresAt); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Logout 退出登录，撤销当前会话、访问令牌以及对应的刷新令牌
+func (s *authService) Logout(claims *token.Claims, req dto.LogoutRequest) error {
+	s.tokenManager.Revoke(claims)
+
+	if sessionId, err := uuid.Parse(claims.Session); err == nil {
+		if err := s.sessionService.RevokeSession(claims.UserID, sessionId); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+	}
+
+	if req.RefreshToken == "" {
+		return nil
+	}
+
+	refreshClaims, err := s.tokenManager.Parse(req.RefreshToken, token.TypeRefresh)
+	if err != nil {
+		// 刷新令牌已失效，无需再撤销
+		return nil
+	}
+
+	if refreshClaims.UserID != claims.UserID {
+		return token.ErrInvalidToken
+	}
+
+	s.tokenManager.Revoke(refreshClaims)
+	return nil
+}
+
+// rehash 按当前参数重新计算并保存密码哈希，失败不影响本次登录
+func (s *authService) rehash(user *models.UserModel, plain string) {
+	hashed, err := s.hasher.Hash(plain)
+	if err != nil {
+		log.Errorf("重新计算用户 %s 密码哈希失败: %v", user.Username, err)
+		return
+	}
+
+	// 以旧哈希作为条件，避免覆盖并发修改的密码
+	result := s.db.Model(&models.UserModel{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashed)
+	if result.Error != nil {
+		log.Errorf("保存用户 %s 密码哈希失败: %v", user.Username, result.Error)
+		return
+	}
+	user.Password = hashed
+}
+
+// checkAccount 校验用户及其所属租户是否启用
+func (s *authService) checkAccount(user *models.UserModel) error {
+	if user.Status == nil || !user.Status.IsEnabled() {
+		return ErrUserDisabled
+	}
+	return s.checkTenant(user)
+}
+
+// checkTenant 校验用户所属租户是否启用，平台用户不属于任何租户
+func (s *authService) checkTenant(user *models.UserModel) error {
+	if user.TenantID == nil {
+		return nil
+	}
+
+	var tenant models.TenantModel
+	if err := s.db.Select("id", "status").First(&tenant, "id = ?", *user.TenantID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrTenantDisabled
+		}
+		return err
+	}
+
+	if tenant.Status == nil || !tenant.Status.IsEnabled() {
+		return ErrTenantDisabled
+	}
+	return nil
+}
+
+// isLoginFailure 判断是否为需要记录的登录失败原因
+func isLoginFailure(err error) bool {
+	return errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrInvalidTwoFactorCode) || errors.Is(err, ErrLoginLocked) ||
+		errors.Is(err, ErrUserDisabled) || errors.Is(err, ErrTenantDisabled)
+}
+
+// challenge 签发两步验证令牌
+func (s *authService) challenge(user *models.UserModel) (*dto.TokenResponse, error) {
+	twoFactorToken, expiresAt, err := s.tokenManager.IssueTwoFactor(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		ExpiresIn:         int64(time.Until(expiresAt).Seconds()),
+		TwoFactorRequired: true,
+		TwoFactorToken:    twoFactorToken,
+	}, nil
+}
+
+// startSession 登录成功后创建会话并签发令牌
+func (s *authService) startSession(user *models.UserModel, client LoginClient) (*dto.TokenResponse, error) {
+	sessionId := uuid.New()
+	resp, expiresAt, err := s.issue(user, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionService.Create(sessionId, user, client, expiresAt); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// issue 为用户签发属于指定会话的令牌并组装响应，同时返回刷新令牌的过期时间
+//
+// 所属角色要求双因素认证而用户尚未启用时，令牌只能访问个人中心，用于完成绑定。
+func (s *authService) issue(user *models.UserModel, sessionId uuid.UUID) (*dto.TokenResponse, time.Time, error) {
+	required, err := s.twoFactorService.Required(user)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	scope := token.ScopeFull
+	setupRequired := required && !user.TwoFactorEnabled
+	if setupRequired {
+		scope = token.ScopeTwoFactorSetup
+	}
+
+	pair, err := s.tokenManager.Issue(user.ID, user.RoleID, user.TenantID, scope, sessionId.String())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	now := time.Now()
+	return &dto.TokenResponse{
+		AccessToken:            pair.AccessToken,
+		RefreshToken:           pair.RefreshToken,
+		TokenType:              "Bearer",
+		ExpiresIn:              int64(pair.AccessExpiresAt.Sub(now).Seconds()),
+		RefreshExpiresIn:       int64(pair.RefreshExpiresAt.Sub(now).Seconds()),
+		TwoFactorSetupRequired: setupRequired,
+	}, pair.RefreshExpiresAt, nil
+}
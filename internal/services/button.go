@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMenuNotFound   = errors.New("菜单不存在")
+	ErrButtonNotFound = errors.New("按钮不存在")
+)
+
+// ButtonService 按钮服务接口
+type ButtonService interface {
+	GetMenuButtons(ctx context.Context, menuId uuid.UUID) ([]models.ButtonModel, error)
+	CreateButton(ctx context.Context, menuId uuid.UUID, req *dto.CreateButtonRequest) (*models.ButtonModel, error)
+	UpdateButton(ctx context.Context, menuId uuid.UUID, buttonId uuid.UUID, req *dto.UpdateButtonRequest) (*models.ButtonModel, error)
+	DeleteButton(ctx context.Context, menuId uuid.UUID, buttonId uuid.UUID) error
+	DeleteMenuButtons(ctx context.Context, menuId uuid.UUID) error
+}
+
+// buttonService 按钮服务实现
+type buttonService struct {
+	db                *gorm.DB
+	commonService     CommonService
+	permissionService PermissionService
+}
+
+// NewButtonService 创建按钮服务实例
+func NewButtonService(db *gorm.DB, commonService CommonService, permissionService PermissionService) ButtonService {
+	return &buttonService{
+		db:                db,
+		commonService:     commonService,
+		permissionService: permissionService,
+	}
+}
+
+// GetMenuButtons 获取菜单按钮列表
+func (s *buttonService) GetMenuButtons(ctx context.Context, menuId uuid.UUID) ([]models.ButtonModel, error) {
+	if err := s.ensureMenu(ctx, menuId); err != nil {
+		return nil, err
+	}
+
+	var buttons []models.ButtonModel
+	if err := s.db.WithContext(ctx).Where("menu_id = ?", menuId).Order("`order` ASC, created_at DESC").Find(&buttons).Error; err != nil {
+		return nil, err
+	}
+	return buttons, nil
+}
+
+// CreateButton 创建按钮
+func (s *buttonService) CreateButton(ctx context.Context, menuId uuid.UUID, req *dto.CreateButtonRequest) (*models.ButtonModel, error) {
+	if err := s.ensureMenu(ctx, menuId); err != nil {
+		return nil, err
+	}
+
+	button := &models.ButtonModel{
+		MenuID:   menuId,
+		Code:     req.Code,
+		Name:     req.Name,
+		ApiNames: req.ApiNames,
+		Order:    req.Order,
+	}
+
+	if err := s.db.WithContext(ctx).Create(button).Error; err != nil {
+		return nil, err
+	}
+	return button, nil
+}
+
+// UpdateButton 更新按钮
+func (s *buttonService) UpdateButton(ctx context.Context, menuId uuid.UUID, buttonId uuid.UUID, req *dto.UpdateButtonRequest) (*models.ButtonModel, error) {
+	button, err := s.getButton(ctx, menuId, buttonId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code != nil {
+		button.Code = *req.Code
+	}
+
+	if req.Name != nil {
+		button.Name = *req.Name
+	}
+
+	if req.ApiNames != nil {
+		button.ApiNames = req.ApiNames
+	}
+
+	if req.Order != nil {
+		button.Order = *req.Order
+	}
+
+	if err := s.db.WithContext(ctx).Save(button).Error; err != nil {
+		return nil, err
+	}
+
+	// 按钮可能被多个角色引用，清除全部权限缓存
+	if req.ApiNames != nil {
+		s.permissionService.InvalidateAll()
+	}
+	return button, nil
+}
+
+// DeleteButton 删除按钮，同时解除与角色的关联
+func (s *buttonService) DeleteButton(ctx context.Context, menuId uuid.UUID, buttonId uuid.UUID) error {
+	button, err := s.getButton(ctx, menuId, buttonId)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("role_buttons").Where("button_model_id = ?", button.ID).Delete(nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(button).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.permissionService.InvalidateAll()
+	return nil
+}
+
+// DeleteMenuButtons 删除菜单下的全部按钮，用于删除菜单时清理
+func (s *buttonService) DeleteMenuButtons(ctx context.Context, menuId uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		buttonIDs := tx.Model(&models.ButtonModel{}).Select("id").Where("menu_id = ?", menuId)
+		if err := tx.Table("role_buttons").Where("button_model_id IN (?)", buttonIDs).Delete(nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("menu_id = ?", menuId).Delete(&models.ButtonModel{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.permissionService.InvalidateAll()
+	return nil
+}
+
+// ensureMenu 确认菜单存在
+func (s *buttonService) ensureMenu(ctx context.Context, menuId uuid.UUID) error {
+	var menu models.MenuModel
+	if err := s.commonService.GetItemByID(ctx, menuId, &menu); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrMenuNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// getButton 获取属于指定菜单的按钮
+func (s *buttonService) getButton(ctx context.Context, menuId uuid.UUID, buttonId uuid.UUID) (*models.ButtonModel, error) {
+	var button models.ButtonModel
+	if err := s.db.WithContext(ctx).First(&button, "id = ? AND menu_id = ?", buttonId, menuId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrButtonNotFound
+		}
+		return nil, err
+	}
+	return &button, nil
+}
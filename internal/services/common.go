@@ -1,8 +1,12 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"reflect"
 	"sort"
+	"strings"
+	"xacms/internal/routes/dto"
 	"xacms/internal/server"
 	"xacms/internal/utils"
 
@@ -11,13 +15,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrInvalidQuery 查询参数无效
+var ErrInvalidQuery = errors.New("查询参数无效")
+
 // CommonService 公共服务接口
 type CommonService interface {
-	GetItems(model any) error
-	GetItemByID(id uuid.UUID, model any) error
-	DeleteItemByID(model any, id uuid.UUID) error
+	GetItemByID(ctx context.Context, id uuid.UUID, model any) error
+	DeleteItemByID(ctx context.Context, model any, id uuid.UUID) error
 	ValidateBody(c *fiber.Ctx, model any) error
 	ValidateQuery(c *fiber.Ctx, model any) error
+	ValidateListQuery(c *fiber.Ctx, req dto.ListQuery) error
 	GetAPIs() []fiber.Route
 }
 
@@ -37,29 +44,17 @@ func NewCommonService(db *gorm.DB, validator *utils.ValidationMiddleware, fiberS
 	}
 }
 
-// GetItems 获取多个数据
-func (s *commonService) GetItems(model any) error {
-	// 先尝试按 order 字段排序，如果失败则只按创建时间排序
-	if err := s.db.Order("`order` ASC, created_at DESC").Find(model).Error; err != nil {
-		// 如果 order 字段不存在，回退到只按创建时间排序
-		if err := s.db.Order("created_at DESC").Find(model).Error; err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 // GetItemByID 根据ID获取单个数据
-func (s *commonService) GetItemByID(id uuid.UUID, model any) error {
-	if err := s.db.First(model, "id = ?", id).Error; err != nil {
+func (s *commonService) GetItemByID(ctx context.Context, id uuid.UUID, model any) error {
+	if err := s.db.WithContext(ctx).First(model, "id = ?", id).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 // DeleteItemByID 根据ID删除单个数据
-func (s *commonService) DeleteItemByID(model any, id uuid.UUID) error {
-	if err := s.db.Delete(model, "id = ?", id).Error; err != nil {
+func (s *commonService) DeleteItemByID(ctx context.Context, model any, id uuid.UUID) error {
+	if err := s.db.WithContext(ctx).Delete(model, "id = ?", id).Error; err != nil {
 		return err
 	}
 	return nil
@@ -93,6 +88,49 @@ func (s *commonService) ValidateQuery(c *fiber.Ctx, model any) error {
 	return nil
 }
 
+// ValidateListQuery 验证列表查询参数，结构体未声明的查询参数作为字段过滤条件，由 applyFilters 按白名单处理
+func (s *commonService) ValidateListQuery(c *fiber.Ctx, req dto.ListQuery) error {
+	if err := s.ValidateQuery(c, req); err != nil {
+		return err
+	}
+
+	declared := make(map[string]struct{})
+	collectQueryTags(reflect.TypeOf(req), declared)
+
+	filters := make(map[string]string)
+	for key, value := range c.Queries() {
+		if _, ok := declared[key]; ok {
+			continue
+		}
+		filters[key] = value
+	}
+	req.ListQuery().Filters = filters
+	return nil
+}
+
+// collectQueryTags 收集结构体（含嵌入结构体）声明的查询参数名
+func collectQueryTags(t reflect.Type, tags map[string]struct{}) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			collectQueryTags(field.Type, tags)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name != "" && name != "-" {
+			tags[name] = struct{}{}
+		}
+	}
+}
+
 // GetAPIs 获取API列表
 func (s *commonService) GetAPIs() []fiber.Route {
 	routeMap := make(map[string][]fiber.Route) // 键: 路径+名称, 值: 具有相同路径+名称的路由
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDepartmentNotFound       = errors.New("部门不存在")
+	ErrParentDepartmentNotFound = errors.New("上级部门不存在")
+	ErrDepartmentCycle          = errors.New("不能将部门移动到自身或其下级部门之下")
+	ErrDepartmentHasChildren    = errors.New("部门下仍有下级部门，无法删除")
+	ErrDepartmentHasUsers       = errors.New("部门下仍有用户，无法删除")
+)
+
+// DepartmentService 部门服务接口
+type DepartmentService interface {
+	GetDepartments(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.DepartmentModel], error)
+	GetDepartmentTree(ctx context.Context) ([]dto.DepartmentTreeItem, error)
+	CreateDepartment(ctx context.Context, req dto.CreateDepartmentRequest) (*models.DepartmentModel, error)
+	UpdateDepartment(ctx context.Context, departmentId uuid.UUID, req dto.UpdateDepartmentRequest) (*models.DepartmentModel, error)
+	DeleteDepartment(ctx context.Context, departmentId uuid.UUID) error
+	GetDescendantIDs(ctx context.Context, departmentId uuid.UUID) ([]uuid.UUID, error)
+}
+
+// departmentService 部门服务实现
+type departmentService struct {
+	db            *gorm.DB
+	commonService CommonService
+}
+
+// NewDepartmentService 创建部门服务实例
+func NewDepartmentService(db *gorm.DB, commonService CommonService) DepartmentService {
+	return &departmentService{
+		db:            db,
+		commonService: commonService,
+	}
+}
+
+// departmentQueryOptions 部门列表查询选项
+var departmentQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"parent_id":   {Column: "parent_id", Type: FieldUUID},
+		"name":        {Column: "name", Type: FieldString},
+		"description": {Column: "description", Type: FieldString},
+		"created_at":  {Column: "created_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"name":       "name",
+		"order":      "order",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	KeywordFields: []string{"name", "description"},
+	DefaultSort:   "`order` ASC, created_at DESC",
+}
+
+// GetDepartments 获取部门列表
+func (s *departmentService) GetDepartments(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.DepartmentModel], error) {
+	return QueryList[models.DepartmentModel](s.db.WithContext(ctx).Model(&models.DepartmentModel{}), &req, departmentQueryOptions)
+}
+
+// GetDepartmentTree 获取部门树
+func (s *departmentService) GetDepartmentTree(ctx context.Context) ([]dto.DepartmentTreeItem, error) {
+	var departments []models.DepartmentModel
+	if err := s.db.WithContext(ctx).Order("`order` ASC, created_at DESC").Find(&departments).Error; err != nil {
+		return nil, err
+	}
+
+	// 递归组装部门树
+	var buildTree func(parentID *uuid.UUID) []dto.DepartmentTreeItem
+	buildTree = func(parentID *uuid.UUID) []dto.DepartmentTreeItem {
+		children := []dto.DepartmentTreeItem{}
+		for _, department := range departments {
+			if utils.EqualUUID(department.ParentID, parentID) {
+				children = append(children, dto.DepartmentTreeItem{
+					DepartmentModel: department,
+					Children:        buildTree(&department.ID),
+				})
+			}
+		}
+		return children
+	}
+
+	return buildTree(nil), nil
+}
+
+// CreateDepartment 创建部门
+func (s *departmentService) CreateDepartment(ctx context.Context, req dto.CreateDepartmentRequest) (*models.DepartmentModel, error) {
+	if req.ParentID != nil {
+		if err := s.ensureParent(ctx, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	department := &models.DepartmentModel{
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Description: req.Description,
+		Order:       req.Order,
+	}
+	if err := s.db.WithContext(ctx).Create(department).Error; err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// UpdateDepartment 更新部门，上级部门为全零UUID时移动到顶级
+func (s *departmentService) UpdateDepartment(ctx context.Context, departmentId uuid.UUID, req dto.UpdateDepartmentRequest) (*models.DepartmentModel, error) {
+	var department models.DepartmentModel
+	if err := s.commonService.GetItemByID(ctx, departmentId, &department); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDepartmentNotFound
+		}
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == uuid.Nil {
+			department.ParentID = nil
+		} else {
+			if err := s.ensureParent(ctx, *req.ParentID); err != nil {
+				return nil, err
+			}
+
+			// 新的上级部门不能是自身或下级部门，否则会形成环
+			descendants, err := s.GetDescendantIDs(ctx, departmentId)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range descendants {
+				if id == *req.ParentID {
+					return nil, ErrDepartmentCycle
+				}
+			}
+			department.ParentID = req.ParentID
+		}
+	}
+
+	if req.Name != nil {
+		department.Name = *req.Name
+	}
+
+	if req.Description != nil {
+		department.Description = *req.Description
+	}
+
+	if req.Order != nil {
+		department.Order = *req.Order
+	}
+
+	if err := s.db.WithContext(ctx).Save(&department).Error; err != nil {
+		return nil, err
+	}
+	return &department, nil
+}
+
+// DeleteDepartment 删除部门，部门下仍有下级部门或用户时拒绝删除
+func (s *departmentService) DeleteDepartment(ctx context.Context, departmentId uuid.UUID) error {
+	var department models.DepartmentModel
+	if err := s.commonService.GetItemByID(ctx, departmentId, &department); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrDepartmentNotFound
+		}
+		return err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.DepartmentModel{}).Where("parent_id = ?", departmentId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDepartmentHasChildren
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.UserModel{}).Where("department_id = ?", departmentId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDepartmentHasUsers
+	}
+
+	return s.db.WithContext(ctx).Delete(&department).Error
+}
+
+// GetDescendantIDs 获取部门及其全部下级部门的ID
+func (s *departmentService) GetDescendantIDs(ctx context.Context, departmentId uuid.UUID) ([]uuid.UUID, error) {
+	var departments []models.DepartmentModel
+	if err := s.db.WithContext(ctx).Select("id", "parent_id").Find(&departments).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]uuid.UUID) // 键: 上级部门ID, 值: 下级部门ID
+	for _, department := range departments {
+		if department.ParentID != nil {
+			children[*department.ParentID] = append(children[*department.ParentID], department.ID)
+		}
+	}
+
+	// 广度优先遍历，visited 防止历史数据中存在环时死循环
+	ids := []uuid.UUID{departmentId}
+	visited := map[uuid.UUID]struct{}{departmentId: {}}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if _, ok := visited[child]; ok {
+				continue
+			}
+			visited[child] = struct{}{}
+			ids = append(ids, child)
+		}
+	}
+	return ids, nil
+}
+
+// ensureParent 确认上级部门存在
+func (s *departmentService) ensureParent(ctx context.Context, parentId uuid.UUID) error {
+	var parent models.DepartmentModel
+	if err := s.commonService.GetItemByID(ctx, parentId, &parent); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrParentDepartmentNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/event"
+	"xacms/internal/routes/dto"
+
+	"gorm.io/gorm"
+)
+
+// ingestBatchSize 侦测事件批量写入的批次大小
+const ingestBatchSize = 200
+
+// DetectionService 侦测事件服务接口
+type DetectionService interface {
+	Ingest(ctx context.Context, device *models.DeviceModel, req dto.IngestDetectionsRequest) (*dto.IngestDetectionsResponse, error)
+	GetDetections(ctx context.Context, req dto.DetectionQueryRequest) (*dto.PaginatedResponse[models.DetectionEventModel], error)
+}
+
+// detectionService 侦测事件服务实现
+type detectionService struct {
+	db               *gorm.DB
+	zoneService      ZoneService
+	whitelistService WhitelistService
+	bus              *event.Bus
+}
+
+// NewDetectionService 创建侦测事件服务实例
+func NewDetectionService(db *gorm.DB, zoneService ZoneService, whitelistService WhitelistService, bus *event.Bus) DetectionService {
+	return &detectionService{
+		db:               db,
+		zoneService:      zoneService,
+		whitelistService: whitelistService,
+		bus:              bus,
+	}
+}
+
+// detectionQueryOptions 侦测事件列表查询选项
+var detectionQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"track_id":           {Column: "track_id", Type: FieldUUID},
+		"target_id":          {Column: "target_id", Type: FieldString},
+		"protocol":           {Column: "protocol", Type: FieldString},
+		"drone_model":        {Column: "drone_model", Type: FieldString},
+		"drone_serial":       {Column: "drone_serial", Type: FieldString},
+		"frequency":          {Column: "frequency", Type: FieldNumber},
+		"altitude":           {Column: "altitude", Type: FieldNumber},
+		"speed":              {Column: "speed", Type: FieldNumber},
+		"rssi":               {Column: "rssi", Type: FieldNumber},
+		"friendly":           {Column: "friendly", Type: FieldBool},
+		"whitelist_entry_id": {Column: "whitelist_entry_id", Type: FieldUUID},
+	},
+	SortFields: map[string]string{
+		"timestamp": "timestamp",
+		"frequency": "frequency",
+		"altitude":  "altitude",
+		"speed":     "speed",
+		"rssi":      "rssi",
+	},
+	KeywordFields: []string{"target_id", "drone_model", "drone_serial"},
+	DefaultSort:   "timestamp DESC",
+}
+
+// Ingest 批量写入设备上报的侦测事件并标记所在区域和友方目标，写入成功后逐条发布事件
+func (s *detectionService) Ingest(ctx context.Context, device *models.DeviceModel, req dto.IngestDetectionsRequest) (*dto.IngestDetectionsResponse, error) {
+	detections := make([]models.DetectionEventModel, 0, len(req.Events))
+	for _, item := range req.Events {
+		detections = append(detections, models.DetectionEventModel{
+			TenantID:    device.TenantID,
+			DeviceID:    device.ID,
+			Timestamp:   item.Timestamp.UTC(),
+			TargetID:    item.TargetID,
+			Frequency:   item.Frequency,
+			Protocol:    item.Protocol,
+			Latitude:    item.Latitude,
+			Longitude:   item.Longitude,
+			Altitude:    item.Altitude,
+			Heading:     item.Heading,
+			Speed:       item.Speed,
+			RSSI:        item.RSSI,
+			DroneModel:  item.DroneModel,
+			DroneSerial: item.DroneSerial,
+		})
+	}
+
+	for i := range detections {
+		if !detections[i].HasPosition() {
+			continue
+		}
+		zones, err := s.zoneService.Locate(device.TenantID, *detections[i].Longitude, *detections[i].Latitude)
+		if err != nil {
+			return nil, err
+		}
+		detections[i].Zones = zones
+	}
+
+	// 友方目标的允许区域判断依赖所在区域
+	if err := s.whitelistService.Classify(device.TenantID, detections); err != nil {
+		return nil, err
+	}
+
+	// 只写入区域关联，不更新区域本身
+	if err := s.db.WithContext(ctx).Omit("Zones.*").CreateInBatches(&detections, ingestBatchSize).Error; err != nil {
+		return nil, err
+	}
+
+	for _, detection := range detections {
+		s.bus.Publish(event.Event{
+			Topic:    event.TopicDetection,
+			TenantID: detection.TenantID,
+			Time:     detection.Timestamp,
+			Payload:  detection,
+		})
+	}
+
+	return &dto.IngestDetectionsResponse{Accepted: len(detections)}, nil
+}
+
+// GetDetections 获取侦测事件列表，支持按设备、时间范围和经纬度范围过滤
+func (s *detectionService) GetDetections(ctx context.Context, req dto.DetectionQueryRequest) (*dto.PaginatedResponse[models.DetectionEventModel], error) {
+	// 所在区域不返回几何对象
+	query := s.db.WithContext(ctx).Model(&models.DetectionEventModel{}).Preload("Zones", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "tenant_id", "name", "type", "enabled", "remark", "created_at", "updated_at")
+	})
+
+	if req.DeviceID != nil {
+		query = query.Where("device_id = ?", *req.DeviceID)
+	}
+
+	if req.ZoneID != nil {
+		query = query.Where("id IN (?)", s.db.Table("detection_zones").Select("detection_id").Where("zone_id = ?", *req.ZoneID))
+	}
+
+	if req.StartTime != "" {
+		start, err := time.Parse(time.RFC3339, req.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: start_time 格式无效", ErrInvalidQuery)
+		}
+		query = query.Where("timestamp >= ?", start.UTC())
+	}
+
+	if req.EndTime != "" {
+		end, err := time.Parse(time.RFC3339, req.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: end_time 格式无效", ErrInvalidQuery)
+		}
+		query = query.Where("timestamp <= ?", end.UTC())
+	}
+
+	if req.BBox != "" {
+		minLon, minLat, maxLon, maxLat, err := parseBBox(req.BBox)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("longitude BETWEEN ? AND ? AND latitude BETWEEN ? AND ?", minLon, maxLon, minLat, maxLat)
+	}
+
+	return QueryList[models.DetectionEventModel](query, &req.ListQueryRequest, detectionQueryOptions)
+}
+
+// parseBBox 解析经纬度范围：最小经度,最小纬度,最大经度,最大纬度
+func parseBBox(bbox string) (minLon, minLat, maxLon, maxLat float64, err error) {
+	parts := strings.Split(bbox, ",")
+	if len(parts) != 4 {
+		return 0, 0, 0, 0, fmt.Errorf("%w: bbox 格式应为 最小经度,最小纬度,最大经度,最大纬度", ErrInvalidQuery)
+	}
+
+	values := make([]float64, 4)
+	for i, part := range parts {
+		values[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("%w: bbox 包含无效数字", ErrInvalidQuery)
+		}
+	}
+
+	minLon, minLat, maxLon, maxLat = values[0], values[1], values[2], values[3]
+	if minLon > maxLon || minLat > maxLat || minLon < -180 || maxLon > 180 || minLat < -90 || maxLat > 90 {
+		return 0, 0, 0, 0, fmt.Errorf("%w: bbox 范围无效", ErrInvalidQuery)
+	}
+	return minLon, minLat, maxLon, maxLat, nil
+}
@@ -1,36 +1,110 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"xacms/internal/models"
+	"xacms/internal/pkg/driver"
+	"xacms/internal/pkg/password"
 	"xacms/internal/routes/dto"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrDeviceNotFound = errors.New("设备不存在")
+	ErrUnknownDriver  = errors.New("不支持的设备驱动")
+
+	ErrStreamPathExists   = errors.New("流路径已被其他设备使用")
+	ErrStreamPathReserved = errors.New("fpv/ 为默认流路径前缀，不能使用")
+)
+
+// ingestKeyPrefix 设备数据接入密钥前缀
+const ingestKeyPrefix = "xdk_"
+
 // DeviceService 用户服务接口
 type DeviceService interface {
-	CreateDevice(req dto.CreateDeviceRequest) (*models.DeviceModel, error)
-	UpdateDevice(userId uuid.UUID, req dto.UpdateDeviceRequest) (*models.DeviceModel, error)
+	GetDevices(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.DeviceModel], error)
+	CreateDevice(ctx context.Context, req dto.CreateDeviceRequest) (*models.DeviceModel, error)
+	UpdateDevice(ctx context.Context, userId uuid.UUID, req dto.UpdateDeviceRequest) (*models.DeviceModel, error)
+	GenerateIngestKey(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceIngestKeyResponse, error)
+	AuthenticateDevice(deviceId uuid.UUID, key string) (*models.DeviceModel, bool, error)
 }
 
 // deviceService 设备服务实现
 type deviceService struct {
-	db            *gorm.DB
-	commonService CommonService
+	db               *gorm.DB
+	commonService    CommonService
+	dataScopeService DataScopeService
+	hasher           password.Hasher
 }
 
 // NewDeviceService 创建设备服务实例
-func NewDeviceService(db *gorm.DB, commonService CommonService) DeviceService {
+func NewDeviceService(db *gorm.DB, commonService CommonService, dataScopeService DataScopeService, hasher password.Hasher) DeviceService {
 	return &deviceService{
-		db:            db,
-		commonService: commonService,
+		db:               db,
+		commonService:    commonService,
+		dataScopeService: dataScopeService,
+		hasher:           hasher,
 	}
 }
 
+// deviceQueryOptions 设备列表查询选项
+var deviceQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"name":             {Column: "name", Type: FieldString},
+		"longitude":        {Column: "longitude", Type: FieldNumber},
+		"latitude":         {Column: "latitude", Type: FieldNumber},
+		"detection_id":     {Column: "detection_id", Type: FieldNumber},
+		"detection_ip":     {Column: "detection_ip", Type: FieldString},
+		"detection_port":   {Column: "detection_port", Type: FieldNumber},
+		"analysis_id":      {Column: "analysis_id", Type: FieldNumber},
+		"analysis_ip":      {Column: "analysis_ip", Type: FieldString},
+		"fpv_ip":           {Column: "fpv_ip", Type: FieldString},
+		"stream_server_ip": {Column: "stream_server_ip", Type: FieldString},
+		"strike_ip":        {Column: "strike_ip", Type: FieldString},
+		"strike_port":      {Column: "strike_port", Type: FieldNumber},
+		"department_id":    {Column: "department_id", Type: FieldUUID},
+		"created_by":       {Column: "created_by", Type: FieldUUID},
+		"driver":           {Column: "driver", Type: FieldString},
+		"created_at":       {Column: "created_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"name":       "name",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	KeywordFields: []string{"name", "detection_ip", "analysis_ip", "fpv_ip", "stream_server_ip", "strike_ip"},
+	DefaultSort:   "created_at DESC",
+	Preloads:      []string{"ModuleStatuses"},
+}
+
+// GetDevices 获取设备列表
+func (s *deviceService) GetDevices(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.DeviceModel], error) {
+	// 数据范围
+	query, err := s.dataScopeService.Apply(ctx, s.db.WithContext(ctx).Model(&models.DeviceModel{}), DataScopeColumns{Owner: "created_by", Department: "department_id"})
+	if err != nil {
+		return nil, err
+	}
+
+	return QueryList[models.DeviceModel](query, &req, deviceQueryOptions)
+}
+
 // CreateDevice 创建用户
-func (s *deviceService) CreateDevice(req dto.CreateDeviceRequest) (*models.DeviceModel, error) {
+func (s *deviceService) CreateDevice(ctx context.Context, req dto.CreateDeviceRequest) (*models.DeviceModel, error) {
+	// 记录创建人及其部门，用于数据范围过滤
+	createdBy, departmentID, err := s.dataScopeService.GetOwnership(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	deviceData := &models.DeviceModel{
 		ID:        uuid.New(),
 		Name:      req.Name,
@@ -49,24 +123,55 @@ func (s *deviceService) CreateDevice(req dto.CreateDeviceRequest) (*models.Devic
 		// FPV模块
 		FPVIP:          req.FPVIP,
 		StreamServerIP: req.StreamServerIP,
+		StreamProtocol: req.StreamProtocol,
+		StreamPath:     req.StreamPath,
+		StreamUsername: req.StreamUsername,
 
 		// 打击模块
 		StrikeIP:   req.StrikeIP,
 		StrikePort: req.StrikePort,
+
+		// 设备驱动
+		Driver: req.Driver,
+
+		DepartmentID: departmentID,
+		CreatedBy:    createdBy,
+	}
+	if req.Driver != "" {
+		if _, ok := driver.Get(req.Driver); !ok {
+			return nil, ErrUnknownDriver
+		}
+		if req.DriverConfig != nil {
+			config := models.DriverConfig(req.DriverConfig)
+			deviceData.DriverConfig = &config
+		}
+	}
+	if deviceData.StreamProtocol == "" {
+		deviceData.StreamProtocol = "rtsp"
+	}
+	if err := s.checkStreamPath(deviceData); err != nil {
+		return nil, err
+	}
+	if req.StreamPassword != "" {
+		hash, err := s.hasher.Hash(req.StreamPassword)
+		if err != nil {
+			return nil, err
+		}
+		deviceData.StreamPasswordHash = hash
 	}
 
-	if err := s.db.Create(deviceData).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(deviceData).Error; err != nil {
 		return nil, err
 	}
 	return deviceData, nil
 }
 
 // UpdateDevice 修改设备
-func (s *deviceService) UpdateDevice(userId uuid.UUID, req dto.UpdateDeviceRequest) (*models.DeviceModel, error) {
+func (s *deviceService) UpdateDevice(ctx context.Context, userId uuid.UUID, req dto.UpdateDeviceRequest) (*models.DeviceModel, error) {
 	var user models.DeviceModel
-	if err := s.commonService.GetItemByID(userId, &user); err != nil {
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("设备不存在")
+			return nil, ErrDeviceNotFound
 		}
 		return nil, err
 	}
@@ -112,6 +217,33 @@ func (s *deviceService) UpdateDevice(userId uuid.UUID, req dto.UpdateDeviceReque
 		user.StreamServerIP = *req.StreamServerIP
 	}
 
+	if req.StreamProtocol != nil {
+		user.StreamProtocol = *req.StreamProtocol
+	}
+
+	if req.StreamPath != nil {
+		user.StreamPath = *req.StreamPath
+		if err := s.checkStreamPath(&user); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.StreamUsername != nil {
+		user.StreamUsername = *req.StreamUsername
+	}
+
+	// 空字符串表示清除推流密码
+	if req.StreamPassword != nil {
+		user.StreamPasswordHash = ""
+		if *req.StreamPassword != "" {
+			hash, err := s.hasher.Hash(*req.StreamPassword)
+			if err != nil {
+				return nil, err
+			}
+			user.StreamPasswordHash = hash
+		}
+	}
+
 	if req.StrikeIP != nil {
 		user.StrikeIP = *req.StrikeIP
 	}
@@ -120,8 +252,93 @@ func (s *deviceService) UpdateDevice(userId uuid.UUID, req dto.UpdateDeviceReque
 		user.StrikePort = *req.StrikePort
 	}
 
-	if err := s.db.Save(&user).Error; err != nil {
+	if req.Driver != nil {
+		if *req.Driver != "" {
+			if _, ok := driver.Get(*req.Driver); !ok {
+				return nil, ErrUnknownDriver
+			}
+		}
+		user.Driver = *req.Driver
+	}
+
+	// 与创建设备一致，只有配置了驱动时才保存驱动配置，移除驱动时一并清空
+	if user.Driver == "" {
+		user.DriverConfig = nil
+	} else if req.DriverConfig != nil {
+		config := models.DriverConfig(req.DriverConfig)
+		user.DriverConfig = &config
+	}
+
+	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// GenerateIngestKey 生成设备数据接入密钥，旧密钥随即失效，只保存哈希
+func (s *deviceService) GenerateIngestKey(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceIngestKeyResponse, error) {
+	var device models.DeviceModel
+	if err := s.commonService.GetItemByID(ctx, deviceId, &device); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := ingestKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	if err := s.db.WithContext(ctx).Model(&device).Update("ingest_key_hash", hashIngestKey(key)).Error; err != nil {
+		return nil, err
+	}
+
+	return &dto.DeviceIngestKeyResponse{
+		DeviceID:  device.ID,
+		IngestKey: key,
+	}, nil
+}
+
+// AuthenticateDevice 校验设备数据接入密钥，设备不存在或密钥错误时返回 false
+func (s *deviceService) AuthenticateDevice(deviceId uuid.UUID, key string) (*models.DeviceModel, bool, error) {
+	var device models.DeviceModel
+	if err := s.db.First(&device, "id = ?", deviceId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if device.IngestKeyHash == "" || subtle.ConstantTimeCompare([]byte(device.IngestKeyHash), []byte(hashIngestKey(key))) != 1 {
+		return nil, false, nil
+	}
+	return &device, true, nil
+}
+
+// checkStreamPath 检查自定义流路径未被其他设备使用
+func (s *deviceService) checkStreamPath(device *models.DeviceModel) error {
+	if device.StreamPath == "" {
+		return nil
+	}
+	if strings.HasPrefix(device.StreamPath, models.DefaultStreamPathPrefix) {
+		return ErrStreamPathReserved
+	}
+
+	// 流路径在流媒体服务器上全局唯一，不按租户隔离
+	var count int64
+	if err := s.db.Model(&models.DeviceModel{}).Where("id <> ? AND stream_path = ?", device.ID, device.StreamPath).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrStreamPathExists
+	}
+	return nil
+}
+
+// hashIngestKey 计算接入密钥哈希，密钥为高熵随机值，使用 SHA-256 即可
+func hashIngestKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
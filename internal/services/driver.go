@@ -0,0 +1,495 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/driver"
+	"xacms/internal/pkg/strike"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/bytedance/sonic"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDeviceNoDriver      = errors.New("设备未配置驱动")
+	ErrDriverNotConnected  = errors.New("设备驱动未连接")
+	ErrInvalidDriverConfig = errors.New("驱动配置无效")
+)
+
+// DeviceDriverService 设备驱动服务接口
+type DeviceDriverService interface {
+	Start()
+	Stop()
+	Refresh()
+	Drivers() []string
+	GetDriverStatus(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceDriverStatusResponse, error)
+	Configure(ctx context.Context, deviceId uuid.UUID, config map[string]any) error
+	Health(ctx context.Context, deviceId uuid.UUID) error
+	SendCommand(ctx context.Context, deviceId uuid.UUID, command strike.Command) (*strike.Ack, error)
+}
+
+// driverSession 设备驱动会话
+type driverSession struct {
+	device    models.DeviceModel
+	signature string // 连接参数签名，变化时重新连接
+	config    string // 已下发的驱动配置
+	session   driver.Session
+	done      chan struct{}
+
+	connectedAt   *time.Time
+	lastCheckedAt *time.Time
+	healthy       bool
+	lastError     string
+}
+
+// deviceDriverService 设备驱动服务实现
+type deviceDriverService struct {
+	db               *gorm.DB
+	commonService    CommonService
+	detectionService DetectionService
+
+	syncInterval time.Duration
+	timeout      time.Duration
+	batchSize    int
+	flushDelay   time.Duration
+
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]*driverSession
+	failures map[uuid.UUID]string // 连接失败原因
+
+	refresh chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+	pumps   sync.WaitGroup
+}
+
+// NewDeviceDriverService 创建设备驱动服务实例
+//
+// 配置项：DRIVER_SYNC_INTERVAL 同步设备配置和健康检查的间隔，DRIVER_TIMEOUT 连接和健康检查超时时间，
+// DRIVER_BATCH_SIZE 与 DRIVER_FLUSH_DELAY 控制驱动侦测数据批量写入。
+func NewDeviceDriverService(db *gorm.DB, commonService CommonService, detectionService DetectionService) DeviceDriverService {
+	return &deviceDriverService{
+		db:               db,
+		commonService:    commonService,
+		detectionService: detectionService,
+		syncInterval:     utils.EnvDuration("DRIVER_SYNC_INTERVAL", 10*time.Second),
+		timeout:          utils.EnvDuration("DRIVER_TIMEOUT", 5*time.Second),
+		batchSize:        max(utils.EnvInt("DRIVER_BATCH_SIZE", 100), 1),
+		flushDelay:       utils.EnvDuration("DRIVER_FLUSH_DELAY", 500*time.Millisecond),
+		sessions:         make(map[uuid.UUID]*driverSession),
+		failures:         make(map[uuid.UUID]string),
+		refresh:          make(chan struct{}, 1),
+	}
+}
+
+// Start 连接已配置驱动的设备，并定期同步设备配置
+func (s *deviceDriverService) Start() {
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.syncInterval)
+		defer ticker.Stop()
+
+		for {
+			s.sync(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.refresh:
+			}
+		}
+	}()
+
+	log.Infof("设备驱动服务已启动，可用驱动: %v", driver.Names())
+}
+
+// Stop 断开全部驱动会话
+func (s *deviceDriverService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+
+	s.mu.Lock()
+	sessions := s.sessions
+	s.sessions = make(map[uuid.UUID]*driverSession)
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		s.disconnect(session)
+	}
+	s.pumps.Wait()
+	s.cancel = nil
+}
+
+// Refresh 设备变更后立即同步，不等待下一个同步周期
+func (s *deviceDriverService) Refresh() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Drivers 可用的全部驱动
+func (s *deviceDriverService) Drivers() []string {
+	return driver.Names()
+}
+
+// GetDriverStatus 获取设备驱动连接状态
+func (s *deviceDriverService) GetDriverStatus(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceDriverStatusResponse, error) {
+	device, err := s.getDevice(ctx, deviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &dto.DeviceDriverStatusResponse{
+		DeviceID: device.ID,
+		Driver:   device.Driver,
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if session, ok := s.sessions[deviceId]; ok {
+		status.Connected = true
+		status.Healthy = session.healthy
+		status.ConnectedAt = session.connectedAt
+		status.LastCheckedAt = session.lastCheckedAt
+		status.LastError = session.lastError
+	} else {
+		status.LastError = s.failures[deviceId]
+	}
+	return status, nil
+}
+
+// Configure 保存驱动配置，驱动已连接时立即下发
+func (s *deviceDriverService) Configure(ctx context.Context, deviceId uuid.UUID, config map[string]any) error {
+	device, err := s.getDevice(ctx, deviceId)
+	if err != nil {
+		return err
+	}
+	if device.Driver == "" {
+		return ErrDeviceNoDriver
+	}
+
+	s.mu.RLock()
+	session, connected := s.sessions[deviceId]
+	s.mu.RUnlock()
+
+	if connected {
+		configCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+		if err := session.session.Configure(configCtx, config); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDriverConfig, err)
+		}
+	}
+
+	driverConfig := models.DriverConfig(config)
+	if err := s.db.WithContext(ctx).Model(device).Update("driver_config", &driverConfig).Error; err != nil {
+		return err
+	}
+
+	if connected {
+		s.mu.Lock()
+		session.config = marshalConfig(&driverConfig)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// Health 检查设备驱动是否正常
+func (s *deviceDriverService) Health(ctx context.Context, deviceId uuid.UUID) error {
+	s.mu.RLock()
+	session, ok := s.sessions[deviceId]
+	s.mu.RUnlock()
+	if !ok {
+		return ErrDriverNotConnected
+	}
+	return session.session.Health(ctx)
+}
+
+// SendCommand 通过设备驱动下发打击指令
+func (s *deviceDriverService) SendCommand(ctx context.Context, deviceId uuid.UUID, command strike.Command) (*strike.Ack, error) {
+	s.mu.RLock()
+	session, ok := s.sessions[deviceId]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrDriverNotConnected
+	}
+	return session.session.Send(ctx, command)
+}
+
+// getDevice 获取设备
+func (s *deviceDriverService) getDevice(ctx context.Context, deviceId uuid.UUID) (*models.DeviceModel, error) {
+	var device models.DeviceModel
+	if err := s.commonService.GetItemByID(ctx, deviceId, &device); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// sync 按设备配置建立、重建或断开驱动会话，并检查已连接会话的健康状态
+func (s *deviceDriverService) sync(ctx context.Context) {
+	var devices []models.DeviceModel
+	if err := s.db.WithContext(ctx).Where("driver <> ''").Find(&devices).Error; err != nil {
+		if ctx.Err() == nil {
+			log.Errorf("加载设备驱动配置失败: %v", err)
+		}
+		return
+	}
+
+	active := make(map[uuid.UUID]struct{}, len(devices))
+	for i := range devices {
+		device := &devices[i]
+		active[device.ID] = struct{}{}
+
+		s.mu.RLock()
+		session, ok := s.sessions[device.ID]
+		s.mu.RUnlock()
+
+		switch {
+		case !ok:
+			s.connect(ctx, device)
+		case session.signature != driverSignature(device):
+			s.remove(device.ID)
+			s.connect(ctx, device)
+		default:
+			s.check(ctx, session, device)
+		}
+	}
+
+	// 断开已删除或已取消驱动的设备
+	s.mu.RLock()
+	var stale []uuid.UUID
+	for deviceID := range s.sessions {
+		if _, ok := active[deviceID]; !ok {
+			stale = append(stale, deviceID)
+		}
+	}
+	s.mu.RUnlock()
+	for _, deviceID := range stale {
+		s.remove(deviceID)
+	}
+
+	s.mu.Lock()
+	for deviceID := range s.failures {
+		if _, ok := active[deviceID]; !ok {
+			delete(s.failures, deviceID)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// connect 建立驱动会话并开始接收侦测数据
+func (s *deviceDriverService) connect(ctx context.Context, device *models.DeviceModel) {
+	fail := func(reason string) {
+		s.mu.Lock()
+		if s.failures[device.ID] != reason {
+			log.Warnf("设备 %s 驱动 %s 连接失败: %s", device.Name, device.Driver, reason)
+		}
+		s.failures[device.ID] = reason
+		s.mu.Unlock()
+	}
+
+	impl, ok := driver.Get(device.Driver)
+	if !ok {
+		fail(ErrUnknownDriver.Error())
+		return
+	}
+
+	var config map[string]any
+	if device.DriverConfig != nil {
+		config = *device.DriverConfig
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	session, err := impl.Connect(connectCtx, driver.Device{
+		ID:               device.ID,
+		Name:             device.Name,
+		Latitude:         device.Latitude,
+		Longitude:        device.Longitude,
+		DetectionAddress: hostPort(device.DetectionIP, device.DetectionPort, 0),
+		StrikeAddress:    strikeAddress(device),
+		Config:           config,
+	})
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+
+	now := time.Now()
+	entry := &driverSession{
+		device:      *device,
+		signature:   driverSignature(device),
+		config:      marshalConfig(device.DriverConfig),
+		session:     session,
+		done:        make(chan struct{}),
+		connectedAt: &now,
+		healthy:     true,
+	}
+
+	s.mu.Lock()
+	s.sessions[device.ID] = entry
+	delete(s.failures, device.ID)
+	s.mu.Unlock()
+
+	s.pumps.Add(1)
+	go s.pump(entry)
+
+	log.Infof("设备 %s 驱动 %s 已连接", device.Name, device.Driver)
+}
+
+// check 下发变更的驱动配置并检查会话健康状态
+func (s *deviceDriverService) check(ctx context.Context, session *driverSession, device *models.DeviceModel) {
+	checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if config := marshalConfig(device.DriverConfig); config != session.config {
+		var values map[string]any
+		if device.DriverConfig != nil {
+			values = *device.DriverConfig
+		}
+		if err := session.session.Configure(checkCtx, values); err != nil {
+			log.Warnf("设备 %s 驱动配置下发失败: %v", device.Name, err)
+		} else {
+			s.mu.Lock()
+			session.config = config
+			s.mu.Unlock()
+		}
+	}
+
+	err := session.session.Health(checkCtx)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if (err == nil) != session.healthy {
+		if err == nil {
+			log.Infof("设备 %s 驱动恢复正常", device.Name)
+		} else {
+			log.Warnf("设备 %s 驱动异常: %v", device.Name, err)
+		}
+	}
+	session.lastCheckedAt = &now
+	session.healthy = err == nil
+	session.lastError = ""
+	if err != nil {
+		session.lastError = truncate(err.Error(), 255)
+	}
+}
+
+// remove 断开并移除设备的驱动会话
+func (s *deviceDriverService) remove(deviceID uuid.UUID) {
+	s.mu.Lock()
+	session, ok := s.sessions[deviceID]
+	delete(s.sessions, deviceID)
+	s.mu.Unlock()
+
+	if ok {
+		s.disconnect(session)
+		log.Infof("设备 %s 驱动 %s 已断开", session.device.Name, session.device.Driver)
+	}
+}
+
+// disconnect 关闭驱动会话并等待侦测数据写入完成
+func (s *deviceDriverService) disconnect(session *driverSession) {
+	if err := session.session.Close(); err != nil {
+		log.Warnf("关闭设备 %s 驱动会话失败: %v", session.device.Name, err)
+	}
+	<-session.done
+}
+
+// pump 将驱动产生的侦测数据批量写入，与 HTTP 上报共用处理流程
+func (s *deviceDriverService) pump(session *driverSession) {
+	defer s.pumps.Done()
+	defer close(session.done)
+
+	batch := make([]dto.DetectionEventRequest, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if _, err := s.detectionService.Ingest(context.Background(), &session.device, dto.IngestDetectionsRequest{Events: batch}); err != nil {
+			log.Errorf("写入设备 %s 驱动侦测数据失败: %v", session.device.Name, err)
+		}
+		batch = make([]dto.DetectionEventRequest, 0, s.batchSize)
+	}
+
+	timer := time.NewTimer(s.flushDelay)
+	defer timer.Stop()
+
+	events := session.session.Events()
+	for {
+		select {
+		case detection, ok := <-events:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, dto.DetectionEventRequest{
+				Timestamp:   detection.Timestamp,
+				TargetID:    detection.TargetID,
+				Frequency:   detection.Frequency,
+				Protocol:    detection.Protocol,
+				Latitude:    detection.Latitude,
+				Longitude:   detection.Longitude,
+				Altitude:    detection.Altitude,
+				Heading:     detection.Heading,
+				Speed:       detection.Speed,
+				RSSI:        detection.RSSI,
+				DroneModel:  detection.DroneModel,
+				DroneSerial: detection.DroneSerial,
+			})
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+			timer.Reset(s.flushDelay)
+		}
+	}
+}
+
+// driverSignature 影响驱动连接的设备参数，变化时需要重新连接
+func driverSignature(device *models.DeviceModel) string {
+	data, _ := sonic.Marshal([]any{
+		device.Driver,
+		device.Name,
+		device.Latitude,
+		device.Longitude,
+		hostPort(device.DetectionIP, device.DetectionPort, 0),
+		strikeAddress(device),
+	})
+	return string(data)
+}
+
+// marshalConfig 序列化驱动配置用于比较
+func marshalConfig(config *models.DriverConfig) string {
+	if config == nil {
+		return ""
+	}
+	data, _ := sonic.ConfigStd.Marshal(config)
+	return string(data)
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrLoginLocked 登录失败次数过多，账号或IP被临时锁定
+var ErrLoginLocked = errors.New("登录失败次数过多，请稍后重试")
+
+// LoginLockedError 登录被锁定的错误，包含剩余锁定时长
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("登录失败次数过多，请在 %d 秒后重试", int64(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// Is 使 errors.Is(err, ErrLoginLocked) 成立
+func (e *LoginLockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
+
+// LoginClient 登录客户端信息
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginAttemptService 登录记录和失败锁定服务接口
+type LoginAttemptService interface {
+	Check(username, ip string) error
+	Record(username string, user *models.UserModel, client LoginClient, loginErr error)
+	Unlock(ctx context.Context, userId uuid.UUID) error
+	GetUserLogins(userId uuid.UUID, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.LoginAttemptModel], error)
+}
+
+// loginLock 账号或IP的连续失败和锁定状态
+type loginLock struct {
+	failures    int       // 窗口内连续失败次数
+	lockCount   int       // 已锁定次数，决定下次锁定时长
+	lockedUntil time.Time // 锁定截止时间
+	expiresAt   time.Time // 状态过期时间，过期后重新计数
+}
+
+// loginAttemptService 登录记录和失败锁定服务实现
+//
+// 锁定状态与令牌撤销列表一样保存在内存中，服务重启后清空；登录记录保存在数据库中。
+type loginAttemptService struct {
+	db            *gorm.DB
+	commonService CommonService
+
+	maxFailures   int
+	ipMaxFailures int
+	window        time.Duration
+	lockDuration  time.Duration
+	maxLock       time.Duration
+
+	mu        sync.Mutex
+	locks     map[string]*loginLock // 键: "user:用户名" 或 "ip:地址"
+	lastSweep time.Time
+}
+
+// NewLoginAttemptService 创建登录记录和失败锁定服务实例
+//
+// 配置项：LOGIN_MAX_FAILURES 账号连续失败多少次后锁定，LOGIN_IP_MAX_FAILURES IP连续失败多少次后锁定，
+// LOGIN_FAILURE_WINDOW 连续失败的计数窗口，LOGIN_LOCK_DURATION 首次锁定时长，之后每次锁定时长翻倍，
+// LOGIN_LOCK_MAX 最长锁定时长。
+func NewLoginAttemptService(db *gorm.DB, commonService CommonService) LoginAttemptService {
+	lockDuration := utils.EnvDuration("LOGIN_LOCK_DURATION", time.Minute)
+	return &loginAttemptService{
+		db:            db,
+		commonService: commonService,
+		maxFailures:   max(utils.EnvInt("LOGIN_MAX_FAILURES", 5), 1),
+		ipMaxFailures: max(utils.EnvInt("LOGIN_IP_MAX_FAILURES", 20), 1),
+		window:        utils.EnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		lockDuration:  lockDuration,
+		maxLock:       max(utils.EnvDuration("LOGIN_LOCK_MAX", time.Hour), lockDuration),
+		locks:         make(map[string]*loginLock),
+	}
+}
+
+// loginAttemptQueryOptions 登录记录列表查询选项
+var loginAttemptQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"success": {Column: "success", Type: FieldBool},
+		"ip":      {Column: "ip", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"created_at": "created_at",
+	},
+	KeywordFields: []string{"ip", "user_agent"},
+	DefaultSort:   "created_at DESC",
+}
+
+// Check 检查账号和IP是否被锁定，锁定时返回 *LoginLockedError
+//
+// 账号按用户名计数，用户名不存在时同样会被锁定，避免通过锁定提示判断账号是否存在。
+func (s *loginAttemptService) Check(username, ip string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range []string{userLockKey(username), ipLockKey(ip)} {
+		if lock, ok := s.locks[key]; ok && lock.lockedUntil.After(now) {
+			retryAfter = max(retryAfter, lock.lockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Record 保存登录记录并更新失败计数，只有用户名、密码或验证码错误计入失败次数
+func (s *loginAttemptService) Record(username string, user *models.UserModel, client LoginClient, loginErr error) {
+	attempt := &models.LoginAttemptModel{
+		Username:  truncate(username, 64),
+		Success:   loginErr == nil,
+		IP:        truncate(client.IP, 64),
+		UserAgent: truncate(client.UserAgent, 255),
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+		attempt.TenantID = user.TenantID
+	}
+	if loginErr != nil {
+		attempt.Reason = truncate(loginErr.Error(), 255)
+	}
+
+	if err := s.db.Create(attempt).Error; err != nil {
+		log.Errorf("保存用户 %s 登录记录失败: %v", username, err)
+	}
+
+	switch {
+	case loginErr == nil:
+		s.reset(username, client.IP)
+	case errors.Is(loginErr, ErrInvalidCredentials) || errors.Is(loginErr, ErrInvalidTwoFactorCode):
+		s.fail(username, client.IP)
+	}
+}
+
+// Unlock 解除用户账号的登录锁定
+func (s *loginAttemptService) Unlock(ctx context.Context, userId uuid.UUID) error {
+	var user models.UserModel
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locks, userLockKey(user.Username))
+	return nil
+}
+
+// GetUserLogins 获取用户的登录记录
+//
+// 登录记录属于用户本人，平台用户选择租户后仍需能看到自身记录，不按租户过滤。
+func (s *loginAttemptService) GetUserLogins(userId uuid.UUID, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.LoginAttemptModel], error) {
+	query := s.db.Model(&models.LoginAttemptModel{}).Where("user_id = ?", userId)
+	return QueryList[models.LoginAttemptModel](query, &req, loginAttemptQueryOptions)
+}
+
+// fail 增加账号和IP的失败次数，达到上限时锁定，锁定时长按锁定次数指数增长
+func (s *loginAttemptService) fail(username, ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	s.failKey(userLockKey(username), s.maxFailures, now)
+	if ip != "" {
+		s.failKey(ipLockKey(ip), s.ipMaxFailures, now)
+	}
+}
+
+// failKey 增加单个键的失败次数
+func (s *loginAttemptService) failKey(key string, limit int, now time.Time) {
+	lock, ok := s.locks[key]
+	if !ok || now.After(lock.expiresAt) {
+		lock = &loginLock{}
+		s.locks[key] = lock
+	}
+
+	lock.failures++
+	if lock.failures >= limit {
+		lock.failures = 0
+		lock.lockCount++
+		lock.lockedUntil = now.Add(s.lockFor(lock.lockCount))
+	}
+	// 锁定期间及解锁后的一个窗口内保留锁定次数，持续失败时锁定时长继续增长
+	lock.expiresAt = now.Add(s.window)
+	if until := lock.lockedUntil.Add(s.window); until.After(lock.expiresAt) {
+		lock.expiresAt = until
+	}
+}
+
+// lockFor 计算第 n 次锁定的时长
+func (s *loginAttemptService) lockFor(n int) time.Duration {
+	d := s.lockDuration
+	for i := 1; i < n && d < s.maxLock; i++ {
+		d *= 2
+	}
+	return min(d, s.maxLock)
+}
+
+// reset 登录成功后清除账号的锁定状态和IP的连续失败次数
+func (s *loginAttemptService) reset(username, ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.locks, userLockKey(username))
+	// IP的锁定次数保留，避免攻击者用自己的账号登录来重置IP的锁定时长
+	if lock, ok := s.locks[ipLockKey(ip)]; ok {
+		lock.failures = 0
+	}
+}
+
+// sweep 清理过期的锁定状态，每个计数窗口最多执行一次
+func (s *loginAttemptService) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.window {
+		return
+	}
+	s.lastSweep = now
+	for key, lock := range s.locks {
+		if now.After(lock.expiresAt) {
+			delete(s.locks, key)
+		}
+	}
+}
+
+// userLockKey 账号锁定状态的键
+func userLockKey(username string) string {
+	return "user:" + username
+}
+
+// ipLockKey IP锁定状态的键
+func ipLockKey(ip string) string {
+	return "ip:" + ip
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"xacms/internal/models"
 	"xacms/internal/routes/dto"
@@ -14,27 +15,58 @@ import (
 
 // MenuService 菜单服务接口
 type MenuService interface {
-	CreateMenu(req *dto.CreateMenuRequest) (*models.MenuModel, error)
-	UpdateMenu(menuUUID uuid.UUID, req *dto.UpdateMenuRequest) (*models.MenuModel, error)
-	GetMenuTree() ([]dto.MenuTreeItem, error)
+	GetMenus(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.MenuModel], error)
+	CreateMenu(ctx context.Context, req *dto.CreateMenuRequest) (*models.MenuModel, error)
+	UpdateMenu(ctx context.Context, menuUUID uuid.UUID, req *dto.UpdateMenuRequest) (*models.MenuModel, error)
+	GetMenuTree(ctx context.Context) ([]dto.MenuTreeItem, error)
 }
 
 // menuService 菜单服务实现
 type menuService struct {
-	db            *gorm.DB
-	commonService CommonService
+	db                *gorm.DB
+	commonService     CommonService
+	permissionService PermissionService
 }
 
 // NewMenuService 创建菜单服务实例
-func NewMenuService(db *gorm.DB, commonService CommonService, fiberServer *server.FiberServer) MenuService {
+func NewMenuService(db *gorm.DB, commonService CommonService, permissionService PermissionService, fiberServer *server.FiberServer) MenuService {
 	return &menuService{
-		db:            db,
-		commonService: commonService,
+		db:                db,
+		commonService:     commonService,
+		permissionService: permissionService,
 	}
 }
 
+// menuQueryOptions 菜单列表查询选项
+var menuQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"parent_id":      {Column: "parent_id", Type: FieldUUID},
+		"name":           {Column: "name", Type: FieldString},
+		"route_name":     {Column: "route_name", Type: FieldString},
+		"route_path":     {Column: "route_path", Type: FieldString},
+		"component":      {Column: "component", Type: FieldString},
+		"is_hidden":      {Column: "is_hidden", Type: FieldBool},
+		"is_full_screen": {Column: "is_full_screen", Type: FieldBool},
+		"is_tabs":        {Column: "is_tabs", Type: FieldBool},
+	},
+	SortFields: map[string]string{
+		"name":       "name",
+		"route_name": "route_name",
+		"order":      "order",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	KeywordFields: []string{"name", "route_name", "route_path"},
+	DefaultSort:   "`order` ASC, created_at DESC",
+}
+
+// GetMenus 获取菜单列表
+func (s *menuService) GetMenus(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.MenuModel], error) {
+	return QueryList[models.MenuModel](s.db.WithContext(ctx).Model(&models.MenuModel{}), &req, menuQueryOptions)
+}
+
 // CreateMenu 创建菜单
-func (s *menuService) CreateMenu(req *dto.CreateMenuRequest) (*models.MenuModel, error) {
+func (s *menuService) CreateMenu(ctx context.Context, req *dto.CreateMenuRequest) (*models.MenuModel, error) {
 
 	menu := &models.MenuModel{
 		ParentID:     req.ParentID,
@@ -50,16 +82,16 @@ func (s *menuService) CreateMenu(req *dto.CreateMenuRequest) (*models.MenuModel,
 		Order:        req.Order,
 	}
 
-	if err := s.db.Create(menu).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(menu).Error; err != nil {
 		return nil, err
 	}
 	return menu, nil
 }
 
 // UpdateMenu 更新菜单
-func (s *menuService) UpdateMenu(menuUUID uuid.UUID, req *dto.UpdateMenuRequest) (*models.MenuModel, error) {
+func (s *menuService) UpdateMenu(ctx context.Context, menuUUID uuid.UUID, req *dto.UpdateMenuRequest) (*models.MenuModel, error) {
 	var menu models.MenuModel
-	if err := s.commonService.GetItemByID(menuUUID, &menu); err != nil {
+	if err := s.commonService.GetItemByID(ctx, menuUUID, &menu); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.New("菜单不存在")
 		}
@@ -104,20 +136,39 @@ func (s *menuService) UpdateMenu(menuUUID uuid.UUID, req *dto.UpdateMenuRequest)
 		menu.Order = *req.Order
 	}
 
-	if err := s.db.Save(menu).Error; err != nil {
+	if err := s.db.WithContext(ctx).Save(&menu).Error; err != nil {
 		return nil, err
 	}
+
+	// 菜单可能被多个角色引用，清除全部权限缓存
+	if req.ApiNames != nil {
+		s.permissionService.InvalidateAll()
+	}
 	return &menu, nil
 }
 
 // GetMenuTree 获取菜单树
-func (s *menuService) GetMenuTree() ([]dto.MenuTreeItem, error) {
+func (s *menuService) GetMenuTree(ctx context.Context) ([]dto.MenuTreeItem, error) {
 	var menus []models.MenuModel
-	if err := s.commonService.GetItems(&menus); err != nil {
+	if err := s.db.WithContext(ctx).Order("`order` ASC, created_at DESC").Find(&menus).Error; err != nil {
 		log.Errorf("获取菜单列表失败: %v", err)
 		return nil, errors.New("获取菜单列表失败")
 	}
 
+	// 按菜单分组按钮
+	var buttons []*models.ButtonModel
+	if err := s.db.WithContext(ctx).Order("`order` ASC, created_at DESC").Find(&buttons).Error; err != nil {
+		log.Errorf("获取按钮列表失败: %v", err)
+		return nil, errors.New("获取按钮列表失败")
+	}
+	menuButtons := make(map[uuid.UUID][]*models.ButtonModel)
+	for _, button := range buttons {
+		menuButtons[button.MenuID] = append(menuButtons[button.MenuID], button)
+	}
+	for i := range menus {
+		menus[i].Buttons = menuButtons[menus[i].ID]
+	}
+
 	// 递归组装菜单树
 	var buildMenuTree func(parentID *uuid.UUID) []dto.MenuTreeItem
 	buildMenuTree = func(parentID *uuid.UUID) []dto.MenuTreeItem {
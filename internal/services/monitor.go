@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/event"
+	"xacms/internal/pkg/probe"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeviceMonitorService 设备在线监测服务接口
+type DeviceMonitorService interface {
+	Start()
+	Stop()
+	GetDeviceStatus(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceStatusResponse, error)
+}
+
+// driverProtocol 由设备驱动检查健康状态的模块使用的探测协议
+const driverProtocol = "driver"
+
+// moduleConfig 模块探测配置
+type moduleConfig struct {
+	protocol    string // 探测协议
+	defaultPort int    // 设备未配置端口时使用的默认端口
+}
+
+// moduleKey 模块状态键
+type moduleKey struct {
+	deviceID uuid.UUID
+	module   models.DeviceModule
+}
+
+// moduleState 模块内存状态
+type moduleState struct {
+	online   bool // 是否在线
+	failures int  // 连续失败次数
+}
+
+// probeTarget 探测目标
+type probeTarget struct {
+	device   *models.DeviceModel
+	module   models.DeviceModule
+	address  string
+	protocol string
+}
+
+// deviceMonitorService 设备在线监测服务实现
+type deviceMonitorService struct {
+	db                  *gorm.DB
+	commonService       CommonService
+	deviceDriverService DeviceDriverService
+	streamService       StreamService
+	bus                 *event.Bus
+
+	enabled          bool
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold int
+	concurrency      int
+	modules          map[models.DeviceModule]moduleConfig
+
+	mu     sync.Mutex
+	states map[moduleKey]*moduleState
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDeviceMonitorService 创建设备在线监测服务实例
+//
+// 配置项：MONITOR_ENABLED、MONITOR_INTERVAL、MONITOR_TIMEOUT、MONITOR_FAILURE_THRESHOLD、
+// MONITOR_CONCURRENCY，以及各模块的 MONITOR_<MODULE>_PROTOCOL 和 MONITOR_<MODULE>_PORT。
+func NewDeviceMonitorService(db *gorm.DB, commonService CommonService, deviceDriverService DeviceDriverService, streamService StreamService, bus *event.Bus) DeviceMonitorService {
+	defaults := map[models.DeviceModule]moduleConfig{
+		models.DeviceModuleDetection: {protocol: "tcp"},
+		models.DeviceModuleAnalysis:  {protocol: "tcp", defaultPort: 80},
+		models.DeviceModuleFPV:       {protocol: "tcp", defaultPort: 554},
+		models.DeviceModuleStream:    {protocol: "tcp", defaultPort: 1935},
+		models.DeviceModuleStrike:    {protocol: "tcp"},
+	}
+
+	modules := make(map[models.DeviceModule]moduleConfig, len(defaults))
+	for module, config := range defaults {
+		prefix := "MONITOR_" + strings.ToUpper(string(module)) + "_"
+		protocol := utils.EnvString(prefix+"PROTOCOL", config.protocol)
+		if _, ok := probe.Get(protocol); !ok {
+			log.Warnf("不支持的探测协议 %s，模块 %s 使用 tcp", protocol, module)
+			protocol = "tcp"
+		}
+		modules[module] = moduleConfig{
+			protocol:    protocol,
+			defaultPort: utils.EnvInt(prefix+"PORT", config.defaultPort),
+		}
+	}
+
+	return &deviceMonitorService{
+		db:                  db,
+		commonService:       commonService,
+		deviceDriverService: deviceDriverService,
+		streamService:       streamService,
+		bus:                 bus,
+		enabled:             utils.EnvBool("MONITOR_ENABLED", true),
+		interval:            utils.EnvDuration("MONITOR_INTERVAL", 30*time.Second),
+		timeout:             utils.EnvDuration("MONITOR_TIMEOUT", 3*time.Second),
+		failureThreshold:    max(utils.EnvInt("MONITOR_FAILURE_THRESHOLD", 2), 1),
+		concurrency:         max(utils.EnvInt("MONITOR_CONCURRENCY", 16), 1),
+		modules:             modules,
+		states:              make(map[moduleKey]*moduleState),
+	}
+}
+
+// Start 启动后台监测
+func (s *deviceMonitorService) Start() {
+	if !s.enabled || s.cancel != nil {
+		return
+	}
+
+	// 加载已记录的状态，避免重启后重复发出状态变化事件
+	var statuses []models.DeviceModuleStatusModel
+	if err := s.db.Find(&statuses).Error; err != nil {
+		log.Errorf("加载设备模块状态失败: %v", err)
+	}
+	for _, status := range statuses {
+		s.states[moduleKey{status.DeviceID, status.Module}] = &moduleState{online: status.Online}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.checkAll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Infof("设备在线监测已启动，探测间隔 %s", s.interval)
+}
+
+// Stop 停止后台监测并等待当前一轮探测结束
+func (s *deviceMonitorService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+}
+
+// GetDeviceStatus 获取设备各模块在线状态
+func (s *deviceMonitorService) GetDeviceStatus(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceStatusResponse, error) {
+	var device models.DeviceModel
+	if err := s.commonService.GetItemByID(ctx, deviceId, &device); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	modules := []models.DeviceModuleStatusModel{}
+	if err := s.db.WithContext(ctx).Where("device_id = ?", deviceId).Order("module ASC").Find(&modules).Error; err != nil {
+		return nil, err
+	}
+
+	// 全部已探测模块在线时设备视为在线
+	online := len(modules) > 0
+	for _, module := range modules {
+		online = online && module.Online
+	}
+
+	return &dto.DeviceStatusResponse{
+		DeviceID: deviceId,
+		Online:   online,
+		Modules:  modules,
+	}, nil
+}
+
+// checkAll 探测全部设备的全部模块
+func (s *deviceMonitorService) checkAll(ctx context.Context) {
+	var devices []models.DeviceModel
+	if err := s.db.WithContext(ctx).Find(&devices).Error; err != nil {
+		if ctx.Err() == nil {
+			log.Errorf("加载设备列表失败: %v", err)
+		}
+		return
+	}
+
+	var targets []probeTarget
+	for i := range devices {
+		for _, module := range models.DeviceModules {
+			config := s.modules[module]
+			address, protocol := s.address(&devices[i], module, config), config.protocol
+			// 侦测和打击模块由驱动接管时检查驱动健康状态
+			if devices[i].Driver != "" && (module == models.DeviceModuleDetection || module == models.DeviceModuleStrike) {
+				address, protocol = "driver:"+devices[i].Driver, driverProtocol
+			}
+			// 视频流通过流媒体服务器 RTSP 检查是否可播放
+			if module == models.DeviceModuleVideo {
+				address, protocol = s.streamService.ProbeAddress(&devices[i]), "rtsp"
+			}
+			if address == "" {
+				continue
+			}
+			targets = append(targets, probeTarget{
+				device:   &devices[i],
+				module:   module,
+				address:  address,
+				protocol: protocol,
+			})
+		}
+	}
+
+	// 并发探测，限制同时进行的探测数量
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, s.concurrency)
+	for _, target := range targets {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(target probeTarget) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			s.check(ctx, target)
+		}(target)
+	}
+	wg.Wait()
+
+	if ctx.Err() == nil {
+		s.purge(targets)
+	}
+}
+
+// check 探测单个模块并记录结果
+func (s *deviceMonitorService) check(ctx context.Context, target probeTarget) {
+	probeCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	switch {
+	case target.protocol == driverProtocol:
+		err = s.deviceDriverService.Health(probeCtx, target.device.ID)
+	case target.module == models.DeviceModuleVideo:
+		err = s.streamService.Probe(probeCtx, target.device)
+	default:
+		prober, _ := probe.Get(target.protocol)
+		err = prober.Probe(probeCtx, target.address)
+	}
+	latency := time.Since(start)
+
+	// 服务停止导致的失败不记录
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.record(target, err, start, latency)
+}
+
+// record 更新模块状态，状态变化时发布事件
+func (s *deviceMonitorService) record(target probeTarget, probeErr error, checkedAt time.Time, latency time.Duration) {
+	key := moduleKey{target.device.ID, target.module}
+
+	s.mu.Lock()
+	state, known := s.states[key]
+	if !known {
+		state = &moduleState{}
+		s.states[key] = state
+	}
+	previous := state.online
+	if probeErr == nil {
+		state.failures = 0
+		state.online = true
+	} else {
+		state.failures++
+		// 连续失败达到阈值才判定离线，避免网络抖动造成状态反复变化
+		if !known || state.failures >= s.failureThreshold {
+			state.online = false
+		}
+	}
+	online := state.online
+	s.mu.Unlock()
+
+	status := models.DeviceModuleStatusModel{
+		DeviceID:      target.device.ID,
+		Module:        target.module,
+		Address:       target.address,
+		Protocol:      target.protocol,
+		Online:        online,
+		LastCheckedAt: &checkedAt,
+		LatencyMs:     latency.Milliseconds(),
+	}
+	columns := []string{"address", "protocol", "online", "last_checked_at", "latency_ms", "last_error", "updated_at"}
+	if probeErr == nil {
+		status.LastSeenAt = &checkedAt
+		columns = append(columns, "last_seen_at")
+	} else {
+		status.LastError = truncate(probeErr.Error(), 255)
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}, {Name: "module"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(&status).Error
+	if err != nil {
+		log.Errorf("保存设备 %s 模块 %s 状态失败: %v", target.device.Name, target.module, err)
+	}
+
+	if known && previous == online {
+		return
+	}
+
+	if online {
+		log.Infof("设备 %s 模块 %s 上线: %s", target.device.Name, target.module, target.address)
+	} else {
+		log.Warnf("设备 %s 模块 %s 离线: %s，%s", target.device.Name, target.module, target.address, status.LastError)
+	}
+
+	payload := event.DeviceStatusPayload{
+		DeviceID:   target.device.ID,
+		DeviceName: target.device.Name,
+		Module:     string(target.module),
+		Address:    target.address,
+		Online:     online,
+		LastSeenAt: status.LastSeenAt,
+		Error:      status.LastError,
+	}
+	if known {
+		payload.Previous = &previous
+	}
+	s.bus.Publish(event.Event{
+		Topic:    event.TopicDeviceStatus,
+		TenantID: target.device.TenantID,
+		Time:     checkedAt,
+		Payload:  payload,
+	})
+}
+
+// purge 清理已删除设备或已取消配置模块的状态
+func (s *deviceMonitorService) purge(targets []probeTarget) {
+	active := make(map[moduleKey]struct{}, len(targets))
+	for _, target := range targets {
+		active[moduleKey{target.device.ID, target.module}] = struct{}{}
+	}
+
+	s.mu.Lock()
+	var stale []moduleKey
+	for key := range s.states {
+		if _, ok := active[key]; !ok {
+			stale = append(stale, key)
+			delete(s.states, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, key := range stale {
+		if err := s.db.Where("device_id = ? AND module = ?", key.deviceID, key.module).Delete(&models.DeviceModuleStatusModel{}).Error; err != nil {
+			log.Errorf("清理设备模块状态失败: %v", err)
+		}
+	}
+}
+
+// address 获取模块探测地址，未配置时返回空字符串
+func (s *deviceMonitorService) address(device *models.DeviceModel, module models.DeviceModule, config moduleConfig) string {
+	switch module {
+	case models.DeviceModuleDetection:
+		return hostPort(device.DetectionIP, device.DetectionPort, config.defaultPort)
+	case models.DeviceModuleAnalysis:
+		return hostPort(device.AnalysisIP, 0, config.defaultPort)
+	case models.DeviceModuleFPV:
+		return hostPort(device.FPVIP, 0, config.defaultPort)
+	case models.DeviceModuleStream:
+		return hostPort(device.StreamServerIP, 0, config.defaultPort)
+	case models.DeviceModuleStrike:
+		return hostPort(device.StrikeIP, device.StrikePort, config.defaultPort)
+	default:
+		return ""
+	}
+}
+
+// hostPort 组合主机和端口，主机已包含端口时直接使用
+func hostPort(host string, port, defaultPort int) string {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	if port <= 0 {
+		port = defaultPort
+	}
+	if port <= 0 {
+		return ""
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"sync"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PermissionService 权限服务接口
+type PermissionService interface {
+	HasPermission(userID uuid.UUID, routeName string) (bool, error)
+	GetRoleApiNames(roleID uuid.UUID) ([]string, error)
+	GetUserPermissions(userID uuid.UUID) (*dto.UserPermissionsResponse, error)
+	InvalidateRole(roleID uuid.UUID)
+	InvalidateAll()
+}
+
+// roleGrants 角色授权缓存项
+type roleGrants struct {
+	isSuper  bool                // 是否超级管理员
+	apiNames map[string]struct{} // 已授权的API名称
+}
+
+// add 添加已授权的API名称
+func (g *roleGrants) add(apiNames *models.ApiNames) {
+	if apiNames == nil {
+		return
+	}
+	for _, name := range *apiNames {
+		g.apiNames[name] = struct{}{}
+	}
+}
+
+// permissionService 权限服务实现
+type permissionService struct {
+	db *gorm.DB
+
+	mu         sync.RWMutex
+	cache      map[uuid.UUID]*roleGrants // 键: 角色ID, 值: 角色授权
+	generation uint64                    // 缓存版本，每次失效时递增
+}
+
+// NewPermissionService 创建权限服务实例
+func NewPermissionService(db *gorm.DB) PermissionService {
+	return &permissionService{
+		db:    db,
+		cache: make(map[uuid.UUID]*roleGrants),
+	}
+}
+
+// HasPermission 判断用户是否有权访问指定名称的路由
+func (s *permissionService) HasPermission(userID uuid.UUID, routeName string) (bool, error) {
+	// 每次从数据库读取用户，保证角色变更和禁用立即生效
+	var user models.UserModel
+	if err := s.db.Select("id", "status", "role_id").First(&user, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if user.Status == nil || !user.Status.IsEnabled() || user.RoleID == nil {
+		return false, nil
+	}
+
+	grants, err := s.getRoleGrants(*user.RoleID)
+	if err != nil {
+		return false, err
+	}
+
+	if grants.isSuper {
+		return true, nil
+	}
+
+	_, ok := grants.apiNames[routeName]
+	return ok, nil
+}
+
+// GetRoleApiNames 获取角色已授权的API名称
+func (s *permissionService) GetRoleApiNames(roleID uuid.UUID) ([]string, error) {
+	grants, err := s.getRoleGrants(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiNames := make([]string, 0, len(grants.apiNames))
+	for name := range grants.apiNames {
+		apiNames = append(apiNames, name)
+	}
+	return apiNames, nil
+}
+
+// GetUserPermissions 获取用户可访问的菜单和按钮编码，供前端控制菜单和按钮显示
+func (s *permissionService) GetUserPermissions(userID uuid.UUID) (*dto.UserPermissionsResponse, error) {
+	resp := &dto.UserPermissionsResponse{
+		Menus:       []models.MenuModel{},
+		ButtonCodes: []string{},
+	}
+
+	var user models.UserModel
+	if err := s.db.Select("id", "role_id").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	if user.RoleID == nil {
+		return resp, nil
+	}
+
+	var role models.RoleModel
+	if err := s.db.First(&role, "id = ?", *user.RoleID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	var buttons []models.ButtonModel
+	if role.IsSuper {
+		// 超级管理员拥有全部菜单和按钮
+		resp.IsSuper = true
+		if err := s.db.Order("`order` ASC, created_at DESC").Find(&resp.Menus).Error; err != nil {
+			return nil, err
+		}
+		if err := s.db.Find(&buttons).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.db.Model(&role).Order("`order` ASC, created_at DESC").Association("Menus").Find(&resp.Menus); err != nil {
+			return nil, err
+		}
+		if err := s.db.Model(&role).Association("Buttons").Find(&buttons); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, button := range buttons {
+		resp.ButtonCodes = append(resp.ButtonCodes, button.Code)
+	}
+	return resp, nil
+}
+
+// InvalidateRole 使单个角色的授权缓存失效
+func (s *permissionService) InvalidateRole(roleID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.cache, roleID)
+	s.generation++
+}
+
+// InvalidateAll 使全部授权缓存失效
+func (s *permissionService) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache = make(map[uuid.UUID]*roleGrants)
+	s.generation++
+}
+
+// getRoleGrants 获取角色授权，优先读取缓存
+func (s *permissionService) getRoleGrants(roleID uuid.UUID) (*roleGrants, error) {
+	s.mu.RLock()
+	grants, ok := s.cache[roleID]
+	generation := s.generation
+	s.mu.RUnlock()
+	if ok {
+		return grants, nil
+	}
+
+	grants, err := s.loadRoleGrants(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 加载期间缓存已失效时不写入，避免缓存旧授权
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache[roleID] = grants
+	}
+	s.mu.Unlock()
+
+	return grants, nil
+}
+
+// loadRoleGrants 从数据库加载角色授权，合并角色所有菜单和按钮的 ApiNames
+func (s *permissionService) loadRoleGrants(roleID uuid.UUID) (*roleGrants, error) {
+	grants := &roleGrants{
+		apiNames: make(map[string]struct{}),
+	}
+
+	var role models.RoleModel
+	if err := s.db.Preload("Menus").Preload("Buttons").First(&role, "id = ?", roleID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// 角色不存在时缓存空授权
+			return grants, nil
+		}
+		return nil, err
+	}
+
+	grants.isSuper = role.IsSuper
+	for _, menu := range role.Menus {
+		grants.add(menu.ApiNames)
+	}
+	for _, button := range role.Buttons {
+		grants.add(button.ApiNames)
+	}
+	return grants, nil
+}
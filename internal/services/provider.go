@@ -8,4 +8,25 @@ var ServicesSet = wire.NewSet(
 	NewMenuService,
 	NewCommonService,
 	NewDeviceService,
+	NewAuthService,
+	NewPermissionService,
+	NewButtonService,
+	NewTenantService,
+	NewDepartmentService,
+	NewDataScopeService,
+	NewDeviceMonitorService,
+	NewDetectionService,
+	NewTrackService,
+	NewRealtimeService,
+	NewStrikeService,
+	NewDeviceDriverService,
+	NewStreamService,
+	NewZoneService,
+	NewAlertService,
+	NewWhitelistService,
+	NewAuditService,
+	NewLoginAttemptService,
+	NewTwoFactorService,
+	NewApiKeyService,
+	NewSessionService,
 )
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 默认分页参数
+const (
+	defaultPage     = 1
+	defaultPageSize = 20
+)
+
+// FieldType 过滤字段类型，用于转换查询参数值
+type FieldType int
+
+const (
+	FieldString FieldType = iota // 字符串
+	FieldNumber                  // 数字
+	FieldBool                    // 布尔
+	FieldUUID                    // UUID
+)
+
+// FilterField 允许过滤的字段
+type FilterField struct {
+	Column string    // 数据库列
+	Type   FieldType // 字段类型
+}
+
+// QueryOptions 模型的列表查询选项，过滤、排序和关键字搜索均只允许白名单中的字段
+type QueryOptions struct {
+	FilterFields  map[string]FilterField // 允许过滤的字段，键: 查询参数名
+	SortFields    map[string]string      // 允许排序的字段，键: 查询参数名, 值: 数据库列
+	KeywordFields []string               // 关键字搜索的数据库列
+	DefaultSort   string                 // 未指定排序时的 ORDER BY 子句
+	Preloads      []string               // 需要预加载的关联
+}
+
+// filterOperators 过滤操作符，键: 查询参数中的操作符, 值: SQL 操作符
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"like": "LIKE",
+	"in":   "IN",
+	"null": "IS NULL",
+}
+
+// likeEscaper 转义 LIKE 通配符，配合 ESCAPE '\' 按字面匹配用户输入
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern 生成包含匹配的 LIKE 模式
+func containsPattern(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
+
+// QueryList 按通用列表查询请求分页查询
+func QueryList[T any](query *gorm.DB, req *dto.ListQueryRequest, opts QueryOptions) (*dto.PaginatedResponse[T], error) {
+	query, order, err := applyListQuery(query, req, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 总数
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// 分页参数
+	page := req.Page
+	if page <= 0 {
+		page = defaultPage
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	offset := (page - 1) * pageSize
+
+	for _, preload := range opts.Preloads {
+		query = query.Preload(preload)
+	}
+
+	items := make([]T, 0, pageSize)
+	if err := query.Order(order).Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedResponse[T]{
+		Total: total,
+		Items: items,
+	}, nil
+}
+
+// applyListQuery 应用列表查询请求的过滤和关键字搜索条件，返回排序子句
+func applyListQuery(query *gorm.DB, req *dto.ListQueryRequest, opts QueryOptions) (*gorm.DB, string, error) {
+	query, err := applyFilters(query, req.Filters, opts.FilterFields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 关键字搜索
+	if req.Keyword != "" && len(opts.KeywordFields) > 0 {
+		keyword := containsPattern(req.Keyword)
+		conditions := make([]string, 0, len(opts.KeywordFields))
+		args := make([]any, 0, len(opts.KeywordFields))
+		for _, column := range opts.KeywordFields {
+			conditions = append(conditions, "`"+column+"` LIKE ? ESCAPE '\\'")
+			args = append(args, keyword)
+		}
+		query = query.Where(strings.Join(conditions, " OR "), args...)
+	}
+
+	// 排序
+	order, err := parseSort(req.Sort, opts.SortFields, opts.DefaultSort)
+	if err != nil {
+		return nil, "", err
+	}
+	return query, order, nil
+}
+
+// applyFilters 应用字段过滤条件
+//
+// 带操作符的参数（字段__操作符）必须是允许过滤的字段；不带操作符且不在白名单中的参数
+// 不视为过滤条件，直接忽略，如防缓存的 "_" 参数。
+func applyFilters(query *gorm.DB, filters map[string]string, allowed map[string]FilterField) (*gorm.DB, error) {
+	for key, raw := range filters {
+		name, op := key, "eq"
+		if i := strings.LastIndex(key, "__"); i > 0 {
+			name, op = key[:i], key[i+2:]
+		} else if _, ok := allowed[name]; !ok {
+			continue
+		}
+
+		field, ok := allowed[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: 不支持按 %s 过滤", ErrInvalidQuery, name)
+		}
+
+		sqlOp, ok := filterOperators[op]
+		if !ok {
+			return nil, fmt.Errorf("%w: 不支持的过滤操作符 %s", ErrInvalidQuery, op)
+		}
+
+		column := "`" + field.Column + "`"
+		switch op {
+		case "null":
+			isNull, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s 的值必须是 true 或 false", ErrInvalidQuery, key)
+			}
+			if isNull {
+				query = query.Where(column + " IS NULL")
+			} else {
+				query = query.Where(column + " IS NOT NULL")
+			}
+
+		case "in":
+			var values []any
+			for _, item := range strings.Split(raw, ",") {
+				value, err := convertFilterValue(field.Type, strings.TrimSpace(item))
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s 的值无效", ErrInvalidQuery, key)
+				}
+				values = append(values, value)
+			}
+			query = query.Where(column+" IN ?", values)
+
+		case "like":
+			if field.Type != FieldString {
+				return nil, fmt.Errorf("%w: %s 不支持模糊匹配", ErrInvalidQuery, name)
+			}
+			query = query.Where(column+" LIKE ? ESCAPE '\\'", containsPattern(raw))
+
+		default:
+			value, err := convertFilterValue(field.Type, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s 的值无效", ErrInvalidQuery, key)
+			}
+			query = query.Where(column+" "+sqlOp+" ?", value)
+		}
+	}
+	return query, nil
+}
+
+// convertFilterValue 按字段类型转换查询参数值
+func convertFilterValue(fieldType FieldType, raw string) (any, error) {
+	switch fieldType {
+	case FieldNumber:
+		// 优先按整数解析，避免整数列与浮点数比较时出现类型转换差异
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v, nil
+		}
+		return strconv.ParseFloat(raw, 64)
+	case FieldBool:
+		return strconv.ParseBool(raw)
+	case FieldUUID:
+		return uuid.Parse(raw)
+	default:
+		return raw, nil
+	}
+}
+
+// parseSort 将排序参数转换为 ORDER BY 子句
+//
+// 排序参数为逗号分隔的字段列表，字段前加 - 表示降序，如 "-created_at,username"。
+// 只允许 allowed 中的字段，未指定排序时使用 fallback。
+func parseSort(sort string, allowed map[string]string, fallback string) (string, error) {
+	if strings.TrimSpace(sort) == "" {
+		return fallback, nil
+	}
+
+	var orders []string
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+
+		column, ok := allowed[field]
+		if !ok {
+			return "", fmt.Errorf("%w: 不支持按 %s 排序", ErrInvalidQuery, field)
+		}
+		orders = append(orders, "`"+column+"` "+direction)
+	}
+
+	if len(orders) == 0 {
+		return fallback, nil
+	}
+	return strings.Join(orders, ", "), nil
+}
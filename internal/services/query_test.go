@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"xacms/internal/routes/dto"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryTestRow 测试用模型
+type queryTestRow struct {
+	ID     int
+	Name   string
+	Age    int
+	Active bool
+}
+
+// queryTestFields 测试用过滤字段白名单
+var queryTestFields = map[string]FilterField{
+	"name":   {Column: "name", Type: FieldString},
+	"age":    {Column: "age", Type: FieldNumber},
+	"active": {Column: "active", Type: FieldBool},
+	"owner":  {Column: "owner_id", Type: FieldUUID},
+}
+
+// newDryRunDB 创建只生成 SQL 不执行的数据库连接
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		DryRun: true,
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	return db
+}
+
+// buildSQL 应用过滤条件并返回生成的 SQL
+func buildSQL(t *testing.T, filters map[string]string) (string, error) {
+	t.Helper()
+	sql, _, err := buildStatement(t, filters)
+	return sql, err
+}
+
+// buildStatement 应用过滤条件并返回生成的 SQL 和参数
+func buildStatement(t *testing.T, filters map[string]string) (string, []any, error) {
+	t.Helper()
+	query, err := applyFilters(newDryRunDB(t).Model(&queryTestRow{}), filters, queryTestFields)
+	if err != nil {
+		return "", nil, err
+	}
+	var rows []queryTestRow
+	stmt := query.Find(&rows).Statement
+	return stmt.SQL.String(), stmt.Vars, nil
+}
+
+func TestApplyFiltersRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]string
+	}{
+		{"未知字段带操作符", map[string]string{"password__eq": "x"}},
+		{"带操作符的列名注入", map[string]string{"name`; DROP TABLE users; --__eq": "x"}},
+		{"未知操作符", map[string]string{"age__between": "1"}},
+		{"操作符注入", map[string]string{"age__gt OR 1=1": "1"}},
+		{"数字字段非数字", map[string]string{"age": "abc"}},
+		{"数字字段 in 非数字", map[string]string{"age__in": "1,abc"}},
+		{"布尔字段非布尔", map[string]string{"active": "maybe"}},
+		{"UUID 字段格式错误", map[string]string{"owner": "not-a-uuid"}},
+		{"null 非布尔", map[string]string{"name__null": "yes"}},
+		{"非字符串字段模糊匹配", map[string]string{"age__like": "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildSQL(t, tt.filters)
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Fatalf("期望 ErrInvalidQuery，实际 %v", err)
+			}
+		})
+	}
+}
+
+func TestApplyFiltersBuildsConditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]string
+		want    string
+	}{
+		{"默认等于", map[string]string{"name": "bob"}, "`name` = "},
+		{"比较操作符", map[string]string{"age__gte": "18"}, "`age` >= "},
+		{"不等于", map[string]string{"age__ne": "18"}, "`age` <> "},
+		{"in", map[string]string{"age__in": "1, 2,3"}, "`age` IN ("},
+		{"模糊匹配", map[string]string{"name__like": "bo"}, "`name` LIKE "},
+		{"为空", map[string]string{"owner__null": "true"}, "`owner_id` IS NULL"},
+		{"不为空", map[string]string{"owner__null": "false"}, "`owner_id` IS NOT NULL"},
+		{"布尔", map[string]string{"active": "true"}, "`active` = "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := buildSQL(t, tt.filters)
+			if err != nil {
+				t.Fatalf("期望成功，实际 %v", err)
+			}
+			if !strings.Contains(sql, tt.want) {
+				t.Fatalf("SQL %q 中缺少 %q", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyFiltersIgnoresUndeclaredParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]string
+	}{
+		{"防缓存参数", map[string]string{"_": "1699999999"}},
+		{"未知字段", map[string]string{"password": "x"}},
+		{"列名注入", map[string]string{"name`; DROP TABLE users; --": "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := buildSQL(t, tt.filters)
+			if err != nil {
+				t.Fatalf("期望成功，实际 %v", err)
+			}
+			if strings.Contains(sql, "WHERE") {
+				t.Fatalf("SQL %q 不应包含过滤条件", sql)
+			}
+		})
+	}
+}
+
+func TestApplyFiltersEscapesLikePattern(t *testing.T) {
+	sql, vars, err := buildStatement(t, map[string]string{"name__like": `50%_off\`})
+	if err != nil {
+		t.Fatalf("期望成功，实际 %v", err)
+	}
+	if !strings.Contains(sql, "LIKE ? ESCAPE '\\'") {
+		t.Fatalf("SQL %q 中缺少 ESCAPE 子句", sql)
+	}
+	if want := `%50\%\_off\\%`; len(vars) != 1 || vars[0] != want {
+		t.Fatalf("期望参数 %q，实际 %v", want, vars)
+	}
+}
+
+func TestApplyListQueryEscapesKeyword(t *testing.T) {
+	req := &dto.ListQueryRequest{}
+	req.Keyword = "a_b%"
+	opts := QueryOptions{KeywordFields: []string{"name"}, DefaultSort: "id ASC"}
+	query, _, err := applyListQuery(newDryRunDB(t).Model(&queryTestRow{}), req, opts)
+	if err != nil {
+		t.Fatalf("期望成功，实际 %v", err)
+	}
+	var rows []queryTestRow
+	stmt := query.Find(&rows).Statement
+	if want := `%a\_b\%%`; len(stmt.Vars) != 1 || stmt.Vars[0] != want {
+		t.Fatalf("期望参数 %q，实际 %v", want, stmt.Vars)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	allowed := map[string]string{
+		"name":       "name",
+		"created_at": "created_at",
+	}
+	const fallback = "created_at DESC"
+
+	tests := []struct {
+		name    string
+		sort    string
+		want    string
+		invalid bool
+	}{
+		{"未指定使用默认排序", "", fallback, false},
+		{"只有空白使用默认排序", " , ", fallback, false},
+		{"升序", "name", "`name` ASC", false},
+		{"降序", "-created_at", "`created_at` DESC", false},
+		{"多个字段", "-created_at, name", "`created_at` DESC, `name` ASC", false},
+		{"未在白名单中", "password", "", true},
+		{"部分字段未在白名单中", "name,password", "", true},
+		{"排序注入", "name; DROP TABLE users", "", true},
+		{"表达式", "(CASE WHEN 1=1 THEN name END)", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSort(tt.sort, allowed, fallback)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Fatalf("期望 ErrInvalidQuery，实际 %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("期望成功，实际 %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("期望 %q，实际 %q", tt.want, got)
+			}
+		})
+	}
+}
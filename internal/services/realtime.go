@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"xacms/internal/pkg/event"
+	"xacms/internal/pkg/realtime"
+	"xacms/internal/utils"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownTopic = errors.New("未知的订阅主题")
+	ErrTopicDenied  = errors.New("无权订阅该主题")
+)
+
+// realtimeTopics 可订阅的主题及订阅所需的路由权限
+var realtimeTopics = map[string]string{
+	event.TopicDeviceStatus: "设备管理.获取设备状态",
+	event.TopicDetection:    "侦测事件.获取侦测事件列表",
+	event.TopicTrack:        "航迹管理.获取航迹列表",
+	event.TopicStrike:       "打击管理.获取打击指令列表",
+	event.TopicAlert:        "告警管理.获取告警列表",
+}
+
+// RealtimeService 实时推送服务接口
+type RealtimeService interface {
+	Start()
+	Stop()
+	Topics() []string
+	Authorize(userId uuid.UUID, topics []string) error
+	Connect(tenantId *uuid.UUID) *realtime.Client
+	Disconnect(client *realtime.Client)
+}
+
+// realtimeService 实时推送服务实现
+type realtimeService struct {
+	bus               *event.Bus
+	permissionService PermissionService
+	hub               *realtime.Hub
+
+	unsubscribes []func()
+}
+
+// NewRealtimeService 创建实时推送服务实例
+//
+// 配置项：REALTIME_BUFFER_SIZE 每个客户端待发送事件队列长度，队列满时断开该客户端。
+func NewRealtimeService(bus *event.Bus, permissionService PermissionService) RealtimeService {
+	return &realtimeService{
+		bus:               bus,
+		permissionService: permissionService,
+		hub:               realtime.NewHub(utils.EnvInt("REALTIME_BUFFER_SIZE", 256)),
+	}
+}
+
+// Start 订阅事件总线，将事件分发给客户端
+func (s *realtimeService) Start() {
+	if s.unsubscribes != nil {
+		return
+	}
+	for topic := range realtimeTopics {
+		s.unsubscribes = append(s.unsubscribes, s.bus.Subscribe(topic, s.hub.Broadcast))
+	}
+	log.Infof("实时推送已启动，可订阅主题: %v", s.Topics())
+}
+
+// Stop 取消订阅并断开全部客户端
+func (s *realtimeService) Stop() {
+	if s.unsubscribes == nil {
+		return
+	}
+	for _, unsubscribe := range s.unsubscribes {
+		unsubscribe()
+	}
+	s.unsubscribes = nil
+	s.hub.Close("服务正在关闭")
+}
+
+// Topics 可订阅的全部主题
+func (s *realtimeService) Topics() []string {
+	topics := make([]string, 0, len(realtimeTopics))
+	for topic := range realtimeTopics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Authorize 校验用户是否有权订阅全部主题
+func (s *realtimeService) Authorize(userId uuid.UUID, topics []string) error {
+	for _, topic := range topics {
+		routeName, ok := realtimeTopics[topic]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownTopic, topic)
+		}
+
+		allowed, err := s.permissionService.HasPermission(userId, routeName)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s", ErrTopicDenied, topic)
+		}
+	}
+	return nil
+}
+
+// Connect 注册客户端，tenantId 不为空时只接收该租户的事件
+func (s *realtimeService) Connect(tenantId *uuid.UUID) *realtime.Client {
+	return s.hub.Register(tenantId)
+}
+
+// Disconnect 注销客户端
+func (s *realtimeService) Disconnect(client *realtime.Client) {
+	s.hub.Unregister(client)
+}
@@ -1,55 +1,97 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"xacms/internal/models"
+	"xacms/internal/pkg/audit"
 	"xacms/internal/routes/dto"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrRoleNotFound 角色不存在
+var ErrRoleNotFound = errors.New("角色不存在")
+
 // RoleService 角色服务接口
 type RoleService interface {
-	CreateRole(req dto.CreateRoleRequest) (*models.RoleModel, error)
-	UpdateRole(roleId uuid.UUID, req dto.UpdateRoleRequest) (*models.RoleModel, error)
-	GetRoleMenus(roleId uuid.UUID) ([]models.MenuModel, error)
-	AssignMenus(roleId uuid.UUID, req dto.AssignMenusRequest) (*models.RoleModel, error)
+	GetRoles(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.RoleModel], error)
+	CreateRole(ctx context.Context, req dto.CreateRoleRequest) (*models.RoleModel, error)
+	UpdateRole(ctx context.Context, roleId uuid.UUID, req dto.UpdateRoleRequest) (*models.RoleModel, error)
+	GetRoleMenus(ctx context.Context, roleId uuid.UUID) ([]models.MenuModel, error)
+	AssignMenus(ctx context.Context, roleId uuid.UUID, req dto.AssignMenusRequest) (*models.RoleModel, error)
+	GetRoleButtons(ctx context.Context, roleId uuid.UUID) ([]models.ButtonModel, error)
+	AssignButtons(ctx context.Context, roleId uuid.UUID, req dto.AssignButtonsRequest) (*models.RoleModel, error)
 }
 
 // roleService 角色服务实现
 type roleService struct {
-	db            *gorm.DB
-	commonService CommonService
+	db                *gorm.DB
+	commonService     CommonService
+	permissionService PermissionService
 }
 
 // NewRoleService 创建角色服务实例
-func NewRoleService(db *gorm.DB, commonService CommonService) RoleService {
+func NewRoleService(db *gorm.DB, commonService CommonService, permissionService PermissionService) RoleService {
 	return &roleService{
-		db:            db,
-		commonService: commonService,
+		db:                db,
+		commonService:     commonService,
+		permissionService: permissionService,
 	}
 }
 
+// roleQueryOptions 角色列表查询选项
+var roleQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"name":        {Column: "name", Type: FieldString},
+		"description": {Column: "description", Type: FieldString},
+		"is_super":    {Column: "is_super", Type: FieldBool},
+		"data_scope":  {Column: "data_scope", Type: FieldNumber},
+		"created_at":  {Column: "created_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"name":       "name",
+		"order":      "order",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	KeywordFields: []string{"name", "description"},
+	DefaultSort:   "`order` ASC, created_at DESC",
+}
+
+// GetRoles 获取角色列表
+func (s *roleService) GetRoles(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.RoleModel], error) {
+	return QueryList[models.RoleModel](s.db.WithContext(ctx).Model(&models.RoleModel{}), &req, roleQueryOptions)
+}
+
 // CreateRole 创建角色
-func (s *roleService) CreateRole(req dto.CreateRoleRequest) (*models.RoleModel, error) {
+func (s *roleService) CreateRole(ctx context.Context, req dto.CreateRoleRequest) (*models.RoleModel, error) {
+	dataScope := models.DataScopeAll
+	if req.DataScope != 0 {
+		dataScope = req.DataScope
+	}
+
 	role := &models.RoleModel{
 		Name:        req.Name,
 		Description: req.Description,
 		Order:       req.Order,
+		DataScope:   dataScope,
+
+		RequireTwoFactor: req.RequireTwoFactor,
 	}
-	if err := s.db.Create(role).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(role).Error; err != nil {
 		return nil, err
 	}
 	return role, nil
 }
 
 // UpdateRole 更新角色
-func (s *roleService) UpdateRole(roleId uuid.UUID, req dto.UpdateRoleRequest) (*models.RoleModel, error) {
+func (s *roleService) UpdateRole(ctx context.Context, roleId uuid.UUID, req dto.UpdateRoleRequest) (*models.RoleModel, error) {
 	var role models.RoleModel
-	if err := s.commonService.GetItemByID(roleId, &role); err != nil {
+	if err := s.commonService.GetItemByID(ctx, roleId, &role); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("角色不存在")
+			return nil, ErrRoleNotFound
 		}
 		return nil, err
 	}
@@ -66,41 +108,108 @@ func (s *roleService) UpdateRole(roleId uuid.UUID, req dto.UpdateRoleRequest) (*
 		role.Order = *req.Order
 	}
 
-	if err := s.db.Save(&role).Error; err != nil {
+	if req.DataScope != nil {
+		role.DataScope = *req.DataScope
+	}
+
+	if req.RequireTwoFactor != nil {
+		role.RequireTwoFactor = *req.RequireTwoFactor
+	}
+
+	if err := s.db.WithContext(ctx).Save(&role).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
 // GetRoleMenus 获取角色菜单列表
-func (s *roleService) GetRoleMenus(roleId uuid.UUID) ([]models.MenuModel, error) {
+func (s *roleService) GetRoleMenus(ctx context.Context, roleId uuid.UUID) ([]models.MenuModel, error) {
+	// 先在当前租户内查找角色，关联查询不会按租户过滤
+	var role models.RoleModel
+	if err := s.commonService.GetItemByID(ctx, roleId, &role); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
 	var menus []models.MenuModel
-	if err := s.db.Model(&models.RoleModel{ID: roleId}).Association("Menus").Find(&menus); err != nil {
+	if err := s.db.WithContext(ctx).Model(&role).Association("Menus").Find(&menus); err != nil {
 		return nil, err
 	}
 	return menus, nil
 }
 
 // AssignMenus 分配菜单给角色
-func (s *roleService) AssignMenus(roleId uuid.UUID, req dto.AssignMenusRequest) (*models.RoleModel, error) {
+func (s *roleService) AssignMenus(ctx context.Context, roleId uuid.UUID, req dto.AssignMenusRequest) (*models.RoleModel, error) {
 	var role models.RoleModel
-	if err := s.commonService.GetItemByID(roleId, &role); err != nil {
+	if err := s.commonService.GetItemByID(ctx, roleId, &role); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("角色不存在")
+			return nil, ErrRoleNotFound
 		}
 		return nil, err
 	}
 
 	// 获取菜单实例
 	var menus []models.MenuModel
-	if err := s.db.Where("id IN ?", req.MenuIDs).Find(&menus).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("id IN ?", req.MenuIDs).Find(&menus).Error; err != nil {
 		return nil, err
 	}
 
 	// 更新角色菜单
-	if err := s.db.Model(&role).Association("Menus").Replace(menus); err != nil {
+	if err := audit.ReplaceAssociation(s.db.WithContext(ctx), &role, "Menus", menus); err != nil {
 		return nil, err
 	}
 
+	// 角色授权已变化，清除权限缓存
+	s.permissionService.InvalidateRole(roleId)
+
+	return &role, nil
+}
+
+// GetRoleButtons 获取角色按钮列表
+func (s *roleService) GetRoleButtons(ctx context.Context, roleId uuid.UUID) ([]models.ButtonModel, error) {
+	// 先在当前租户内查找角色，关联查询不会按租户过滤
+	var role models.RoleModel
+	if err := s.commonService.GetItemByID(ctx, roleId, &role); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	var buttons []models.ButtonModel
+	if err := s.db.WithContext(ctx).Model(&role).Association("Buttons").Find(&buttons); err != nil {
+		return nil, err
+	}
+	return buttons, nil
+}
+
+// AssignButtons 分配按钮给角色
+func (s *roleService) AssignButtons(ctx context.Context, roleId uuid.UUID, req dto.AssignButtonsRequest) (*models.RoleModel, error) {
+	var role models.RoleModel
+	if err := s.commonService.GetItemByID(ctx, roleId, &role); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	// 获取按钮实例
+	var buttons []models.ButtonModel
+	if len(req.ButtonIDs) > 0 {
+		if err := s.db.WithContext(ctx).Where("id IN ?", req.ButtonIDs).Find(&buttons).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// 更新角色按钮
+	if err := audit.ReplaceAssociation(s.db.WithContext(ctx), &role, "Buttons", buttons); err != nil {
+		return nil, err
+	}
+
+	// 角色授权已变化，清除权限缓存
+	s.permissionService.InvalidateRole(roleId)
+
 	return &role, nil
 }
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"xacms/internal/models"
+	"xacms/internal/pkg/audit"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newAuditTestDB 创建启用审计插件的内存数据库
+func newAuditTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if err := db.Use(audit.Plugin{}); err != nil {
+		t.Fatalf("注册审计插件失败: %v", err)
+	}
+
+	// 内存数据库每个连接独立，只使用一个连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&models.RoleModel{}, &models.MenuModel{}, &models.ButtonModel{}); err != nil {
+		t.Fatalf("迁移数据库失败: %v", err)
+	}
+	return db
+}
+
+func TestAssignMenusRecordsRoleUpdate(t *testing.T) {
+	db := newAuditTestDB(t)
+	service := NewRoleService(db, NewCommonService(db, nil, nil), NewPermissionService(db))
+
+	role := models.RoleModel{Name: "operator"}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("创建角色失败: %v", err)
+	}
+	menus := make([]models.MenuModel, 3)
+	for i := range menus {
+		menus[i] = models.MenuModel{Name: "menu", RouteName: uuid.NewString(), RoutePath: "/", Component: "view"}
+		if err := db.Create(&menus[i]).Error; err != nil {
+			t.Fatalf("创建菜单失败: %v", err)
+		}
+	}
+
+	// 初始菜单不记录
+	initial := dto.AssignMenusRequest{MenuIDs: []uuid.UUID{menus[0].ID, menus[1].ID}}
+	if _, err := service.AssignMenus(context.Background(), role.ID, initial); err != nil {
+		t.Fatalf("分配菜单失败: %v", err)
+	}
+
+	recorder := &audit.Recorder{}
+	ctx := audit.WithRecorder(context.Background(), recorder)
+	req := dto.AssignMenusRequest{MenuIDs: []uuid.UUID{menus[1].ID, menus[2].ID}}
+	if _, err := service.AssignMenus(ctx, role.ID, req); err != nil {
+		t.Fatalf("分配菜单失败: %v", err)
+	}
+
+	sorted := func(ids ...uuid.UUID) []string {
+		result := make([]string, 0, len(ids))
+		for _, id := range ids {
+			result = append(result, id.String())
+		}
+		slices.Sort(result)
+		return result
+	}
+	want := []audit.Change{{
+		Table:  "roles",
+		ID:     role.ID.String(),
+		Action: audit.ActionUpdate,
+		Before: map[string]any{"menus": sorted(menus[0].ID, menus[1].ID)},
+		After:  map[string]any{"menus": sorted(menus[1].ID, menus[2].ID)},
+	}}
+	if got := recorder.Changes(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("期望记录 %+v，实际 %+v", want, got)
+	}
+
+	// 菜单未变化时不记录
+	recorder = &audit.Recorder{}
+	ctx = audit.WithRecorder(context.Background(), recorder)
+	if _, err := service.AssignMenus(ctx, role.ID, req); err != nil {
+		t.Fatalf("分配菜单失败: %v", err)
+	}
+	if got := recorder.Changes(); len(got) != 0 {
+		t.Fatalf("期望不记录，实际 %+v", got)
+	}
+}
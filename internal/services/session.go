@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/token"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval 最近活动时间的更新间隔，避免每个请求都写数据库
+const sessionTouchInterval = time.Minute
+
+// ErrSessionNotFound 会话不存在或已失效
+var ErrSessionNotFound = errors.New("会话不存在或已失效")
+
+// SessionService 登录会话服务接口
+type SessionService interface {
+	Create(sessionId uuid.UUID, user *models.UserModel, client LoginClient, expiresAt time.Time) error
+	Extend(sessionId uuid.UUID, expiresAt time.Time) error
+	ValidateSession(claims *token.Claims, ip string) (bool, error)
+	GetUserSessions(userId uuid.UUID, currentSession string) ([]models.SessionModel, error)
+	RevokeSession(userId, sessionId uuid.UUID) error
+	RevokeUserSessions(userId uuid.UUID) error
+	ForceLogout(ctx context.Context, userId uuid.UUID) error
+}
+
+// sessionService 登录会话服务实现
+//
+// 每次登录创建一个会话，访问令牌和刷新令牌携带会话ID；会话保存在数据库中，
+// 撤销后其令牌立即失效，服务重启后依然有效。
+type sessionService struct {
+	db            *gorm.DB
+	commonService CommonService
+	tokenManager  *token.Manager
+}
+
+// NewSessionService 创建登录会话服务实例
+func NewSessionService(db *gorm.DB, commonService CommonService, tokenManager *token.Manager) SessionService {
+	return &sessionService{
+		db:            db,
+		commonService: commonService,
+		tokenManager:  tokenManager,
+	}
+}
+
+// Create 登录成功后创建会话，过期时间与刷新令牌一致
+func (s *sessionService) Create(sessionId uuid.UUID, user *models.UserModel, client LoginClient, expiresAt time.Time) error {
+	now := time.Now().UTC()
+	ip := truncate(client.IP, 64)
+	session := &models.SessionModel{
+		ID:           sessionId,
+		TenantID:     user.TenantID,
+		UserID:       user.ID,
+		IP:           ip,
+		UserAgent:    truncate(client.UserAgent, 255),
+		LastActiveAt: now,
+		LastActiveIP: ip,
+		ExpiresAt:    expiresAt.UTC(),
+	}
+	return s.db.Create(session).Error
+}
+
+// Extend 刷新令牌后延长会话有效期
+func (s *sessionService) Extend(sessionId uuid.UUID, expiresAt time.Time) error {
+	return s.db.Model(&models.SessionModel{}).Where("id = ?", sessionId).
+		UpdateColumns(map[string]any{"expires_at": expiresAt.UTC(), "last_active_at": time.Now().UTC()}).Error
+}
+
+// ValidateSession 校验令牌所属会话属于令牌用户且未撤销、未过期，有效时按间隔更新最近活动时间和IP
+func (s *sessionService) ValidateSession(claims *token.Claims, ip string) (bool, error) {
+	sessionId, err := uuid.Parse(claims.Session)
+	if err != nil {
+		return false, nil
+	}
+
+	var session models.SessionModel
+	if err := s.db.First(&session, "id = ?", sessionId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	now := time.Now()
+	if session.UserID != claims.UserID || !session.Active(now) {
+		return false, nil
+	}
+
+	if ip != "" && (now.Sub(session.LastActiveAt) >= sessionTouchInterval || session.LastActiveIP != ip) {
+		err := s.db.Model(&models.SessionModel{}).Where("id = ?", session.ID).
+			UpdateColumns(map[string]any{"last_active_at": now.UTC(), "last_active_ip": truncate(ip, 64)}).Error
+		if err != nil {
+			log.Errorf("更新会话 %s 活动时间失败: %v", session.ID, err)
+		}
+	}
+	return true, nil
+}
+
+// GetUserSessions 获取用户未撤销、未过期的会话，并标记当前会话
+//
+// 会话属于用户本人，平台用户选择租户后仍需能看到自身会话，不按租户过滤。
+func (s *sessionService) GetUserSessions(userId uuid.UUID, currentSession string) ([]models.SessionModel, error) {
+	var sessions []models.SessionModel
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now().UTC()).
+		Order("last_active_at DESC").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == currentSession
+	}
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的单个会话，会话签发的令牌随即失效
+func (s *sessionService) RevokeSession(userId, sessionId uuid.UUID) error {
+	result := s.db.Model(&models.SessionModel{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionId, userId, time.Now().UTC()).
+		UpdateColumn("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions 撤销用户的全部会话，用于修改密码、禁用用户等场景
+func (s *sessionService) RevokeUserSessions(userId uuid.UUID) error {
+	err := s.db.Model(&models.SessionModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		UpdateColumn("revoked_at", time.Now().UTC()).Error
+	if err != nil {
+		return err
+	}
+
+	// 同时在内存中撤销，不依赖会话校验
+	s.tokenManager.RevokeUser(userId)
+	return nil
+}
+
+// ForceLogout 管理员强制用户下线
+func (s *sessionService) ForceLogout(ctx context.Context, userId uuid.UUID) error {
+	var user models.UserModel
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return s.RevokeUserSessions(user.ID)
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/password"
+	"xacms/internal/pkg/probe"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrStreamNotConfigured = errors.New("设备未配置流媒体服务器")
+	ErrStreamUnauthorized  = errors.New("流媒体鉴权失败")
+	ErrStreamLocked        = errors.New("推流鉴权失败次数过多，请稍后重试")
+)
+
+// streamProbeTTL 在线监测探测视频流时使用的签名有效期
+const streamProbeTTL = time.Minute
+
+// StreamService 视频流服务接口
+//
+// 播放地址的查询参数带有 expires 和 sign，由流媒体服务器通过鉴权回调交给 Authorize 校验；
+// 回调请求格式与 MediaMTX 的 HTTP 鉴权一致，地址格式默认也与 MediaMTX 一致。
+// 鉴权回调是公开接口，需携带与 STREAM_AUTH_TOKEN 一致的回调令牌，由 VerifyCallback 校验。
+type StreamService interface {
+	GetStream(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceStreamResponse, error)
+	VerifyCallback(token string) bool
+	Authorize(req dto.StreamAuthRequest) error
+	ProbeAddress(device *models.DeviceModel) string
+	Probe(ctx context.Context, device *models.DeviceModel) error
+}
+
+// streamService 视频流服务实现
+type streamService struct {
+	db            *gorm.DB
+	commonService CommonService
+	hasher        password.Hasher
+
+	secret       []byte
+	ttl          time.Duration
+	publicHost   string
+	httpScheme   string
+	hlsPort      int
+	webrtcPort   int
+	rtspPort     int
+	publishPorts map[string]int
+
+	callbackToken []byte
+
+	publishMaxFailures int
+	publishWindow      time.Duration
+	publishLockFor     time.Duration
+
+	mu           sync.Mutex
+	publishLocks map[string]*publishLock // 键: 流路径
+	lastSweep    time.Time
+}
+
+// publishLock 流路径推流鉴权的连续失败和锁定状态
+type publishLock struct {
+	failures    int       // 窗口内连续失败次数
+	lockedUntil time.Time // 锁定截止时间
+	expiresAt   time.Time // 状态过期时间，过期后重新计数
+}
+
+// NewStreamService 创建视频流服务实例
+//
+// 配置项：STREAM_SIGN_SECRET、STREAM_URL_TTL、STREAM_PUBLIC_HOST、STREAM_HTTP_SCHEME、
+// STREAM_HLS_PORT、STREAM_WEBRTC_PORT、STREAM_RTSP_PORT、STREAM_RTMP_PORT、STREAM_SRT_PORT、
+// STREAM_AUTH_TOKEN 鉴权回调令牌，STREAM_PUBLISH_MAX_FAILURES 同一流路径推流鉴权连续失败多少次后锁定，
+// STREAM_PUBLISH_FAILURE_WINDOW 连续失败的计数窗口，STREAM_PUBLISH_LOCK_DURATION 锁定时长。
+func NewStreamService(db *gorm.DB, commonService CommonService, hasher password.Hasher) StreamService {
+	secret := []byte(utils.EnvString("STREAM_SIGN_SECRET", ""))
+	if len(secret) == 0 {
+		// 未配置密钥时随机生成，重启后已签发的播放地址全部失效
+		log.Warn("未配置 STREAM_SIGN_SECRET，使用随机密钥")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("生成视频流签名密钥失败: %v", err)
+		}
+	}
+
+	callbackToken := utils.EnvString("STREAM_AUTH_TOKEN", "")
+	if callbackToken == "" {
+		log.Warn("未配置 STREAM_AUTH_TOKEN，流媒体鉴权回调将拒绝所有请求")
+	}
+
+	rtspPort := utils.EnvInt("STREAM_RTSP_PORT", 8554)
+	return &streamService{
+		db:            db,
+		commonService: commonService,
+		hasher:        hasher,
+		secret:        secret,
+		ttl:           utils.EnvDuration("STREAM_URL_TTL", 5*time.Minute),
+		publicHost:    utils.EnvString("STREAM_PUBLIC_HOST", ""),
+		httpScheme:    utils.EnvString("STREAM_HTTP_SCHEME", "http"),
+		hlsPort:       utils.EnvInt("STREAM_HLS_PORT", 8888),
+		webrtcPort:    utils.EnvInt("STREAM_WEBRTC_PORT", 8889),
+		rtspPort:      rtspPort,
+		publishPorts: map[string]int{
+			"rtsp": rtspPort,
+			"rtmp": utils.EnvInt("STREAM_RTMP_PORT", 1935),
+			"srt":  utils.EnvInt("STREAM_SRT_PORT", 8890),
+		},
+		callbackToken:      []byte(callbackToken),
+		publishMaxFailures: max(utils.EnvInt("STREAM_PUBLISH_MAX_FAILURES", 5), 1),
+		publishWindow:      utils.EnvDuration("STREAM_PUBLISH_FAILURE_WINDOW", 15*time.Minute),
+		publishLockFor:     utils.EnvDuration("STREAM_PUBLISH_LOCK_DURATION", 5*time.Minute),
+		publishLocks:       make(map[string]*publishLock),
+	}
+}
+
+// GetStream 获取设备视频流的签名播放地址
+func (s *streamService) GetStream(ctx context.Context, deviceId uuid.UUID) (*dto.DeviceStreamResponse, error) {
+	var device models.DeviceModel
+	if err := s.commonService.GetItemByID(ctx, deviceId, &device); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	host := s.serverHost(&device)
+	if host == "" {
+		return nil, ErrStreamNotConfigured
+	}
+	if s.publicHost != "" {
+		host = s.publicHost
+	}
+
+	path := device.StreamPathOrDefault()
+	expiresAt := time.Now().Add(s.ttl).Truncate(time.Second)
+	query := s.signQuery(path, expiresAt)
+
+	protocol := device.StreamProtocol
+	if protocol == "" {
+		protocol = "rtsp"
+	}
+
+	return &dto.DeviceStreamResponse{
+		DeviceID:   device.ID,
+		Path:       path,
+		Protocol:   protocol,
+		PublishURL: s.publishURL(host, protocol, path),
+		URLs: dto.DeviceStreamURLs{
+			HLS:    s.httpScheme + "://" + hostPort(host, s.hlsPort, 0) + "/" + path + "/index.m3u8?" + query,
+			WebRTC: s.httpScheme + "://" + hostPort(host, s.webrtcPort, 0) + "/" + path + "/whep?" + query,
+			RTSP:   "rtsp://" + hostPort(host, s.rtspPort, 0) + "/" + path + "?" + query,
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyCallback 校验鉴权回调令牌，未配置 STREAM_AUTH_TOKEN 时一律拒绝
+func (s *streamService) VerifyCallback(token string) bool {
+	if len(s.callbackToken) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare(s.callbackToken, []byte(token)) == 1
+}
+
+// Authorize 校验流媒体服务器的鉴权回调：播放需有效签名，推流需设备的推流凭据
+//
+// 同一流路径推流鉴权连续失败过多时临时锁定，锁定期间不再校验凭据，直接返回 ErrStreamLocked。
+func (s *streamService) Authorize(req dto.StreamAuthRequest) error {
+	path := strings.Trim(req.Path, "/")
+
+	switch req.Action {
+	case "read", "playback":
+		values, err := url.ParseQuery(req.Query)
+		if err != nil {
+			return ErrStreamUnauthorized
+		}
+		if !s.verify(path, values.Get("expires"), values.Get("sign")) {
+			return ErrStreamUnauthorized
+		}
+		return nil
+
+	case "publish":
+		if s.publishLocked(path) {
+			return ErrStreamLocked
+		}
+		err := s.authorizePublish(path, req)
+		switch {
+		case err == nil:
+			s.publishSucceeded(path)
+		case errors.Is(err, ErrStreamUnauthorized):
+			s.publishFailed(path)
+		}
+		return err
+
+	default:
+		return ErrStreamUnauthorized
+	}
+}
+
+// authorizePublish 校验设备的推流凭据
+func (s *streamService) authorizePublish(path string, req dto.StreamAuthRequest) error {
+	device, err := s.findByPath(path)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrStreamUnauthorized
+		}
+		return err
+	}
+	// 未设置推流密码的设备不允许推流
+	if device.StreamPasswordHash == "" || subtle.ConstantTimeCompare([]byte(device.StreamUsername), []byte(req.User)) != 1 {
+		return ErrStreamUnauthorized
+	}
+	ok, err := s.hasher.Verify(device.StreamPasswordHash, req.Password)
+	if err != nil || !ok {
+		return ErrStreamUnauthorized
+	}
+	return nil
+}
+
+// publishLocked 判断流路径的推流鉴权是否被锁定
+func (s *streamService) publishLocked(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.publishLocks[path]
+	return ok && lock.lockedUntil.After(time.Now())
+}
+
+// publishFailed 增加流路径的推流鉴权失败次数，达到上限时锁定
+func (s *streamService) publishFailed(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	lock, ok := s.publishLocks[path]
+	if !ok || now.After(lock.expiresAt) {
+		lock = &publishLock{}
+		s.publishLocks[path] = lock
+	}
+
+	lock.failures++
+	if lock.failures >= s.publishMaxFailures {
+		lock.failures = 0
+		lock.lockedUntil = now.Add(s.publishLockFor)
+		log.Warnf("流路径 %s 推流鉴权连续失败，锁定 %s", path, s.publishLockFor)
+	}
+	lock.expiresAt = now.Add(s.publishWindow)
+	if lock.lockedUntil.After(lock.expiresAt) {
+		lock.expiresAt = lock.lockedUntil
+	}
+}
+
+// publishSucceeded 推流鉴权成功后清除流路径的失败次数
+func (s *streamService) publishSucceeded(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.publishLocks, path)
+}
+
+// sweep 清理过期的推流锁定状态，每个计数窗口最多执行一次
+func (s *streamService) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.publishWindow {
+		return
+	}
+	s.lastSweep = now
+	for path, lock := range s.publishLocks {
+		if now.After(lock.expiresAt) {
+			delete(s.publishLocks, path)
+		}
+	}
+}
+
+// ProbeAddress 获取视频流探测地址（不含签名），未配置流媒体服务器时返回空字符串
+func (s *streamService) ProbeAddress(device *models.DeviceModel) string {
+	host := s.serverHost(device)
+	if host == "" {
+		return ""
+	}
+	return "rtsp://" + hostPort(host, s.rtspPort, 0) + "/" + device.StreamPathOrDefault()
+}
+
+// Probe 通过 RTSP DESCRIBE 检查设备视频流是否可播放
+func (s *streamService) Probe(ctx context.Context, device *models.DeviceModel) error {
+	address := s.ProbeAddress(device)
+	if address == "" {
+		return ErrStreamNotConfigured
+	}
+
+	prober, _ := probe.Get("rtsp")
+	return prober.Probe(ctx, address+"?"+s.signQuery(device.StreamPathOrDefault(), time.Now().Add(streamProbeTTL)))
+}
+
+// serverHost 获取流媒体服务器主机，忽略配置中的端口
+func (s *streamService) serverHost(device *models.DeviceModel) string {
+	host := strings.TrimSpace(device.StreamServerIP)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// publishURL 获取 FPV 模块推流地址
+func (s *streamService) publishURL(host, protocol, path string) string {
+	address := hostPort(host, s.publishPorts[protocol], 0)
+	switch protocol {
+	case "srt":
+		return "srt://" + address + "?streamid=publish:" + path
+	default:
+		return protocol + "://" + address + "/" + path
+	}
+}
+
+// findByPath 根据流路径查找设备，鉴权回调不带租户信息
+func (s *streamService) findByPath(path string) (*models.DeviceModel, error) {
+	var device models.DeviceModel
+	if id, ok := strings.CutPrefix(path, models.DefaultStreamPathPrefix); ok {
+		deviceId, err := uuid.Parse(id)
+		if err != nil {
+			return nil, gorm.ErrRecordNotFound
+		}
+		if err := s.db.First(&device, "id = ? AND stream_path = ''", deviceId).Error; err != nil {
+			return nil, err
+		}
+		return &device, nil
+	}
+
+	if err := s.db.First(&device, "stream_path = ?", path).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// signQuery 生成带过期时间和签名的查询参数
+func (s *streamService) signQuery(path string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	values := url.Values{}
+	values.Set("expires", expires)
+	values.Set("sign", s.sign(path, expires))
+	return values.Encode()
+}
+
+// verify 校验签名及有效期
+func (s *streamService) verify(path, expires, sign string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(sign), []byte(s.sign(path, expires)))
+}
+
+// sign 计算流路径和过期时间的签名
+func (s *streamService) sign(path, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(path + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
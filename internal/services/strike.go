@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/event"
+	"xacms/internal/pkg/identity"
+	"xacms/internal/pkg/strike"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrStrikeNotFound      = errors.New("打击指令不存在")
+	ErrStrikeNotPending    = errors.New("打击指令不在待审批状态")
+	ErrStrikeExpired       = errors.New("打击指令审批已超时")
+	ErrStrikeSelfApproval  = errors.New("不能审批自己申请的打击指令")
+	ErrStrikeNotConfigured = errors.New("设备未配置打击模块")
+	ErrStrikeUnknownUser   = errors.New("无法确定当前用户")
+)
+
+// StrikeService 打击指令服务接口
+type StrikeService interface {
+	Start()
+	Stop()
+	GetStrikes(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.StrikeCommandModel], error)
+	GetStrike(ctx context.Context, strikeId uuid.UUID) (*models.StrikeCommandModel, error)
+	RequestStrike(ctx context.Context, req *dto.CreateStrikeRequest) (*models.StrikeCommandModel, error)
+	ApproveStrike(ctx context.Context, strikeId uuid.UUID, req *dto.StrikeDecisionRequest) (*models.StrikeCommandModel, error)
+	RejectStrike(ctx context.Context, strikeId uuid.UUID, req *dto.StrikeDecisionRequest) (*models.StrikeCommandModel, error)
+}
+
+// strikeService 打击指令服务实现
+type strikeService struct {
+	db                  *gorm.DB
+	commonService       CommonService
+	deviceDriverService DeviceDriverService
+	bus                 *event.Bus
+
+	driver          strike.Driver
+	approvalTimeout time.Duration
+	sendTimeout     time.Duration
+	expireInterval  time.Duration
+
+	dispatching sync.WaitGroup
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewStrikeService 创建打击指令服务实例
+//
+// 配置项：STRIKE_DRIVER 打击模块驱动，STRIKE_APPROVAL_TIMEOUT 审批时限，
+// STRIKE_SEND_TIMEOUT 下发并等待确认的超时时间，STRIKE_EXPIRE_INTERVAL 检查审批超时的间隔。
+func NewStrikeService(db *gorm.DB, commonService CommonService, deviceDriverService DeviceDriverService, bus *event.Bus) StrikeService {
+	driverName := utils.EnvString("STRIKE_DRIVER", "tcp")
+	driver, ok := strike.Get(driverName)
+	if !ok {
+		log.Warnf("不支持的打击模块驱动 %s，使用 tcp", driverName)
+		driver, _ = strike.Get("tcp")
+	}
+
+	return &strikeService{
+		db:                  db,
+		commonService:       commonService,
+		deviceDriverService: deviceDriverService,
+		bus:                 bus,
+		driver:              driver,
+		approvalTimeout:     utils.EnvDuration("STRIKE_APPROVAL_TIMEOUT", 2*time.Minute),
+		sendTimeout:         utils.EnvDuration("STRIKE_SEND_TIMEOUT", 10*time.Second),
+		expireInterval:      utils.EnvDuration("STRIKE_EXPIRE_INTERVAL", 10*time.Second),
+	}
+}
+
+// strikeQueryOptions 打击指令列表查询选项
+var strikeQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"device_id":    {Column: "device_id", Type: FieldUUID},
+		"track_id":     {Column: "track_id", Type: FieldUUID},
+		"action":       {Column: "action", Type: FieldString},
+		"status":       {Column: "status", Type: FieldString},
+		"requested_by": {Column: "requested_by", Type: FieldUUID},
+		"decided_by":   {Column: "decided_by", Type: FieldUUID},
+	},
+	SortFields: map[string]string{
+		"created_at": "created_at",
+		"expires_at": "expires_at",
+		"decided_at": "decided_at",
+		"sent_at":    "sent_at",
+	},
+	KeywordFields: []string{"reason", "decision_note", "result"},
+	DefaultSort:   "created_at DESC",
+}
+
+// Start 启动审批超时检查
+func (s *strikeService) Start() {
+	if s.cancel != nil {
+		return
+	}
+
+	// 重启前已批准但未完成的指令不再自动下发，避免重复打击
+	s.failInterrupted()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.expireInterval)
+		defer ticker.Stop()
+
+		for {
+			s.expirePending()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Infof("打击指令服务已启动，审批时限 %s", s.approvalTimeout)
+}
+
+// Stop 停止审批超时检查并等待下发中的指令完成
+func (s *strikeService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.dispatching.Wait()
+	s.cancel = nil
+}
+
+// GetStrikes 获取打击指令列表
+func (s *strikeService) GetStrikes(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.StrikeCommandModel], error) {
+	return QueryList[models.StrikeCommandModel](s.db.WithContext(ctx).Model(&models.StrikeCommandModel{}), &req, strikeQueryOptions)
+}
+
+// GetStrike 获取打击指令详情及状态变更记录
+func (s *strikeService) GetStrike(ctx context.Context, strikeId uuid.UUID) (*models.StrikeCommandModel, error) {
+	var command models.StrikeCommandModel
+	err := s.db.WithContext(ctx).
+		Preload("Logs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		First(&command, "id = ?", strikeId).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrStrikeNotFound
+		}
+		return nil, err
+	}
+	return &command, nil
+}
+
+// RequestStrike 申请打击，需由另一名用户在审批时限内批准后才会下发
+func (s *strikeService) RequestStrike(ctx context.Context, req *dto.CreateStrikeRequest) (*models.StrikeCommandModel, error) {
+	userID, ok := identity.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrStrikeUnknownUser
+	}
+
+	var device models.DeviceModel
+	if err := s.commonService.GetItemByID(ctx, req.DeviceID, &device); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+	if device.Driver == "" && strikeAddress(&device) == "" {
+		return nil, ErrStrikeNotConfigured
+	}
+
+	if req.TrackID != nil {
+		var track models.TrackModel
+		if err := s.commonService.GetItemByID(ctx, *req.TrackID, &track); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrTrackNotFound
+			}
+			return nil, err
+		}
+	}
+
+	command := &models.StrikeCommandModel{
+		TenantID:    device.TenantID,
+		DeviceID:    device.ID,
+		TrackID:     req.TrackID,
+		Action:      models.StrikeAction(req.Action),
+		Duration:    req.Duration,
+		Reason:      req.Reason,
+		Status:      models.StrikeStatusRequested,
+		RequestedBy: userID,
+		ExpiresAt:   time.Now().Add(s.approvalTimeout).UTC(),
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(command).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.StrikeCommandLogModel{
+			TenantID:  command.TenantID,
+			CommandID: command.ID,
+			ToStatus:  models.StrikeStatusRequested,
+			ActorID:   &userID,
+			Message:   req.Reason,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("用户 %s 申请打击指令 %s: 设备 %s，方式 %s", userID, command.ID, device.Name, command.Action)
+	s.publish(command)
+	return command, nil
+}
+
+// ApproveStrike 批准打击指令并异步下发到打击模块，申请人不能批准自己的申请
+func (s *strikeService) ApproveStrike(ctx context.Context, strikeId uuid.UUID, req *dto.StrikeDecisionRequest) (*models.StrikeCommandModel, error) {
+	command, userID, err := s.pending(ctx, strikeId)
+	if err != nil {
+		return nil, err
+	}
+	if command.RequestedBy == userID {
+		return nil, ErrStrikeSelfApproval
+	}
+
+	now := time.Now().UTC()
+	err = s.transition(s.db.WithContext(ctx), command, models.StrikeStatusApproved, map[string]any{
+		"decided_by":    userID,
+		"decided_at":    now,
+		"decision_note": req.Note,
+	}, &userID, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("用户 %s 批准打击指令 %s", userID, command.ID)
+
+	s.dispatching.Add(1)
+	go func() {
+		defer s.dispatching.Done()
+		s.dispatch(*command)
+	}()
+
+	return command, nil
+}
+
+// RejectStrike 驳回打击指令，申请人可驳回自己的申请以撤销
+func (s *strikeService) RejectStrike(ctx context.Context, strikeId uuid.UUID, req *dto.StrikeDecisionRequest) (*models.StrikeCommandModel, error) {
+	command, userID, err := s.pending(ctx, strikeId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = s.transition(s.db.WithContext(ctx), command, models.StrikeStatusRejected, map[string]any{
+		"decided_by":    userID,
+		"decided_at":    now,
+		"decision_note": req.Note,
+		"completed_at":  now,
+	}, &userID, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("用户 %s 驳回打击指令 %s", userID, command.ID)
+	return command, nil
+}
+
+// pending 获取待审批的打击指令，已超时的指令标记为超时
+func (s *strikeService) pending(ctx context.Context, strikeId uuid.UUID) (*models.StrikeCommandModel, uuid.UUID, error) {
+	userID, ok := identity.UserIDFromContext(ctx)
+	if !ok {
+		return nil, uuid.Nil, ErrStrikeUnknownUser
+	}
+
+	var command models.StrikeCommandModel
+	if err := s.db.WithContext(ctx).First(&command, "id = ?", strikeId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, uuid.Nil, ErrStrikeNotFound
+		}
+		return nil, uuid.Nil, err
+	}
+
+	if command.Status != models.StrikeStatusRequested {
+		return nil, uuid.Nil, ErrStrikeNotPending
+	}
+	if time.Now().After(command.ExpiresAt) {
+		s.expire(&command)
+		return nil, uuid.Nil, ErrStrikeExpired
+	}
+	return &command, userID, nil
+}
+
+// dispatch 下发已批准的打击指令并记录打击模块的确认结果
+func (s *strikeService) dispatch(command models.StrikeCommandModel) {
+	var device models.DeviceModel
+	if err := s.db.First(&device, "id = ?", command.DeviceID).Error; err != nil {
+		s.fail(&command, fmt.Sprintf("加载设备失败: %v", err))
+		return
+	}
+
+	// 配置了驱动的设备通过驱动下发，否则通过网络直接发送到打击模块
+	address := strikeAddress(&device)
+	send := func(ctx context.Context, payload strike.Command) (*strike.Ack, error) {
+		return s.driver.Send(ctx, address, payload)
+	}
+	if device.Driver != "" {
+		address = "驱动 " + device.Driver
+		send = func(ctx context.Context, payload strike.Command) (*strike.Ack, error) {
+			return s.deviceDriverService.SendCommand(ctx, device.ID, payload)
+		}
+	} else if address == "" {
+		s.fail(&command, ErrStrikeNotConfigured.Error())
+		return
+	}
+
+	payload := strike.Command{
+		ID:       command.ID,
+		Action:   string(command.Action),
+		Duration: command.Duration,
+	}
+	if command.TrackID != nil {
+		var track models.TrackModel
+		if err := s.db.First(&track, "id = ?", *command.TrackID).Error; err == nil {
+			payload.TargetID = track.TargetID
+			payload.DroneSerial = track.DroneSerial
+			payload.Latitude = track.LastLatitude
+			payload.Longitude = track.LastLongitude
+			payload.Altitude = track.LastAltitude
+		}
+	}
+
+	// 交给驱动前记录为已下发，驱动返回后记录确认结果
+	if err := s.transition(s.db, &command, models.StrikeStatusSent, map[string]any{
+		"sent_at": time.Now().UTC(),
+	}, nil, "下发到"+address); err != nil {
+		log.Errorf("记录打击指令 %s 下发失败: %v", command.ID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.sendTimeout)
+	defer cancel()
+
+	ack, err := send(ctx, payload)
+	switch {
+	case err != nil:
+		s.fail(&command, err.Error())
+	case !ack.Accepted:
+		s.fail(&command, "打击模块拒绝: "+ack.Message)
+	default:
+		if err := s.transition(s.db, &command, models.StrikeStatusAcknowledged, map[string]any{
+			"completed_at": time.Now().UTC(),
+			"result":       truncate(ack.Message, 255),
+		}, nil, ack.Message); err != nil {
+			log.Errorf("记录打击指令 %s 确认失败: %v", command.ID, err)
+			return
+		}
+		log.Infof("打击指令 %s 已确认: %s", command.ID, ack.Message)
+	}
+}
+
+// fail 将打击指令标记为失败
+func (s *strikeService) fail(command *models.StrikeCommandModel, reason string) {
+	reason = truncate(reason, 255)
+	if err := s.transition(s.db, command, models.StrikeStatusFailed, map[string]any{
+		"completed_at": time.Now().UTC(),
+		"result":       reason,
+	}, nil, reason); err != nil {
+		log.Errorf("记录打击指令 %s 失败状态失败: %v", command.ID, err)
+		return
+	}
+	log.Warnf("打击指令 %s 失败: %s", command.ID, reason)
+}
+
+// expire 将待审批的打击指令标记为超时
+func (s *strikeService) expire(command *models.StrikeCommandModel) {
+	if err := s.transition(s.db, command, models.StrikeStatusExpired, map[string]any{
+		"completed_at": time.Now().UTC(),
+	}, nil, "审批超时"); err != nil {
+		if !errors.Is(err, ErrStrikeNotPending) {
+			log.Errorf("标记打击指令 %s 超时失败: %v", command.ID, err)
+		}
+		return
+	}
+	log.Warnf("打击指令 %s 审批超时", command.ID)
+}
+
+// expirePending 将超过审批时限的打击指令标记为超时
+func (s *strikeService) expirePending() {
+	var commands []models.StrikeCommandModel
+	if err := s.db.Where("status = ? AND expires_at < ?", models.StrikeStatusRequested, time.Now().UTC()).Find(&commands).Error; err != nil {
+		log.Errorf("查询审批超时的打击指令失败: %v", err)
+		return
+	}
+	for i := range commands {
+		s.expire(&commands[i])
+	}
+}
+
+// failInterrupted 将服务停止时未完成下发的打击指令标记为失败
+func (s *strikeService) failInterrupted() {
+	var commands []models.StrikeCommandModel
+	if err := s.db.Where("status IN ?", []models.StrikeStatus{models.StrikeStatusApproved, models.StrikeStatusSent}).Find(&commands).Error; err != nil {
+		log.Errorf("查询未完成的打击指令失败: %v", err)
+		return
+	}
+	for i := range commands {
+		s.fail(&commands[i], "服务重启，指令下发结果未知")
+	}
+}
+
+// transition 变更打击指令状态并记录，状态已被其他操作修改时返回 ErrStrikeNotPending
+func (s *strikeService) transition(db *gorm.DB, command *models.StrikeCommandModel, to models.StrikeStatus, updates map[string]any, actorID *uuid.UUID, message string) error {
+	from := command.Status
+	updates["status"] = to
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 以当前状态为条件更新，避免并发审批
+		result := tx.Model(&models.StrikeCommandModel{}).Where("id = ? AND status = ?", command.ID, from).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStrikeNotPending
+		}
+
+		return tx.Create(&models.StrikeCommandLogModel{
+			TenantID:   command.TenantID,
+			CommandID:  command.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			ActorID:    actorID,
+			Message:    truncate(message, 255),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := db.First(command, "id = ?", command.ID).Error; err != nil {
+		return err
+	}
+	s.publish(command)
+	return nil
+}
+
+// publish 发布打击指令状态变化事件
+func (s *strikeService) publish(command *models.StrikeCommandModel) {
+	s.bus.Publish(event.Event{
+		Topic:    event.TopicStrike,
+		TenantID: command.TenantID,
+		Payload:  *command,
+	})
+}
+
+// strikeAddress 获取设备打击模块地址，未配置时返回空字符串
+func strikeAddress(device *models.DeviceModel) string {
+	return hostPort(device.StrikeIP, device.StrikePort, 0)
+}
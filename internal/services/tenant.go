@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"xacms/internal/models"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTenantNotFound = errors.New("租户不存在")
+	ErrTenantDisabled = errors.New("租户已被禁用")
+	ErrTenantInUse    = errors.New("租户下仍有用户、角色或设备，无法删除")
+)
+
+// TenantService 租户服务接口
+type TenantService interface {
+	GetTenants(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.TenantModel], error)
+	CreateTenant(ctx context.Context, req dto.CreateTenantRequest) (*models.TenantModel, error)
+	UpdateTenant(ctx context.Context, tenantId uuid.UUID, req dto.UpdateTenantRequest) (*models.TenantModel, error)
+	DeleteTenant(ctx context.Context, tenantId uuid.UUID) error
+	ResolveTenant(identifier string) (uuid.UUID, bool, error)
+}
+
+// tenantService 租户服务实现
+type tenantService struct {
+	db            *gorm.DB
+	commonService CommonService
+}
+
+// NewTenantService 创建租户服务实例
+func NewTenantService(db *gorm.DB, commonService CommonService) TenantService {
+	return &tenantService{
+		db:            db,
+		commonService: commonService,
+	}
+}
+
+// tenantQueryOptions 租户列表查询选项
+var tenantQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"name":       {Column: "name", Type: FieldString},
+		"code":       {Column: "code", Type: FieldString},
+		"status":     {Column: "status", Type: FieldNumber},
+		"created_at": {Column: "created_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"name":       "name",
+		"code":       "code",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	KeywordFields: []string{"name", "code", "description"},
+	DefaultSort:   "created_at DESC",
+}
+
+// GetTenants 获取租户列表
+func (s *tenantService) GetTenants(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.TenantModel], error) {
+	return QueryList[models.TenantModel](s.db.WithContext(ctx).Model(&models.TenantModel{}), &req, tenantQueryOptions)
+}
+
+// CreateTenant 创建租户
+func (s *tenantService) CreateTenant(ctx context.Context, req dto.CreateTenantRequest) (*models.TenantModel, error) {
+	status := models.StatusEnabled
+	if req.Status != nil {
+		status = *req.Status
+	}
+
+	tenant := &models.TenantModel{
+		Name:        req.Name,
+		Code:        req.Code,
+		Description: req.Description,
+		Status:      &status,
+	}
+	if err := s.db.WithContext(ctx).Create(tenant).Error; err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// UpdateTenant 更新租户
+func (s *tenantService) UpdateTenant(ctx context.Context, tenantId uuid.UUID, req dto.UpdateTenantRequest) (*models.TenantModel, error) {
+	var tenant models.TenantModel
+	if err := s.commonService.GetItemByID(ctx, tenantId, &tenant); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	if req.Name != nil {
+		tenant.Name = *req.Name
+	}
+
+	if req.Code != nil {
+		tenant.Code = *req.Code
+	}
+
+	if req.Description != nil {
+		tenant.Description = *req.Description
+	}
+
+	if req.Status != nil {
+		tenant.Status = req.Status
+	}
+
+	if err := s.db.WithContext(ctx).Save(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// DeleteTenant 删除租户，租户下仍有数据时拒绝删除
+func (s *tenantService) DeleteTenant(ctx context.Context, tenantId uuid.UUID) error {
+	var tenant models.TenantModel
+	if err := s.commonService.GetItemByID(ctx, tenantId, &tenant); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrTenantNotFound
+		}
+		return err
+	}
+
+	for _, model := range []any{&models.UserModel{}, &models.RoleModel{}, &models.DeviceModel{}} {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(model).Where("tenant_id = ?", tenantId).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrTenantInUse
+		}
+	}
+
+	return s.db.WithContext(ctx).Delete(&tenant).Error
+}
+
+// ResolveTenant 按租户ID或编码查找启用的租户，租户不存在或已禁用时返回 false
+func (s *tenantService) ResolveTenant(identifier string) (uuid.UUID, bool, error) {
+	query := s.db.Model(&models.TenantModel{})
+	if id, err := uuid.Parse(identifier); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("code = ?", identifier)
+	}
+
+	var tenant models.TenantModel
+	if err := query.Select("id", "status").First(&tenant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return uuid.Nil, false, nil
+		}
+		return uuid.Nil, false, err
+	}
+
+	if tenant.Status == nil || !tenant.Status.IsEnabled() {
+		return uuid.Nil, false, nil
+	}
+	return tenant.ID, true, nil
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/event"
+	"xacms/internal/routes/dto"
+	"xacms/internal/utils"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrTrackNotFound 航迹不存在
+var ErrTrackNotFound = errors.New("航迹不存在")
+
+// TrackService 航迹服务接口
+type TrackService interface {
+	Start()
+	Stop()
+	GetTracks(ctx context.Context, req dto.TrackQueryRequest) (*dto.PaginatedResponse[models.TrackModel], error)
+	GetTrack(ctx context.Context, trackId uuid.UUID) (*dto.TrackDetailResponse, error)
+}
+
+// trackService 航迹服务实现
+type trackService struct {
+	db            *gorm.DB
+	commonService CommonService
+	bus           *event.Bus
+
+	gap           time.Duration
+	timeout       time.Duration
+	closeInterval time.Duration
+	queueSize     int
+
+	queue       chan models.DetectionEventModel
+	unsubscribe func()
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewTrackService 创建航迹服务实例
+//
+// 配置项：TRACK_GAP 同一目标相邻侦测点超过该间隔时开始新航迹，
+// TRACK_TIMEOUT 航迹超过该时长无新侦测点时结束，TRACK_CLOSE_INTERVAL 检查超时航迹的间隔，
+// TRACK_QUEUE_SIZE 待处理侦测事件队列长度。
+func NewTrackService(db *gorm.DB, commonService CommonService, bus *event.Bus) TrackService {
+	return &trackService{
+		db:            db,
+		commonService: commonService,
+		bus:           bus,
+		gap:           utils.EnvDuration("TRACK_GAP", time.Minute),
+		timeout:       utils.EnvDuration("TRACK_TIMEOUT", 2*time.Minute),
+		closeInterval: utils.EnvDuration("TRACK_CLOSE_INTERVAL", 30*time.Second),
+		queueSize:     max(utils.EnvInt("TRACK_QUEUE_SIZE", 1024), 1),
+	}
+}
+
+// trackQueryOptions 航迹列表查询选项
+var trackQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"status":       {Column: "status", Type: FieldString},
+		"target_key":   {Column: "target_key", Type: FieldString},
+		"target_id":    {Column: "target_id", Type: FieldString},
+		"drone_model":  {Column: "drone_model", Type: FieldString},
+		"drone_serial": {Column: "drone_serial", Type: FieldString},
+		"point_count":  {Column: "point_count", Type: FieldNumber},
+	},
+	SortFields: map[string]string{
+		"start_time":  "start_time",
+		"end_time":    "end_time",
+		"point_count": "point_count",
+		"created_at":  "created_at",
+	},
+	KeywordFields: []string{"target_key", "target_id", "drone_model", "drone_serial"},
+	DefaultSort:   "end_time DESC",
+}
+
+// Start 订阅侦测事件并启动航迹聚合
+func (s *trackService) Start() {
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.queue = make(chan models.DetectionEventModel, s.queueSize)
+
+	// 事件在上报请求中同步发布，放入队列后由后台统一处理，队列满时阻塞上报形成背压
+	s.unsubscribe = s.bus.Subscribe(event.TopicDetection, func(e event.Event) {
+		detection, ok := e.Payload.(models.DetectionEventModel)
+		if !ok || models.TrackKey(&detection) == "" {
+			return
+		}
+		select {
+		case s.queue <- detection:
+		case <-ctx.Done():
+		}
+	})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.closeInterval)
+		defer ticker.Stop()
+
+		s.closeStale()
+		for {
+			select {
+			case <-ctx.Done():
+				s.drain()
+				return
+			case detection := <-s.queue:
+				s.process(detection)
+			case <-ticker.C:
+				s.closeStale()
+			}
+		}
+	}()
+
+	log.Infof("航迹聚合已启动，航迹间隔 %s，超时 %s", s.gap, s.timeout)
+}
+
+// Stop 取消订阅并等待队列中的侦测事件处理完毕
+func (s *trackService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.unsubscribe()
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+}
+
+// GetTracks 获取航迹列表，支持按设备和时间范围过滤
+func (s *trackService) GetTracks(ctx context.Context, req dto.TrackQueryRequest) (*dto.PaginatedResponse[models.TrackModel], error) {
+	query := s.db.WithContext(ctx).Model(&models.TrackModel{})
+
+	if req.DeviceID != nil {
+		query = query.Where("device_id = ?", *req.DeviceID)
+	}
+
+	if req.StartTime != "" {
+		start, err := time.Parse(time.RFC3339, req.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: start_time 格式无效", ErrInvalidQuery)
+		}
+		query = query.Where("end_time >= ?", start.UTC())
+	}
+
+	if req.EndTime != "" {
+		end, err := time.Parse(time.RFC3339, req.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: end_time 格式无效", ErrInvalidQuery)
+		}
+		query = query.Where("start_time <= ?", end.UTC())
+	}
+
+	return QueryList[models.TrackModel](query, &req.ListQueryRequest, trackQueryOptions)
+}
+
+// GetTrack 获取航迹详情及完整路径
+func (s *trackService) GetTrack(ctx context.Context, trackId uuid.UUID) (*dto.TrackDetailResponse, error) {
+	var track models.TrackModel
+	if err := s.commonService.GetItemByID(ctx, trackId, &track); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrTrackNotFound
+		}
+		return nil, err
+	}
+
+	var detections []models.DetectionEventModel
+	if err := s.db.WithContext(ctx).
+		Select("latitude", "longitude", "altitude").
+		Where("track_id = ? AND latitude IS NOT NULL AND longitude IS NOT NULL", trackId).
+		Order("timestamp ASC").
+		Find(&detections).Error; err != nil {
+		return nil, err
+	}
+
+	// 所有侦测点都有高度时才输出三维坐标，避免同一路径混用二维和三维坐标
+	withAltitude := len(detections) > 0
+	for _, detection := range detections {
+		if detection.Altitude == nil {
+			withAltitude = false
+			break
+		}
+	}
+
+	coordinates := make([][]float64, 0, len(detections))
+	for _, detection := range detections {
+		position := []float64{*detection.Longitude, *detection.Latitude}
+		if withAltitude {
+			position = append(position, *detection.Altitude)
+		}
+		coordinates = append(coordinates, position)
+	}
+
+	return &dto.TrackDetailResponse{
+		TrackModel: track,
+		Path: dto.GeoJSONLineString{
+			Type:        "LineString",
+			Coordinates: coordinates,
+		},
+	}, nil
+}
+
+// process 将侦测事件归入航迹，与上一个侦测点间隔过长时开始新航迹
+func (s *trackService) process(detection models.DetectionEventModel) {
+	key := models.TrackKey(&detection)
+
+	var track models.TrackModel
+	err := s.db.Where("device_id = ? AND target_key = ? AND status = ?", detection.DeviceID, key, models.TrackStatusActive).
+		Order("end_time DESC").
+		First(&track).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("查询航迹失败: %v", err)
+		return
+	}
+
+	found := err == nil
+	if found && detection.Timestamp.Sub(track.EndTime) > s.gap {
+		s.close(&track)
+		found = false
+	}
+	// 远早于当前航迹的补传侦测点单独成迹，由超时检查结束
+	if found && track.StartTime.Sub(detection.Timestamp) > s.gap {
+		found = false
+	}
+	if !found {
+		track = models.TrackModel{
+			TenantID:  detection.TenantID,
+			DeviceID:  detection.DeviceID,
+			TargetKey: key,
+			Status:    models.TrackStatusActive,
+			StartTime: detection.Timestamp,
+			EndTime:   detection.Timestamp,
+		}
+	}
+
+	track.PointCount++
+	if detection.Timestamp.Before(track.StartTime) {
+		track.StartTime = detection.Timestamp
+	}
+	// 乱序到达的较早侦测点只计入路径，不覆盖最后位置
+	if !detection.Timestamp.Before(track.EndTime) {
+		track.EndTime = detection.Timestamp
+		applyLatest(&track, &detection)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&track).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.DetectionEventModel{}).Where("id = ?", detection.ID).Update("track_id", track.ID).Error
+	})
+	if err != nil {
+		log.Errorf("保存航迹失败: %v", err)
+		return
+	}
+
+	s.publish(track)
+}
+
+// applyLatest 使用最新侦测点更新航迹的目标信息和最后位置
+func applyLatest(track *models.TrackModel, detection *models.DetectionEventModel) {
+	if detection.TargetID != "" {
+		track.TargetID = detection.TargetID
+	}
+	if detection.DroneModel != "" {
+		track.DroneModel = detection.DroneModel
+	}
+	if detection.DroneSerial != "" {
+		track.DroneSerial = detection.DroneSerial
+	}
+	if detection.HasPosition() {
+		track.LastLatitude = detection.Latitude
+		track.LastLongitude = detection.Longitude
+		track.LastAltitude = detection.Altitude
+	}
+	if detection.Heading != nil {
+		track.LastHeading = detection.Heading
+	}
+	if detection.Speed != nil {
+		track.LastSpeed = detection.Speed
+	}
+}
+
+// closeStale 结束超时未更新的航迹
+func (s *trackService) closeStale() {
+	var tracks []models.TrackModel
+	if err := s.db.Where("status = ? AND end_time < ?", models.TrackStatusActive, time.Now().Add(-s.timeout).UTC()).Find(&tracks).Error; err != nil {
+		log.Errorf("查询超时航迹失败: %v", err)
+		return
+	}
+
+	for i := range tracks {
+		s.close(&tracks[i])
+	}
+}
+
+// close 结束航迹
+func (s *trackService) close(track *models.TrackModel) {
+	if err := s.db.Model(track).Update("status", models.TrackStatusClosed).Error; err != nil {
+		log.Errorf("结束航迹 %s 失败: %v", track.ID, err)
+		return
+	}
+	track.Status = models.TrackStatusClosed
+	s.publish(*track)
+}
+
+// publish 发布航迹变化事件
+func (s *trackService) publish(track models.TrackModel) {
+	s.bus.Publish(event.Event{
+		Topic:    event.TopicTrack,
+		TenantID: track.TenantID,
+		Time:     track.EndTime,
+		Payload:  track,
+	})
+}
+
+// drain 处理队列中剩余的侦测事件
+func (s *trackService) drain() {
+	for {
+		select {
+		case detection := <-s.queue:
+			s.process(detection)
+		default:
+			return
+		}
+	}
+}
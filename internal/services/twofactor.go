@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/password"
+	"xacms/internal/pkg/totp"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPasswordMismatch        = errors.New("密码错误")
+	ErrInvalidTwoFactorCode    = errors.New("验证码错误")
+	ErrTwoFactorNotEnabled     = errors.New("未启用双因素认证")
+	ErrTwoFactorAlreadyEnabled = errors.New("已启用双因素认证")
+	ErrTwoFactorNotPending     = errors.New("请先生成双因素认证密钥")
+	ErrTwoFactorRequired       = errors.New("所属角色要求启用双因素认证，不能停用")
+)
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// totpSkew 允许的时钟偏差（时间步）
+const totpSkew = 1
+
+// recoveryEncoding 恢复码编码，小写便于输入
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// TwoFactorService 双因素认证服务接口
+type TwoFactorService interface {
+	GetStatus(userId uuid.UUID) (*dto.TwoFactorStatusResponse, error)
+	Setup(userId uuid.UUID, req dto.TwoFactorSetupRequest) (*dto.TwoFactorSetupResponse, error)
+	Enable(userId uuid.UUID, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(userId uuid.UUID, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+	Disable(userId uuid.UUID, req dto.DisableTwoFactorRequest) error
+	Reset(ctx context.Context, userId uuid.UUID) error
+	Required(user *models.UserModel) (bool, error)
+	Verify(user *models.UserModel, code string) error
+}
+
+// twoFactorService 双因素认证服务实现
+//
+// 当前用户的操作由令牌确定用户，平台用户选择租户后仍需能找到自身，不按租户过滤。
+type twoFactorService struct {
+	db             *gorm.DB
+	commonService  CommonService
+	hasher         password.Hasher
+	sessionService SessionService
+	issuer         string
+}
+
+// NewTwoFactorService 创建双因素认证服务实例
+//
+// 配置项：TOTP_ISSUER 身份验证器应用中显示的发行方名称。
+func NewTwoFactorService(db *gorm.DB, commonService CommonService, hasher password.Hasher, sessionService SessionService) TwoFactorService {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "XACMS"
+	}
+	return &twoFactorService{
+		db:             db,
+		commonService:  commonService,
+		hasher:         hasher,
+		sessionService: sessionService,
+		issuer:         issuer,
+	}
+}
+
+// GetStatus 获取用户的双因素认证状态
+func (s *twoFactorService) GetStatus(userId uuid.UUID) (*dto.TwoFactorStatusResponse, error) {
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.Required(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining int64
+	if user.TwoFactorEnabled {
+		if err := s.db.Model(&models.RecoveryCodeModel{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Count(&remaining).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.TwoFactorStatusResponse{
+		Enabled:                user.TwoFactorEnabled,
+		Required:               required,
+		Pending:                user.TOTPPendingSecret != "",
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// Setup 校验密码后生成待确认的密钥，确认前不影响登录
+func (s *twoFactorService) Setup(userId uuid.UUID, req dto.TwoFactorSetupRequest) (*dto.TwoFactorSetupResponse, error) {
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := s.checkPassword(user, req.Password); err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(user).Update("totp_pending_secret", secret).Error; err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 使用待确认密钥生成的验证码启用双因素认证，返回恢复码
+//
+// 启用后撤销用户已签发的全部令牌，需要重新登录并完成两步验证。
+func (s *twoFactorService) Enable(userId uuid.UUID, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPPendingSecret == "" {
+		return nil, ErrTwoFactorNotPending
+	}
+
+	if err := s.verifyTOTP(user, user.TOTPPendingSecret, req.Code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]any{
+			"two_factor_enabled":  true,
+			"totp_secret":         user.TOTPPendingSecret,
+			"totp_pending_secret": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionService.RevokeUserSessions(user.ID); err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧恢复码随即失效
+func (s *twoFactorService) RegenerateRecoveryCodes(userId uuid.UUID, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if err := s.verifyTOTP(user, user.TOTPSecret, req.Code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable 校验密码和验证码后停用双因素认证，角色要求启用时不能停用
+func (s *twoFactorService) Disable(userId uuid.UUID, req dto.DisableTwoFactorRequest) error {
+	user, err := s.getUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	required, err := s.Required(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	if err := s.checkPassword(user, req.Password); err != nil {
+		return err
+	}
+
+	if err := s.Verify(user, req.Code); err != nil {
+		return err
+	}
+
+	return s.clear(user.ID)
+}
+
+// Reset 管理员重置用户的双因素认证，用于用户丢失身份验证器且恢复码用尽的情况
+//
+// 重置后撤销用户已签发的全部令牌；角色要求启用时，用户下次登录后需要重新绑定。
+func (s *twoFactorService) Reset(ctx context.Context, userId uuid.UUID) error {
+	var user models.UserModel
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if err := s.clear(user.ID); err != nil {
+		return err
+	}
+
+	return s.sessionService.RevokeUserSessions(user.ID)
+}
+
+// Required 判断用户所属角色是否要求启用双因素认证
+func (s *twoFactorService) Required(user *models.UserModel) (bool, error) {
+	if user.RoleID == nil {
+		return false, nil
+	}
+
+	var role models.RoleModel
+	if err := s.db.Select("id", "require_two_factor").Take(&role, "id = ?", *user.RoleID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return role.RequireTwoFactor, nil
+}
+
+// Verify 校验用户的验证码或恢复码，恢复码使用后失效
+func (s *twoFactorService) Verify(user *models.UserModel, code string) error {
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(user, user.TOTPSecret, code)
+	}
+	return s.useRecoveryCode(user, code)
+}
+
+// verifyTOTP 校验验证码，同一时间步的验证码只能使用一次
+func (s *twoFactorService) verifyTOTP(user *models.UserModel, secret, code string) error {
+	step, ok := totp.Validate(secret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !ok || step <= user.TOTPLastStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// 以时间步作为条件更新，并发提交同一验证码时只有一个成功
+	result := s.db.Model(&models.UserModel{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TOTPLastStep = step
+	return nil
+}
+
+// useRecoveryCode 使用恢复码
+func (s *twoFactorService) useRecoveryCode(user *models.UserModel, code string) error {
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidTwoFactorCode
+	}
+
+	result := s.db.Model(&models.RecoveryCodeModel{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(normalized)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 删除旧恢复码并生成新恢复码，只保存哈希
+func (s *twoFactorService) replaceRecoveryCodes(tx *gorm.DB, userId uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCodeModel{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCodeModel, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := recoveryEncoding.EncodeToString(raw)[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		records = append(records, models.RecoveryCodeModel{UserID: userId, CodeHash: hashRecoveryCode(code)})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// clear 清除用户的双因素认证密钥和恢复码
+func (s *twoFactorService) clear(userId uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserModel{}).Where("id = ?", userId).Updates(map[string]any{
+			"two_factor_enabled":  false,
+			"totp_secret":         "",
+			"totp_pending_secret": "",
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userId).Delete(&models.RecoveryCodeModel{}).Error
+	})
+}
+
+// checkPassword 校验用户当前密码
+func (s *twoFactorService) checkPassword(user *models.UserModel, plain string) error {
+	ok, err := s.hasher.Verify(user.Password, plain)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// getUser 按ID获取用户，不按租户过滤
+func (s *twoFactorService) getUser(userId uuid.UUID) (*models.UserModel, error) {
+	var user models.UserModel
+	if err := s.db.First(&user, "id = ?", userId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// normalizeRecoveryCode 统一恢复码格式，忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// hashRecoveryCode 计算恢复码哈希，恢复码为高熵随机值，使用 SHA-256 即可
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
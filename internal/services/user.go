@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"xacms/internal/models"
+	"xacms/internal/pkg/password"
 	"xacms/internal/routes/dto"
 
 	"github.com/google/uuid"
@@ -12,69 +14,129 @@ import (
 
 // UserService 用户服务接口
 type UserService interface {
-	GetUsers(req dto.UserQueryRequest) (*dto.PaginatedResponse[models.UserModel], error)
-	CreateUser(req dto.CreateUserRequest) (*models.UserModel, error)
-	UpdateUser(userId uuid.UUID, req dto.UpdateUserRequest) (*models.UserModel, error)
-	AssignRole(userId uuid.UUID, req dto.AssignRoleRequest) (*models.UserModel, error)
+	GetUsers(ctx context.Context, req dto.UserQueryRequest) (*dto.PaginatedResponse[models.UserModel], error)
+	CreateUser(ctx context.Context, req dto.CreateUserRequest) (*models.UserModel, error)
+	UpdateUser(ctx context.Context, userId uuid.UUID, req dto.UpdateUserRequest) (*models.UserModel, error)
+	AssignRole(ctx context.Context, userId uuid.UUID, req dto.AssignRoleRequest) (*models.UserModel, error)
+	AssignDepartment(ctx context.Context, userId uuid.UUID, req dto.AssignDepartmentRequest) (*models.UserModel, error)
+	ChangePassword(ctx context.Context, userId uuid.UUID, req dto.ChangePasswordRequest) error
+	ResetPassword(ctx context.Context, userId uuid.UUID, req dto.ResetPasswordRequest) error
 }
 
+var (
+	ErrUserNotFound        = errors.New("用户不存在")
+	ErrOldPasswordMismatch = errors.New("原密码错误")
+	ErrPasswordUnchanged   = errors.New("新密码不能与原密码相同")
+)
+
 // userService 用户服务实现
 type userService struct {
-	db            *gorm.DB
-	commonService CommonService
+	db                *gorm.DB
+	commonService     CommonService
+	departmentService DepartmentService
+	dataScopeService  DataScopeService
+	hasher            password.Hasher
+	sessionService    SessionService
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(db *gorm.DB, commonService CommonService) UserService {
+func NewUserService(db *gorm.DB, commonService CommonService, departmentService DepartmentService, dataScopeService DataScopeService, hasher password.Hasher, sessionService SessionService) UserService {
 	return &userService{
-		db:            db,
-		commonService: commonService,
+		db:                db,
+		commonService:     commonService,
+		departmentService: departmentService,
+		dataScopeService:  dataScopeService,
+		hasher:            hasher,
+		sessionService:    sessionService,
 	}
 }
 
+// userQueryOptions 用户列表查询选项
+var userQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"username":   {Column: "username", Type: FieldString},
+		"nickname":   {Column: "nickname", Type: FieldString},
+		"email":      {Column: "email", Type: FieldString},
+		"phone":      {Column: "phone", Type: FieldString},
+		"created_at": {Column: "created_at", Type: FieldString},
+		"updated_at": {Column: "updated_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"username":   "username",
+		"nickname":   "nickname",
+		"email":      "email",
+		"status":     "status",
+	},
+	KeywordFields: []string{"nickname", "username", "email", "phone"},
+	DefaultSort:   "created_at DESC",
+	Preloads:      []string{clause.Associations},
+}
+
 // GetUsers 获取用户列表
-func (s *userService) GetUsers(req dto.UserQueryRequest) (*dto.PaginatedResponse[models.UserModel], error) {
-	var users []models.UserModel
-	query := s.db.Model(&models.UserModel{}).Preload(clause.Associations)
+func (s *userService) GetUsers(ctx context.Context, req dto.UserQueryRequest) (*dto.PaginatedResponse[models.UserModel], error) {
+	query := s.db.WithContext(ctx).Model(&models.UserModel{})
+
+	// 过滤条件
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+
+	if req.RoleID != nil {
+		query = query.Where("role_id = ?", *req.RoleID)
+	}
 
-	// 分页参数
-	page := req.Page
-	pageSize := req.PageSize
+	if req.TenantID != nil {
+		query = query.Where("tenant_id = ?", *req.TenantID)
+	}
 
-	offset := (page - 1) * pageSize
+	// 部门过滤包含下级部门的用户
+	if req.DepartmentID != nil {
+		departmentIDs, err := s.departmentService.GetDescendantIDs(ctx, *req.DepartmentID)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("department_id IN ?", departmentIDs)
+	}
 
-	if err := query.Offset(offset).Limit(pageSize).Find(&users).Order("created_at DESC").Error; err != nil {
+	// 数据范围
+	query, err := s.dataScopeService.Apply(ctx, query, DataScopeColumns{Owner: "id", Department: "department_id"})
+	if err != nil {
 		return nil, err
 	}
-	return &dto.PaginatedResponse[models.UserModel]{
-		Total: int64(len(users)),
-		Items: users,
-	}, nil
+
+	return QueryList[models.UserModel](query, &req.ListQueryRequest, userQueryOptions)
 }
 
 // CreateUser 创建用户
-func (s *userService) CreateUser(req dto.CreateUserRequest) (*models.UserModel, error) {
+func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*models.UserModel, error) {
+	hashed, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
 	userData := &models.UserModel{
 		ID:       uuid.New(),
 		Nickname: req.Nickname,
 		Username: req.Username,
-		Password: req.Password, // TODO：实际应用中应该加密密码
+		Password: hashed,
 		Email:    req.Email,
 		Phone:    req.Phone,
 		Avatar:   req.Avatar,
 		Status:   req.Status,
 	}
 
-	if err := s.db.Create(userData).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(userData).Error; err != nil {
 		return nil, err
 	}
 	return userData, nil
 }
 
-// UpdateUser 修改用户
-func (s *userService) UpdateUser(userId uuid.UUID, req dto.UpdateUserRequest) (*models.UserModel, error) {
+// UpdateUser 修改用户，禁用用户时撤销其全部会话
+func (s *userService) UpdateUser(ctx context.Context, userId uuid.UUID, req dto.UpdateUserRequest) (*models.UserModel, error) {
 	var user models.UserModel
-	if err := s.commonService.GetItemByID(userId, &user); err != nil {
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.New("用户不存在")
 		}
@@ -105,26 +167,121 @@ func (s *userService) UpdateUser(userId uuid.UUID, req dto.UpdateUserRequest) (*
 		user.Status = req.Status
 	}
 
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
 		return nil, err
 	}
+
+	if req.Status != nil && req.Status.IsDisabled() {
+		if err := s.sessionService.RevokeUserSessions(user.ID); err != nil {
+			return nil, err
+		}
+	}
 	return &user, nil
 }
 
 // AssignRole 分配角色
-func (s *userService) AssignRole(userId uuid.UUID, req dto.AssignRoleRequest) (*models.UserModel, error) {
+func (s *userService) AssignRole(ctx context.Context, userId uuid.UUID, req dto.AssignRoleRequest) (*models.UserModel, error) {
 	var user models.UserModel
-	if err := s.commonService.GetItemByID(userId, &user); err != nil {
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.New("用户不存在")
 		}
 		return nil, err
 	}
 
-	user.RoleID = &req.RoleID
+	// 只能分配当前租户内的角色
+	var role models.RoleModel
+	if err := s.commonService.GetItemByID(ctx, req.RoleID, &role); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	user.RoleID = &role.ID
 
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// AssignDepartment 分配部门，部门ID为空时移出部门
+func (s *userService) AssignDepartment(ctx context.Context, userId uuid.UUID, req dto.AssignDepartmentRequest) (*models.UserModel, error) {
+	var user models.UserModel
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if req.DepartmentID != nil {
+		// 只能分配当前租户内的部门
+		var department models.DepartmentModel
+		if err := s.commonService.GetItemByID(ctx, *req.DepartmentID, &department); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrDepartmentNotFound
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Model(&user).Update("department_id", req.DepartmentID).Error; err != nil {
+		return nil, err
+	}
+	user.DepartmentID = req.DepartmentID
+	return &user, nil
+}
+
+// ChangePassword 修改当前用户密码，校验原密码后撤销该用户已签发的全部令牌
+func (s *userService) ChangePassword(ctx context.Context, userId uuid.UUID, req dto.ChangePasswordRequest) error {
+	var user models.UserModel
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	ok, err := s.hasher.Verify(user.Password, req.OldPassword)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOldPasswordMismatch
+	}
+
+	if req.OldPassword == req.NewPassword {
+		return ErrPasswordUnchanged
+	}
+
+	return s.setPassword(ctx, &user, req.NewPassword)
+}
+
+// ResetPassword 管理员重置用户密码，并撤销该用户已签发的全部令牌
+func (s *userService) ResetPassword(ctx context.Context, userId uuid.UUID, req dto.ResetPasswordRequest) error {
+	var user models.UserModel
+	if err := s.commonService.GetItemByID(ctx, userId, &user); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return s.setPassword(ctx, &user, req.NewPassword)
+}
+
+// setPassword 保存新密码哈希并撤销用户令牌
+func (s *userService) setPassword(ctx context.Context, user *models.UserModel, plain string) error {
+	hashed, err := s.hasher.Hash(plain)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Model(user).Update("password", hashed).Error; err != nil {
+		return err
+	}
+
+	return s.sessionService.RevokeUserSessions(user.ID)
+}
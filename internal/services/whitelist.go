@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"xacms/internal/models"
+	"xacms/internal/pkg/audit"
+	"xacms/internal/pkg/tenant"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// whitelistImportMaxRows 单次导入的最大行数
+const whitelistImportMaxRows = 5000
+
+var (
+	ErrWhitelistEntryNotFound = errors.New("白名单不存在")
+	ErrWhitelistZoneNotFound  = errors.New("部分允许区域不存在")
+	ErrWhitelistValidity      = errors.New("失效时间不能早于生效时间")
+	ErrWhitelistSerialExists  = errors.New("序列号已在白名单中")
+	ErrInvalidWhitelistCSV    = errors.New("白名单文件格式无效")
+)
+
+// WhitelistService 白名单服务接口
+type WhitelistService interface {
+	GetEntries(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.WhitelistEntryModel], error)
+	GetEntry(ctx context.Context, entryId uuid.UUID) (*models.WhitelistEntryModel, error)
+	CreateEntry(ctx context.Context, req dto.CreateWhitelistEntryRequest) (*models.WhitelistEntryModel, error)
+	UpdateEntry(ctx context.Context, entryId uuid.UUID, req dto.UpdateWhitelistEntryRequest) (*models.WhitelistEntryModel, error)
+	DeleteEntry(ctx context.Context, entryId uuid.UUID) error
+	ImportEntries(ctx context.Context, r io.Reader) (*dto.WhitelistImportResponse, error)
+	Classify(tenantId *uuid.UUID, detections []models.DetectionEventModel) error
+}
+
+// whitelistService 白名单服务实现
+type whitelistService struct {
+	db            *gorm.DB
+	commonService CommonService
+}
+
+// NewWhitelistService 创建白名单服务实例
+func NewWhitelistService(db *gorm.DB, commonService CommonService) WhitelistService {
+	return &whitelistService{
+		db:            db,
+		commonService: commonService,
+	}
+}
+
+// whitelistQueryOptions 白名单列表查询选项
+var whitelistQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"serial":      {Column: "serial", Type: FieldString},
+		"drone_model": {Column: "drone_model", Type: FieldString},
+		"owner":       {Column: "owner", Type: FieldString},
+		"enabled":     {Column: "enabled", Type: FieldBool},
+		"created_at":  {Column: "created_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"serial":      "serial",
+		"owner":       "owner",
+		"valid_from":  "valid_from",
+		"valid_until": "valid_until",
+		"created_at":  "created_at",
+		"updated_at":  "updated_at",
+	},
+	KeywordFields: []string{"serial", "drone_model", "owner", "remark"},
+	DefaultSort:   "created_at DESC",
+	Preloads:      []string{"Zones"},
+}
+
+// GetEntries 获取白名单列表
+func (s *whitelistService) GetEntries(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.WhitelistEntryModel], error) {
+	return QueryList[models.WhitelistEntryModel](s.db.WithContext(ctx).Model(&models.WhitelistEntryModel{}), &req, whitelistQueryOptions)
+}
+
+// GetEntry 获取白名单详情，包含允许区域
+func (s *whitelistService) GetEntry(ctx context.Context, entryId uuid.UUID) (*models.WhitelistEntryModel, error) {
+	var entry models.WhitelistEntryModel
+	if err := s.db.WithContext(ctx).Preload("Zones").First(&entry, "id = ?", entryId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrWhitelistEntryNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CreateEntry 创建白名单
+func (s *whitelistService) CreateEntry(ctx context.Context, req dto.CreateWhitelistEntryRequest) (*models.WhitelistEntryModel, error) {
+	entry := &models.WhitelistEntryModel{
+		Serial:     strings.TrimSpace(req.Serial),
+		DroneModel: req.DroneModel,
+		Owner:      req.Owner,
+		ValidFrom:  utcTime(req.ValidFrom),
+		ValidUntil: utcTime(req.ValidUntil),
+		Enabled:    true,
+		Remark:     req.Remark,
+	}
+	if req.Enabled != nil {
+		entry.Enabled = *req.Enabled
+	}
+	if err := validateWhitelistEntry(entry); err != nil {
+		return nil, err
+	}
+	if err := s.checkSerial(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	zones, err := s.findZones(ctx, req.ZoneIDs)
+	if err != nil {
+		return nil, err
+	}
+	entry.Zones = zones
+
+	// 只写入区域关联，不更新区域本身
+	if err := s.db.WithContext(ctx).Omit("Zones.*").Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// UpdateEntry 更新白名单
+func (s *whitelistService) UpdateEntry(ctx context.Context, entryId uuid.UUID, req dto.UpdateWhitelistEntryRequest) (*models.WhitelistEntryModel, error) {
+	entry, err := s.GetEntry(ctx, entryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Serial != nil {
+		entry.Serial = strings.TrimSpace(*req.Serial)
+	}
+
+	if req.DroneModel != nil {
+		entry.DroneModel = *req.DroneModel
+	}
+
+	if req.Owner != nil {
+		entry.Owner = *req.Owner
+	}
+
+	if req.ValidFrom != nil {
+		entry.ValidFrom = utcTime(req.ValidFrom)
+		if req.ValidFrom.IsZero() {
+			entry.ValidFrom = nil
+		}
+	}
+
+	if req.ValidUntil != nil {
+		entry.ValidUntil = utcTime(req.ValidUntil)
+		if req.ValidUntil.IsZero() {
+			entry.ValidUntil = nil
+		}
+	}
+
+	if req.Enabled != nil {
+		entry.Enabled = *req.Enabled
+	}
+
+	if req.Remark != nil {
+		entry.Remark = *req.Remark
+	}
+
+	if err := validateWhitelistEntry(entry); err != nil {
+		return nil, err
+	}
+	if err := s.checkSerial(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	var zones []*models.ZoneModel
+	if req.ZoneIDs != nil {
+		if zones, err = s.findZones(ctx, *req.ZoneIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Zones").Save(entry).Error; err != nil {
+			return err
+		}
+		if req.ZoneIDs == nil {
+			return nil
+		}
+		entry.Zones = zones
+		return audit.ReplaceAssociation(tx, entry, "Zones", zones)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// DeleteEntry 删除白名单，已标记的侦测事件保留白名单ID
+func (s *whitelistService) DeleteEntry(ctx context.Context, entryId uuid.UUID) error {
+	var entry models.WhitelistEntryModel
+	if err := s.commonService.GetItemByID(ctx, entryId, &entry); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrWhitelistEntryNotFound
+		}
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entry).Association("Zones").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&entry).Error
+	})
+}
+
+// ImportEntries 从 CSV 导入白名单，按序列号新增或更新
+//
+// 第一行为表头，支持的列：serial（必填）、drone_model、owner、valid_from、valid_until（RFC3339，可为空）、
+// zones（允许区域名称，多个以分号分隔）、enabled（默认 true）、remark，其他列忽略。
+func (s *whitelistService) ImportEntries(ctx context.Context, r io.Reader) (*dto.WhitelistImportResponse, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 缺少表头", ErrInvalidWhitelistCSV)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// 兼容 Excel 导出的 UTF-8 BOM
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["serial"]; !ok {
+		return nil, fmt.Errorf("%w: 缺少 serial 列", ErrInvalidWhitelistCSV)
+	}
+
+	// 允许区域按名称匹配当前租户的区域
+	var zones []*models.ZoneModel
+	if err := ownTenant(ctx, s.db.WithContext(ctx)).Select("id", "tenant_id", "name", "type", "enabled").Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	zonesByName := make(map[string]*models.ZoneModel, len(zones))
+	for _, zone := range zones {
+		zonesByName[zone.Name] = zone
+	}
+
+	// 先读取全部行，超出行数限制时不导入任何数据
+	type row struct {
+		line   int
+		record []string
+		err    error
+	}
+	var rows []row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) == whitelistImportMaxRows {
+			return nil, fmt.Errorf("%w: 单次最多导入 %d 行", ErrInvalidWhitelistCSV, whitelistImportMaxRows)
+		}
+		var line int
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			line = parseErr.StartLine
+		} else if err == nil {
+			line, _ = reader.FieldPos(0)
+		}
+		rows = append(rows, row{line: line, record: record, err: err})
+	}
+
+	result := &dto.WhitelistImportResponse{Errors: []dto.WhitelistImportError{}}
+	for _, item := range rows {
+		err := item.err
+		created := false
+		if err == nil {
+			created, err = s.importRecord(ctx, item.record, columns, zonesByName)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, dto.WhitelistImportError{Line: item.line, Message: err.Error()})
+			continue
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// importRecord 导入一行白名单，返回是否为新增
+func (s *whitelistService) importRecord(ctx context.Context, record []string, columns map[string]int, zonesByName map[string]*models.ZoneModel) (bool, error) {
+	field := func(name string) (string, bool) {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[i]), true
+	}
+
+	serial, _ := field("serial")
+	if serial == "" {
+		return false, errors.New("序列号不能为空")
+	}
+	if len(serial) > 64 {
+		return false, errors.New("序列号长度不能超过 64")
+	}
+
+	var entry models.WhitelistEntryModel
+	created := false
+	if err := ownTenant(ctx, s.db.WithContext(ctx)).Where("serial = ?", serial).First(&entry).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return false, err
+		}
+		entry = models.WhitelistEntryModel{Serial: serial, Enabled: true}
+		created = true
+	}
+
+	// 空单元格覆盖原值，未提供的列保留原值
+	if value, ok := field("drone_model"); ok {
+		entry.DroneModel = truncate(value, 64)
+	}
+	if value, ok := field("owner"); ok {
+		entry.Owner = truncate(value, 64)
+	}
+	if value, ok := field("remark"); ok {
+		entry.Remark = truncate(value, 255)
+	}
+	for name, target := range map[string]**time.Time{"valid_from": &entry.ValidFrom, "valid_until": &entry.ValidUntil} {
+		value, ok := field(name)
+		if !ok {
+			continue
+		}
+		if value == "" {
+			*target = nil
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return false, fmt.Errorf("%s 格式无效，应为 RFC3339", name)
+		}
+		t = t.UTC()
+		*target = &t
+	}
+	if value, ok := field("enabled"); ok && value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, errors.New("enabled 应为 true 或 false")
+		}
+		entry.Enabled = enabled
+	}
+	if err := validateWhitelistEntry(&entry); err != nil {
+		return false, err
+	}
+
+	value, replaceZones := field("zones")
+	zones := []*models.ZoneModel{}
+	if value != "" {
+		for _, name := range strings.Split(value, ";") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			zone, ok := zonesByName[name]
+			if !ok {
+				return false, fmt.Errorf("区域 %s 不存在", name)
+			}
+			zones = append(zones, zone)
+		}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Zones").Save(&entry).Error; err != nil {
+			return err
+		}
+		if !replaceZones {
+			return nil
+		}
+		return audit.ReplaceAssociation(tx, &entry, "Zones", zones)
+	})
+	return created, err
+}
+
+// Classify 按序列号匹配租户已启用的白名单，标记侦测事件的白名单ID以及是否友方目标
+//
+// 友方目标须在有效期内；白名单限定了允许区域时，侦测点还须位于其中任一区域内，无位置的侦测事件不视为友方。
+// 侦测事件的所在区域须已标记。
+func (s *whitelistService) Classify(tenantId *uuid.UUID, detections []models.DetectionEventModel) error {
+	serials := make([]string, 0, len(detections))
+	seen := make(map[string]struct{}, len(detections))
+	for i := range detections {
+		serial := detections[i].DroneSerial
+		if _, ok := seen[serial]; ok || serial == "" {
+			continue
+		}
+		seen[serial] = struct{}{}
+		serials = append(serials, serial)
+	}
+	if len(serials) == 0 {
+		return nil
+	}
+
+	// 侦测事件写入时不一定带有租户上下文，按设备所属租户显式过滤
+	query := s.db.Preload("Zones", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id")
+	}).Where("enabled = ? AND serial IN ?", true, serials)
+	if tenantId != nil {
+		query = query.Where("tenant_id = ?", *tenantId)
+	} else {
+		query = query.Where("tenant_id IS NULL")
+	}
+	var entries []models.WhitelistEntryModel
+	if err := query.Find(&entries).Error; err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	bySerial := make(map[string]*models.WhitelistEntryModel, len(entries))
+	for i := range entries {
+		bySerial[entries[i].Serial] = &entries[i]
+	}
+
+	for i := range detections {
+		detection := &detections[i]
+		entry, ok := bySerial[detection.DroneSerial]
+		if !ok || detection.DroneSerial == "" {
+			continue
+		}
+		detection.WhitelistEntryID = &entry.ID
+		detection.Friendly = entry.ValidAt(detection.Timestamp) && inAllowedZones(entry.Zones, detection)
+	}
+	return nil
+}
+
+// findZones 查找当前租户的区域
+func (s *whitelistService) findZones(ctx context.Context, zoneIds []uuid.UUID) ([]*models.ZoneModel, error) {
+	zones := []*models.ZoneModel{}
+	if len(zoneIds) == 0 {
+		return zones, nil
+	}
+	if err := s.db.WithContext(ctx).Where("id IN ?", zoneIds).Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	if len(zones) != len(uniqueUUIDs(zoneIds)) {
+		return nil, ErrWhitelistZoneNotFound
+	}
+	return zones, nil
+}
+
+// checkSerial 检查序列号在当前租户内是否已存在，平台数据的租户ID为空时唯一索引不生效，需显式检查
+func (s *whitelistService) checkSerial(ctx context.Context, entry *models.WhitelistEntryModel) error {
+	query := s.db.WithContext(ctx).Model(&models.WhitelistEntryModel{}).Where("serial = ? AND id <> ?", entry.Serial, entry.ID)
+	if entry.TenantID != nil {
+		query = query.Where("tenant_id = ?", *entry.TenantID)
+	} else {
+		query = ownTenant(ctx, query)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrWhitelistSerialExists
+	}
+	return nil
+}
+
+// ownTenant 限定为当前用户所属租户的数据：租户用户由租户插件限定，平台用户限定为平台数据
+func ownTenant(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if _, ok := tenant.FromContext(ctx); ok {
+		return db
+	}
+	return db.Where("tenant_id IS NULL")
+}
+
+// validateWhitelistEntry 校验白名单有效期
+func validateWhitelistEntry(entry *models.WhitelistEntryModel) error {
+	if entry.ValidFrom != nil && entry.ValidUntil != nil && entry.ValidUntil.Before(*entry.ValidFrom) {
+		return ErrWhitelistValidity
+	}
+	return nil
+}
+
+// inAllowedZones 判断侦测点是否位于允许区域内，未限定区域时始终成立
+func inAllowedZones(allowed []*models.ZoneModel, detection *models.DetectionEventModel) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, zone := range detection.Zones {
+		for _, item := range allowed {
+			if zone.ID == item.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// utcTime 转换为 UTC 时间
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"xacms/internal/models"
+	"xacms/internal/pkg/audit"
+	"xacms/internal/pkg/geo"
+	"xacms/internal/routes/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrZoneNotFound       = errors.New("区域不存在")
+	ErrInvalidGeometry    = geo.ErrInvalidGeometry
+	ErrZoneDeviceNotFound = errors.New("部分设备不存在")
+)
+
+// ZoneService 区域服务接口
+type ZoneService interface {
+	GetZones(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.ZoneModel], error)
+	GetZone(ctx context.Context, zoneId uuid.UUID) (*models.ZoneModel, error)
+	CreateZone(ctx context.Context, req dto.CreateZoneRequest) (*models.ZoneModel, error)
+	UpdateZone(ctx context.Context, zoneId uuid.UUID, req dto.UpdateZoneRequest) (*models.ZoneModel, error)
+	DeleteZone(ctx context.Context, zoneId uuid.UUID) error
+	AssignDevices(ctx context.Context, zoneId uuid.UUID, req dto.AssignZoneDevicesRequest) (*models.ZoneModel, error)
+	Locate(tenantId *uuid.UUID, longitude, latitude float64) ([]*models.ZoneModel, error)
+}
+
+// zoneShape 已解析几何对象的区域
+type zoneShape struct {
+	zone  *models.ZoneModel // 仅包含ID、名称和类型
+	shape geo.Shape
+}
+
+// zoneService 区域服务实现
+type zoneService struct {
+	db            *gorm.DB
+	commonService CommonService
+
+	// 各租户已启用区域的缓存，区域变化时清空，键为租户ID（无租户时为 uuid.Nil）
+	mu      sync.RWMutex
+	shapes  map[uuid.UUID][]zoneShape
+	version int // 每次清空缓存时递增，避免加载期间区域变化导致缓存旧数据
+}
+
+// NewZoneService 创建区域服务实例
+func NewZoneService(db *gorm.DB, commonService CommonService) ZoneService {
+	return &zoneService{
+		db:            db,
+		commonService: commonService,
+		shapes:        make(map[uuid.UUID][]zoneShape),
+	}
+}
+
+// zoneQueryOptions 区域列表查询选项
+var zoneQueryOptions = QueryOptions{
+	FilterFields: map[string]FilterField{
+		"name":       {Column: "name", Type: FieldString},
+		"type":       {Column: "type", Type: FieldString},
+		"enabled":    {Column: "enabled", Type: FieldBool},
+		"created_at": {Column: "created_at", Type: FieldString},
+	},
+	SortFields: map[string]string{
+		"name":       "name",
+		"type":       "type",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	KeywordFields: []string{"name", "remark"},
+	DefaultSort:   "created_at DESC",
+}
+
+// GetZones 获取区域列表
+func (s *zoneService) GetZones(ctx context.Context, req dto.ListQueryRequest) (*dto.PaginatedResponse[models.ZoneModel], error) {
+	return QueryList[models.ZoneModel](s.db.WithContext(ctx).Model(&models.ZoneModel{}), &req, zoneQueryOptions)
+}
+
+// GetZone 获取区域详情，包含关联设备
+func (s *zoneService) GetZone(ctx context.Context, zoneId uuid.UUID) (*models.ZoneModel, error) {
+	var zone models.ZoneModel
+	if err := s.db.WithContext(ctx).Preload("Devices").First(&zone, "id = ?", zoneId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrZoneNotFound
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// CreateZone 创建区域
+func (s *zoneService) CreateZone(ctx context.Context, req dto.CreateZoneRequest) (*models.ZoneModel, error) {
+	zone := &models.ZoneModel{
+		Name:     req.Name,
+		Type:     req.Type,
+		Enabled:  true,
+		Remark:   req.Remark,
+		Geometry: req.Geometry,
+		Radius:   req.Radius,
+	}
+	if req.Enabled != nil {
+		zone.Enabled = *req.Enabled
+	}
+	if _, err := zoneShapeOf(zone); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(zone).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return zone, nil
+}
+
+// UpdateZone 更新区域
+func (s *zoneService) UpdateZone(ctx context.Context, zoneId uuid.UUID, req dto.UpdateZoneRequest) (*models.ZoneModel, error) {
+	var zone models.ZoneModel
+	if err := s.commonService.GetItemByID(ctx, zoneId, &zone); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrZoneNotFound
+		}
+		return nil, err
+	}
+
+	if req.Name != nil {
+		zone.Name = *req.Name
+	}
+
+	if req.Type != nil {
+		zone.Type = *req.Type
+	}
+
+	if req.Enabled != nil {
+		zone.Enabled = *req.Enabled
+	}
+
+	if req.Remark != nil {
+		zone.Remark = *req.Remark
+	}
+
+	if req.Geometry != nil {
+		zone.Geometry = req.Geometry
+	}
+
+	if req.Radius != nil {
+		zone.Radius = *req.Radius
+	}
+
+	if _, err := zoneShapeOf(&zone); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Save(&zone).Error; err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return &zone, nil
+}
+
+// DeleteZone 删除区域，同时删除设备关联、白名单允许区域和侦测事件的区域标记
+func (s *zoneService) DeleteZone(ctx context.Context, zoneId uuid.UUID) error {
+	var zone models.ZoneModel
+	if err := s.commonService.GetItemByID(ctx, zoneId, &zone); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrZoneNotFound
+		}
+		return err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&zone).Association("Devices").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Table("detection_zones").Where("zone_id = ?", zone.ID).Delete(nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("whitelist_zones").Where("zone_id = ?", zone.ID).Delete(nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&zone).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// AssignDevices 设置区域关联的设备
+func (s *zoneService) AssignDevices(ctx context.Context, zoneId uuid.UUID, req dto.AssignZoneDevicesRequest) (*models.ZoneModel, error) {
+	var zone models.ZoneModel
+	if err := s.commonService.GetItemByID(ctx, zoneId, &zone); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrZoneNotFound
+		}
+		return nil, err
+	}
+
+	// 设备须属于当前租户
+	devices := []*models.DeviceModel{}
+	if len(req.DeviceIDs) > 0 {
+		if err := s.db.WithContext(ctx).Where("id IN ?", req.DeviceIDs).Find(&devices).Error; err != nil {
+			return nil, err
+		}
+		if len(devices) != len(uniqueUUIDs(req.DeviceIDs)) {
+			return nil, ErrZoneDeviceNotFound
+		}
+	}
+
+	if err := audit.ReplaceAssociation(s.db.WithContext(ctx), &zone, "Devices", devices); err != nil {
+		return nil, err
+	}
+
+	zone.Devices = devices
+	return &zone, nil
+}
+
+// Locate 获取坐标所在的已启用区域
+func (s *zoneService) Locate(tenantId *uuid.UUID, longitude, latitude float64) ([]*models.ZoneModel, error) {
+	shapes, err := s.load(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []*models.ZoneModel
+	for _, item := range shapes {
+		if item.shape.Contains(longitude, latitude) {
+			zones = append(zones, item.zone)
+		}
+	}
+	return zones, nil
+}
+
+// load 加载租户已启用区域，优先使用缓存
+func (s *zoneService) load(tenantId *uuid.UUID) ([]zoneShape, error) {
+	key := uuid.Nil
+	if tenantId != nil {
+		key = *tenantId
+	}
+
+	s.mu.RLock()
+	shapes, ok := s.shapes[key]
+	version := s.version
+	s.mu.RUnlock()
+	if ok {
+		return shapes, nil
+	}
+
+	// 侦测事件写入时不一定带有租户上下文，按设备所属租户显式过滤
+	query := s.db.Where("enabled = ?", true)
+	if tenantId != nil {
+		query = query.Where("tenant_id = ?", *tenantId)
+	} else {
+		query = query.Where("tenant_id IS NULL")
+	}
+	var zones []models.ZoneModel
+	if err := query.Find(&zones).Error; err != nil {
+		return nil, err
+	}
+
+	shapes = make([]zoneShape, 0, len(zones))
+	for i := range zones {
+		shape, err := zoneShapeOf(&zones[i])
+		if err != nil {
+			// 数据库中的几何对象已在写入时校验，这里只会因历史数据出错
+			continue
+		}
+		shapes = append(shapes, zoneShape{
+			zone:  &models.ZoneModel{ID: zones[i].ID, TenantID: zones[i].TenantID, Name: zones[i].Name, Type: zones[i].Type, Enabled: true},
+			shape: shape,
+		})
+	}
+
+	s.mu.Lock()
+	if s.version == version {
+		s.shapes[key] = shapes
+	}
+	s.mu.Unlock()
+	return shapes, nil
+}
+
+// invalidate 清空区域缓存
+func (s *zoneService) invalidate() {
+	s.mu.Lock()
+	s.shapes = make(map[uuid.UUID][]zoneShape)
+	s.version++
+	s.mu.Unlock()
+}
+
+// zoneShapeOf 解析区域几何对象
+func zoneShapeOf(zone *models.ZoneModel) (geo.Shape, error) {
+	if zone.Geometry == nil {
+		return nil, fmt.Errorf("%w: 缺少几何对象", ErrInvalidGeometry)
+	}
+	return geo.NewShape(zone.Geometry.Type, zone.Geometry.Coordinates, zone.Radius)
+}
+
+// uniqueUUIDs 去除重复ID
+func uniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// EnvString 读取字符串环境变量，未配置时返回默认值
+func EnvString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// EnvInt 读取整数环境变量，未配置或格式错误时返回默认值
+func EnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		log.Warnf("环境变量 %s 格式错误: %s，使用默认值 %d", key, value, fallback)
+		return fallback
+	}
+	return v
+}
+
+// EnvBool 读取布尔环境变量，未配置或格式错误时返回默认值
+func EnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warnf("环境变量 %s 格式错误: %s，使用默认值 %t", key, value, fallback)
+		return fallback
+	}
+	return v
+}
+
+// EnvDuration 读取时长环境变量，如 30s、5m，未配置或格式错误时返回默认值
+func EnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Warnf("环境变量 %s 格式错误: %s，使用默认值 %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
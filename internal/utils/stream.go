@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// IsStreamRequest 判断请求是否为 WebSocket 或 SSE 长连接请求
+func IsStreamRequest(c *fiber.Ctx) bool {
+	return websocket.IsWebSocketUpgrade(c) || strings.Contains(c.Get(fiber.HeaderAccept), "text/event-stream")
+}
@@ -19,6 +19,7 @@ func NewValidationMiddleware() *ValidationMiddleware {
 	// 注册自定义验证规则
 	validate.RegisterValidation("phone", validatePhone)
 	validate.RegisterValidation("password_strength", validatePasswordStrength)
+	validate.RegisterValidation("stream_path", validateStreamPath)
 
 	return &ValidationMiddleware{
 		validator: validate,
@@ -55,6 +56,8 @@ func formatValidationError(err validator.FieldError) string {
 		return fmt.Sprintf("%s 必须是有效的手机号码", field)
 	case "password_strength":
 		return fmt.Sprintf("%s 必须包含大写字母、小写字母和数字，且至少8个字符", field)
+	case "stream_path":
+		return fmt.Sprintf("%s 只能包含字母、数字、_、-、.和/，且不能以/开头或结尾", field)
 	case "uuid":
 		return fmt.Sprintf("%s 必须是有效的UUID格式", field)
 	case "oneof":
@@ -91,6 +94,26 @@ func validatePasswordStrength(fl validator.FieldLevel) bool {
 	return hasUpper && hasLower && hasDigit
 }
 
+// validateStreamPath 流路径验证，由斜杠分隔的若干段组成
+func validateStreamPath(fl validator.FieldLevel) bool {
+	path := fl.Field().String()
+	if path == "" {
+		return true
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+		for _, r := range segment {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ValidationErrorResponse 验证错误响应
 // func Validationdto.ErrorResponse(errors []string) fiber.Map {
 // 	return fiber.Map{